@@ -0,0 +1,335 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const (
+	// MetadataAckRequired 消息需要服务端ACK时设置的metadata key
+	MetadataAckRequired = "ack-required"
+
+	defaultAckTimeout            = 30 * time.Second
+	defaultAckRetransmitInterval = 5 * time.Second
+	defaultAckMaxRetransmits     = 3
+	ackCheckInterval             = 500 * time.Millisecond
+)
+
+// Delivery 消息投递结果，在收到对应ACK、超时或客户端断开时完成
+type Delivery struct {
+	messageID string
+	done      chan struct{}
+	once      sync.Once
+	ack       *imv1.AckContent
+	err       error
+}
+
+// newDelivery 创建投递结果
+func newDelivery(messageID string) *Delivery {
+	return &Delivery{
+		messageID: messageID,
+		done:      make(chan struct{}),
+	}
+}
+
+// MessageID 返回消息ID
+func (d *Delivery) MessageID() string {
+	return d.messageID
+}
+
+// Done 返回投递完成时关闭的channel
+func (d *Delivery) Done() <-chan struct{} {
+	return d.done
+}
+
+// Err 返回投递错误，未完成或投递成功时返回nil
+func (d *Delivery) Err() error {
+	select {
+	case <-d.done:
+		return d.err
+	default:
+		return nil
+	}
+}
+
+// Wait 等待投递完成，返回服务端的ACK内容
+func (d *Delivery) Wait(ctx context.Context) (*imv1.AckContent, error) {
+	select {
+	case <-d.done:
+		return d.ack, d.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve 完成投递，只有第一次调用生效
+func (d *Delivery) resolve(ack *imv1.AckContent, err error) {
+	d.once.Do(func() {
+		d.ack = ack
+		d.err = err
+		close(d.done)
+	})
+}
+
+// pendingAck 等待ACK的消息
+type pendingAck struct {
+	msg         *imv1.MessageRequest
+	delivery    *Delivery
+	deadline    time.Time
+	lastSent    time.Time
+	retransmits int
+}
+
+// ackTracker 跟踪等待ACK的消息
+type ackTracker struct {
+	pending map[string]*pendingAck
+	mu      sync.Mutex
+}
+
+// newAckTracker 创建ACK跟踪器
+func newAckTracker() *ackTracker {
+	return &ackTracker{
+		pending: make(map[string]*pendingAck),
+	}
+}
+
+// add 登记等待ACK的消息
+func (t *ackTracker) add(msg *imv1.MessageRequest, timeout time.Duration) *Delivery {
+	now := time.Now()
+	p := &pendingAck{
+		msg:      msg,
+		delivery: newDelivery(msg.MessageId),
+		deadline: now.Add(timeout),
+		lastSent: now,
+	}
+
+	t.mu.Lock()
+	t.pending[msg.MessageId] = p
+	t.mu.Unlock()
+
+	return p.delivery
+}
+
+// remove 移除等待ACK的消息
+func (t *ackTracker) remove(messageID string) {
+	t.mu.Lock()
+	delete(t.pending, messageID)
+	t.mu.Unlock()
+}
+
+// resolve 根据ACK完成对应消息的投递，返回是否匹配到等待中的消息
+func (t *ackTracker) resolve(ack *imv1.AckContent) bool {
+	t.mu.Lock()
+	p, exists := t.pending[ack.OriginalMessageId]
+	delete(t.pending, ack.OriginalMessageId)
+	t.mu.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	if ack.Success {
+		p.delivery.resolve(ack, nil)
+	} else {
+		p.delivery.resolve(ack, fmt.Errorf("消息 %s: %w: %s", ack.OriginalMessageId, ErrMessageRejected, ack.ErrorMessage))
+	}
+	return true
+}
+
+// expire 处理超时消息，返回需要重传的消息
+func (t *ackTracker) expire(now time.Time, interval time.Duration, maxRetransmits int) []*imv1.MessageRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var retransmit []*imv1.MessageRequest
+	for id, p := range t.pending {
+		if now.After(p.deadline) {
+			delete(t.pending, id)
+			p.delivery.resolve(nil, fmt.Errorf("消息 %s: %w", id, ErrAckTimeout))
+			continue
+		}
+
+		if now.Sub(p.lastSent) >= interval && p.retransmits < maxRetransmits {
+			p.lastSent = now
+			p.retransmits++
+			retransmit = append(retransmit, p.msg)
+		}
+	}
+
+	return retransmit
+}
+
+// failAll 以指定错误完成所有等待中的消息
+func (t *ackTracker) failAll(err error) {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[string]*pendingAck)
+	t.mu.Unlock()
+
+	for _, p := range pending {
+		p.delivery.resolve(nil, err)
+	}
+}
+
+// SendTextMessageWithAck 发送文本消息并等待服务端ACK
+func (c *Client) SendTextMessageWithAck(roomID, content string, timeout time.Duration) (*Delivery, error) {
+	return c.SendTextMessageWithAckContext(context.Background(), roomID, content, timeout)
+}
+
+// SendTextMessageWithAckContext 发送文本消息并等待服务端ACK
+func (c *Client) SendTextMessageWithAckContext(ctx context.Context, roomID, content string, timeout time.Duration) (*Delivery, error) {
+	msg, err := c.newContentMessage(roomID, imv1.MessageType_MESSAGE_TYPE_TEXT, &imv1.TextContent{Text: content})
+	if err != nil {
+		return nil, err
+	}
+	return c.SendMessageWithAckContext(ctx, msg, timeout)
+}
+
+// SendMessageWithAck 发送消息并返回投递结果
+//
+// 消息会带上 ack-required metadata，在收到 original_message_id 匹配的ACK前，
+// 每隔 AckRetransmitInterval 重传一次（最多 AckMaxRetransmits 次），
+// 超过 timeout（为0时使用 AckTimeout）仍未收到ACK则以超时错误完成。
+// 通过一元RPC发送时（见 Config.SendMode），RPC成功返回即视为投递成功。
+func (c *Client) SendMessageWithAck(msg *imv1.MessageRequest, timeout time.Duration) (*Delivery, error) {
+	return c.SendMessageWithAckContext(context.Background(), msg, timeout)
+}
+
+// SendMessageWithAckContext 发送消息并返回投递结果，ctx 只约束写入发送队列的过程，
+// 等待ACK使用 timeout 或 Delivery.Wait 的 ctx
+func (c *Client) SendMessageWithAckContext(ctx context.Context, msg *imv1.MessageRequest, timeout time.Duration) (*Delivery, error) {
+	if msg.MessageId == "" {
+		msg.MessageId = c.generateMessageID()
+	}
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]string)
+	}
+	msg.Metadata[MetadataAckRequired] = "true"
+
+	if timeout <= 0 {
+		timeout = c.ackTimeout()
+	}
+
+	delivery := c.acks.add(msg, timeout)
+	resp, err := c.send(ctx, msg)
+	if err != nil {
+		c.acks.remove(msg.MessageId)
+		return nil, err
+	}
+	if resp != nil {
+		// 一元RPC的响应即为服务端确认
+		c.acks.resolve(&imv1.AckContent{OriginalMessageId: msg.MessageId, Success: true, Sequence: resp.Sequence})
+	}
+
+	return delivery, nil
+}
+
+// handleAcks 处理ACK超时和重传
+func (c *Client) handleAcks() {
+	ticker := time.NewTicker(ackCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			c.acks.failAll(ErrClosed)
+			return
+		case now := <-ticker.C:
+			for _, msg := range c.acks.expire(now, c.ackRetransmitInterval(), c.ackMaxRetransmits()) {
+				c.retransmit(msg)
+			}
+		}
+	}
+}
+
+// retransmit 经由正常的发送路径重传等待ACK的消息
+//
+// 未连接或发送队列已满时放弃本次重传；配置了发件箱时重新写入发件箱（仍在发件箱中的消息不会重复）；
+// 按 SendMode 改用一元RPC发送时，RPC成功即完成投递。
+func (c *Client) retransmit(msg *imv1.MessageRequest) {
+	ctx, cancel := context.WithTimeout(c.ctx, ackCheckInterval)
+	defer cancel()
+
+	resp, err := c.routeMessage(ctx, msg)
+	if err != nil {
+		c.logger.Debug("重传消息失败", "message_id", msg.MessageId, "error", err)
+		return
+	}
+	if resp != nil {
+		c.acks.resolve(&imv1.AckContent{OriginalMessageId: msg.MessageId, Success: true, Sequence: resp.Sequence})
+	}
+}
+
+// handleAckMessage 处理收到的ACK消息，返回是否已被SDK消费（匹配到等待中的消息或携带消息序号）
+func (c *Client) handleAckMessage(msg *imv1.MessageResponse) bool {
+	var ack imv1.AckContent
+	if err := proto.Unmarshal(msg.Content, &ack); err != nil || ack.OriginalMessageId == "" {
+		return false
+	}
+	if ack.Sequence > 0 {
+		// 自己发送的消息不会经由消息流返回，用ACK中的序号推进房间的消息序列
+		c.deliver(&imv1.MessageResponse{RoomId: msg.RoomId, Sequence: ack.Sequence}, false)
+	}
+	return c.acks.resolve(&ack) || ack.Sequence > 0
+}
+
+// sendAutoAck 为需要ACK的入站消息回复ACK
+func (c *Client) sendAutoAck(msg *imv1.MessageResponse) {
+	content, err := proto.Marshal(&imv1.AckContent{
+		OriginalMessageId: msg.MessageId,
+		Success:           true,
+	})
+	if err != nil {
+		return
+	}
+
+	ack := &imv1.MessageRequest{
+		MessageId: c.generateMessageID(),
+		UserId:    c.config.UserID,
+		RoomId:    msg.RoomId,
+		Type:      imv1.MessageType_MESSAGE_TYPE_ACK,
+		Content:   content,
+		Timestamp: timestamppb.New(time.Now()),
+	}
+
+	// 在接收协程中调用，发送队列已满时丢弃ACK，不阻塞消息接收
+	select {
+	case c.messageCh <- ack:
+	default:
+		c.logger.Warn("发送队列已满，丢弃自动ACK", "message_id", msg.MessageId, "room_id", msg.RoomId)
+	}
+}
+
+// ackTimeout 等待ACK的默认超时时间
+func (c *Client) ackTimeout() time.Duration {
+	if c.config.AckTimeout > 0 {
+		return c.config.AckTimeout
+	}
+	return defaultAckTimeout
+}
+
+// ackRetransmitInterval 未收到ACK时的重传间隔
+func (c *Client) ackRetransmitInterval() time.Duration {
+	if c.config.AckRetransmitInterval > 0 {
+		return c.config.AckRetransmitInterval
+	}
+	return defaultAckRetransmitInterval
+}
+
+// ackMaxRetransmits 未收到ACK时的最大重传次数
+func (c *Client) ackMaxRetransmits() int {
+	switch {
+	case c.config.AckMaxRetransmits > 0:
+		return c.config.AckMaxRetransmits
+	case c.config.AckMaxRetransmits < 0:
+		return 0
+	}
+	return defaultAckMaxRetransmits
+}
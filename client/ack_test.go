@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+var errDropped = errors.New("dropped by test")
+
+// dropAcks 返回丢弃收到的ACK消息的拦截器，drop 为 false 时放行
+func dropAcks(drop *atomic.Bool) MessageInterceptor {
+	return MessageInterceptorFuncs{
+		Receive: func(msg *imv1.MessageResponse) error {
+			if msg.Type == imv1.MessageType_MESSAGE_TYPE_ACK && drop.Load() {
+				return errDropped
+			}
+			return nil
+		},
+	}
+}
+
+// waitDelivery 等待投递完成
+func waitDelivery(t *testing.T, d *Delivery) (*imv1.AckContent, error) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	ack, err := d.Wait(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("等待消息 %s 的投递结果超时", d.MessageID())
+	}
+	return ack, err
+}
+
+func TestSendWithAckResolvesDelivery(t *testing.T) {
+	srv := newTestServer(t)
+	c := connectTestClient(t, newTestConfig(srv, "alice"))
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+
+	delivery, err := c.SendTextMessageWithAck("room1", "hello", 0)
+	if err != nil {
+		t.Fatalf("SendTextMessageWithAck: %v", err)
+	}
+	ack, err := waitDelivery(t, delivery)
+	if err != nil {
+		t.Fatalf("Delivery: %v", err)
+	}
+	if !ack.Success || ack.OriginalMessageId != delivery.MessageID() || ack.Sequence == 0 {
+		t.Fatalf("ACK = %v, want 成功且带有序号", ack)
+	}
+	if delivery.Err() != nil {
+		t.Fatalf("Err = %v, want nil", delivery.Err())
+	}
+}
+
+func TestSendWithAckTimeout(t *testing.T) {
+	srv := newTestServer(t)
+	var drop atomic.Bool
+	drop.Store(true)
+	config := newTestConfig(srv, "alice")
+	config.AckMaxRetransmits = -1
+	config.MessageInterceptors = []MessageInterceptor{dropAcks(&drop)}
+	c := connectTestClient(t, config)
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+
+	delivery, err := c.SendTextMessageWithAck("room1", "hello", 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("SendTextMessageWithAck: %v", err)
+	}
+	_, err = waitDelivery(t, delivery)
+	if !errors.Is(err, ErrAckTimeout) {
+		t.Fatalf("err = %v, want ErrAckTimeout", err)
+	}
+	if !IsRetryable(err) {
+		t.Fatalf("IsRetryable(%v) = false, want true", err)
+	}
+}
+
+func TestSendWithAckRetransmitsSameMessageID(t *testing.T) {
+	srv := newTestServer(t)
+	var drop atomic.Bool
+	drop.Store(true)
+	config := newTestConfig(srv, "alice")
+	config.AckRetransmitInterval = 10 * time.Millisecond
+	config.AckMaxRetransmits = 5
+	config.MessageInterceptors = []MessageInterceptor{dropAcks(&drop)}
+	c := connectTestClient(t, config)
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+
+	delivery, err := c.SendTextMessageWithAck("room1", "hello", testTimeout)
+	if err != nil {
+		t.Fatalf("SendTextMessageWithAck: %v", err)
+	}
+
+	// 没有收到ACK时重传，重传的消息使用同一个 MessageId
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	sent, err := srv.Received().WaitForCount(ctx, 2, imtest.TextRequest("hello"))
+	if err != nil {
+		t.Fatalf("等待重传失败: %v", err)
+	}
+	for _, msg := range sent {
+		if msg.MessageId != delivery.MessageID() {
+			t.Fatalf("重传的 MessageId = %s, want %s", msg.MessageId, delivery.MessageID())
+		}
+	}
+
+	drop.Store(false)
+	if _, err := waitDelivery(t, delivery); err != nil {
+		t.Fatalf("Delivery: %v", err)
+	}
+}
+
+func TestAutoAckDoesNotBlockReceive(t *testing.T) {
+	srv := newTestServer(t)
+	var received atomic.Int32
+	release := make(chan struct{})
+	unblock := sync.OnceFunc(func() { close(release) })
+	config := newTestConfig(srv, "alice")
+	config.AutoAck = true
+	config.OnMessage = func(msg *imv1.MessageResponse) {
+		if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+			received.Add(1)
+		}
+	}
+	// 阻塞发送协程，自动ACK只能堆积在发送队列中
+	config.MessageInterceptors = []MessageInterceptor{MessageInterceptorFuncs{
+		Send: func(msg *imv1.MessageRequest) error {
+			<-release
+			return nil
+		},
+	}}
+	connectTestClient(t, config)
+	t.Cleanup(unblock)
+	waitSession(t, srv, "alice")
+
+	// 发送队列已满时丢弃自动ACK，消息仍然全部送达
+	const total = 150
+	for i := 0; i < total; i++ {
+		srv.Push("alice", &imv1.MessageResponse{
+			MessageId:   fmt.Sprintf("msg-%d", i),
+			Type:        imv1.MessageType_MESSAGE_TYPE_TEXT,
+			RoomId:      "room1",
+			FromUserId:  "bob",
+			Content:     []byte("hello"),
+			AckRequired: true,
+		})
+	}
+	deadline := time.Now().Add(testTimeout)
+	for received.Load() < total {
+		if time.Now().After(deadline) {
+			t.Fatalf("收到 %d 条消息, want %d", received.Load(), total)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 恢复发送后，队列中的自动ACK发往服务端
+	unblock()
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	ack, err := srv.Received().WaitFor(ctx, imtest.RequestOfType(imv1.MessageType_MESSAGE_TYPE_ACK))
+	if err != nil {
+		t.Fatalf("服务端没有收到自动ACK: %v", err)
+	}
+	var content imv1.AckContent
+	if err := proto.Unmarshal(ack.Content, &content); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !content.Success || content.OriginalMessageId == "" {
+		t.Fatalf("自动ACK = %v", &content)
+	}
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// defaultAttachmentMIMEType 无法从文件名推断类型时使用的MIME类型
+const defaultAttachmentMIMEType = "application/octet-stream"
+
+// Attachment 上传附件的参数
+type Attachment struct {
+	Filename string
+	// MIMEType 附件的MIME类型，为空时按 Filename 的扩展名推断，无法推断时为 application/octet-stream
+	MIMEType string
+
+	// Size 附件总字节数，未知时为0；reader 实现 io.Seeker 时自动计算
+	Size int64
+	// Checksum 完整附件的SHA-256（十六进制），为空且 reader 实现 io.Seeker 时自动计算
+	Checksum string
+
+	// Thumbnail 缩略图数据，为空表示没有缩略图
+	Thumbnail         []byte
+	ThumbnailMIMEType string
+
+	// ChunkSize 每个数据块的字节数，为0时使用 Config.UploadChunkSize
+	ChunkSize int
+	// OnProgress 每发送一个数据块后回调，sent 为已发送的总字节数，total 为0表示总大小未知
+	OnProgress func(sent, total int64)
+}
+
+// UploadAttachment 从 reader 流式上传附件，内存占用只有一个数据块
+//
+// 上传不受 RequestTimeout 限制，只在 ctx 取消或客户端关闭时中止。
+// 服务端返回的校验和与本地计算的不一致时返回错误。
+func (c *Client) UploadAttachment(ctx context.Context, roomID string, r io.Reader, att Attachment) (*imv1.UploadAttachmentResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer func() {
+		stop()
+		cancel()
+	}()
+
+	return c.uploadAttachment(ctx, roomID, r, &att)
+}
+
+// SendAttachment 上传附件并发送附件消息，返回消息中的附件内容
+func (c *Client) SendAttachment(roomID string, r io.Reader, att Attachment) (*imv1.AttachmentContent, error) {
+	return c.SendAttachmentContext(context.Background(), roomID, r, att)
+}
+
+// SendAttachmentContext 上传附件并发送附件消息，ctx 同时约束上传和发送
+func (c *Client) SendAttachmentContext(ctx context.Context, roomID string, r io.Reader, att Attachment) (*imv1.AttachmentContent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer func() {
+		stop()
+		cancel()
+	}()
+
+	resp, err := c.uploadAttachment(ctx, roomID, r, &att)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &imv1.AttachmentContent{
+		AttachmentId: resp.AttachmentId,
+		Url:          resp.Url,
+		MimeType:     att.MIMEType,
+		Filename:     att.Filename,
+		Size:         att.Size,
+		Checksum:     resp.Checksum,
+		ThumbnailUrl: resp.ThumbnailUrl,
+	}
+	if err := c.SendContentContext(ctx, roomID, imv1.MessageType_MESSAGE_TYPE_ATTACHMENT, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// uploadAttachment 上传附件，补全 att 中的MIME类型、大小和校验和
+func (c *Client) uploadAttachment(ctx context.Context, roomID string, r io.Reader, att *Attachment) (resp *imv1.UploadAttachmentResponse, err error) {
+	ctx, finish := c.startRPC(ctx, "UploadAttachment", roomID)
+	defer func() { finish(err) }()
+
+	if att.MIMEType == "" {
+		att.MIMEType = mime.TypeByExtension(filepath.Ext(att.Filename))
+		if att.MIMEType == "" {
+			att.MIMEType = defaultAttachmentMIMEType
+		}
+	}
+
+	var digest hash.Hash
+	if seeker, ok := r.(io.Seeker); ok && (att.Size == 0 || att.Checksum == "") {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("读取附件位置失败: %w", err)
+		}
+		size, checksum, err := measureReader(r, seeker, start)
+		if err != nil {
+			return nil, err
+		}
+		if att.Size == 0 {
+			att.Size = size
+		}
+		if att.Checksum == "" {
+			att.Checksum = checksum
+		}
+	} else if att.Checksum == "" {
+		// 无法预先计算时边上传边计算，上传完成后与服务端返回的校验和比较
+		digest = sha256.New()
+	}
+
+	c.mu.RLock()
+	if c.State() != StateReady {
+		c.mu.RUnlock()
+		return nil, c.notReadyError()
+	}
+	grpcClient := c.client
+	c.mu.RUnlock()
+
+	ctx, _, err = c.authContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := grpcClient.UploadAttachment(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("创建上传流失败: %w", responseError(nil, err))
+	}
+
+	err = stream.Send(&imv1.UploadAttachmentRequest{
+		Data: &imv1.UploadAttachmentRequest_Metadata{Metadata: &imv1.AttachmentMetadata{
+			UserId:            c.config.UserID,
+			RoomId:            roomID,
+			MimeType:          att.MIMEType,
+			Filename:          att.Filename,
+			Size:              att.Size,
+			Checksum:          att.Checksum,
+			Thumbnail:         att.Thumbnail,
+			ThumbnailMimeType: att.ThumbnailMIMEType,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("发送附件元数据失败: %w", uploadSendError(stream, err))
+	}
+
+	chunkSize := att.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = c.uploadChunkSize()
+	}
+	var sent int64
+	err = sendChunks(r, chunkSize, 0, att.Size, digest, func(n, total int64) {
+		sent = n
+		if att.OnProgress != nil {
+			att.OnProgress(n, total)
+		}
+	}, func(chunk []byte) error {
+		err := stream.Send(&imv1.UploadAttachmentRequest{
+			Data: &imv1.UploadAttachmentRequest_Chunk{Chunk: chunk},
+		})
+		if err != nil {
+			return fmt.Errorf("发送附件数据失败: %w", uploadSendError(stream, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = stream.CloseAndRecv()
+	if err = responseError(resp, err); err != nil {
+		return nil, err
+	}
+
+	if digest != nil {
+		att.Checksum = hex.EncodeToString(digest.Sum(nil))
+	}
+	if att.Size == 0 {
+		att.Size = sent
+	}
+	if resp.Checksum != "" && !strings.EqualFold(resp.Checksum, att.Checksum) {
+		return nil, fmt.Errorf("附件校验和不匹配: 本地 %s，服务端 %s", att.Checksum, resp.Checksum)
+	}
+	return resp, nil
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// expectStoredAttachment 检查服务端保存的附件数据，返回附件元数据
+func expectStoredAttachment(t *testing.T, srv *imtest.Server, attachmentID string, want []byte) *imv1.AttachmentMetadata {
+	t.Helper()
+
+	meta, data, err := srv.Attachments().Data(attachmentID)
+	if err != nil {
+		t.Fatalf("Data: %v", err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("服务端保存了 %d 字节，与上传的 %d 字节不一致", len(data), len(want))
+	}
+	return meta
+}
+
+func TestSendAttachment(t *testing.T) {
+	srv := newTestServer(t)
+	config := newTestConfig(srv, "alice")
+	config.UploadChunkSize = 1024
+	c := connectTestClient(t, config)
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	data := audioData(3*1024 + 10)
+	sum := sha256.Sum256(data)
+
+	content, err := c.SendAttachment("room1", bytes.NewReader(data), Attachment{
+		Filename:  "report.pdf",
+		Thumbnail: []byte("thumbnail"),
+	})
+	if err != nil {
+		t.Fatalf("SendAttachment: %v", err)
+	}
+	if content.MimeType != "application/pdf" || content.Size != int64(len(data)) || content.Checksum != hex.EncodeToString(sum[:]) {
+		t.Fatalf("AttachmentContent = %v, want application/pdf, %d 字节, 校验和 %x", content, len(data), sum)
+	}
+	if content.AttachmentId == "" || content.Url == "" || content.ThumbnailUrl == "" {
+		t.Fatalf("AttachmentContent = %v, want 附件ID、URL和缩略图URL", content)
+	}
+	meta := expectStoredAttachment(t, srv, content.AttachmentId, data)
+	if meta.Filename != "report.pdf" || meta.RoomId != "room1" || string(meta.Thumbnail) != "thumbnail" {
+		t.Fatalf("AttachmentMetadata = %v", meta)
+	}
+
+	// 上传完成后发送的附件消息携带附件信息
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	msg, err := srv.Received().WaitFor(ctx, imtest.RequestOfType(imv1.MessageType_MESSAGE_TYPE_ATTACHMENT))
+	if err != nil {
+		t.Fatalf("服务端没有收到附件消息: %v", err)
+	}
+	if msg.RoomId != "room1" {
+		t.Fatalf("RoomId = %s, want room1", msg.RoomId)
+	}
+	decoded, err := DecodeContent(msg.Type, msg.Content, msg.Metadata["content-encoding"])
+	if err != nil {
+		t.Fatalf("DecodeContent: %v", err)
+	}
+	if !proto.Equal(decoded, content) {
+		t.Fatalf("附件消息内容 = %v, want %v", decoded, content)
+	}
+}
+
+func TestUploadAttachmentStreamingDigest(t *testing.T) {
+	srv := newTestServer(t)
+	config := newTestConfig(srv, "alice")
+	config.UploadChunkSize = 1024
+	c := connectTestClient(t, config)
+	data := audioData(2*1024 + 1)
+
+	// 不可 Seek 的 reader 边上传边计算校验和，无法推断类型时使用默认MIME类型
+	var progress []int64
+	att := Attachment{
+		Filename:   "blob",
+		OnProgress: func(sent, total int64) { progress = append(progress, sent) },
+	}
+	resp, err := c.UploadAttachment(context.Background(), "room1", struct{ io.Reader }{bytes.NewReader(data)}, att)
+	if err != nil {
+		t.Fatalf("UploadAttachment: %v", err)
+	}
+	meta := expectStoredAttachment(t, srv, resp.AttachmentId, data)
+	if meta.MimeType != defaultAttachmentMIMEType || meta.Size != 0 || meta.Checksum != "" {
+		t.Fatalf("AttachmentMetadata MimeType = %s, Size = %d, Checksum = %q, want 默认类型且大小和校验和为空", meta.MimeType, meta.Size, meta.Checksum)
+	}
+	if want := []int64{1024, 2048, 2049}; !slices.Equal(progress, want) {
+		t.Fatalf("进度 = %v, want %v", progress, want)
+	}
+	if resp.ThumbnailUrl != "" {
+		t.Fatalf("ThumbnailUrl = %s, want 空", resp.ThumbnailUrl)
+	}
+}
+
+func TestUploadAttachmentChecksumMismatch(t *testing.T) {
+	srv := newTestServer(t)
+	c := connectTestClient(t, newTestConfig(srv, "alice"))
+
+	// 声明的校验和与数据不一致，服务端拒绝保存，也不发送附件消息
+	_, err := c.SendAttachment("room1", bytes.NewReader(audioData(100)), Attachment{
+		Filename: "photo.png",
+		Checksum: strings.Repeat("f", 64),
+	})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != codes.DataLoss {
+		t.Fatalf("err = %v, want DataLoss", err)
+	}
+	if n := len(srv.Received().Filter(imtest.RequestOfType(imv1.MessageType_MESSAGE_TYPE_ATTACHMENT))); n != 0 {
+		t.Fatalf("服务端收到 %d 条附件消息, want 0", n)
+	}
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const (
+	// MetadataAuthorization 携带访问令牌的metadata key
+	MetadataAuthorization = "authorization"
+
+	defaultTokenType          = "Bearer"
+	defaultTokenRefreshWindow = time.Minute
+
+	// 重建的消息流在该时间内再次因令牌被拒绝时不再重建，交由常规重连处理
+	authRecreateGuard = 10 * time.Second
+)
+
+// Token 访问令牌
+type Token struct {
+	AccessToken string    // 令牌内容
+	TokenType   string    // 令牌类型，为空时使用 Bearer
+	Expiry      time.Time // 过期时间，零值表示永不过期
+}
+
+// authorization 返回 authorization metadata 的值
+func (t *Token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" {
+		tokenType = defaultTokenType
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// CredentialsProvider 访问令牌提供者
+//
+// SDK会缓存返回的令牌，在过期前 TokenRefreshWindow 或服务端返回 Unauthenticated 时重新获取。
+type CredentialsProvider interface {
+	// Token 获取新的访问令牌
+	Token(ctx context.Context) (*Token, error)
+}
+
+// CredentialsProviderFunc 函数形式的令牌提供者
+type CredentialsProviderFunc func(ctx context.Context) (*Token, error)
+
+// Token 获取新的访问令牌
+func (f CredentialsProviderFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticToken 返回固定令牌的提供者
+func StaticToken(accessToken string) CredentialsProvider {
+	return CredentialsProviderFunc(func(ctx context.Context) (*Token, error) {
+		return &Token{AccessToken: accessToken}, nil
+	})
+}
+
+// tokenCache 缓存访问令牌，同一时间只有一个请求在获取新令牌
+type tokenCache struct {
+	token *Token
+	mu    sync.Mutex
+}
+
+// newTokenCache 创建令牌缓存
+func newTokenCache() *tokenCache {
+	return &tokenCache{}
+}
+
+// get 返回有效令牌，即将过期时重新获取
+func (tc *tokenCache) get(ctx context.Context, provider CredentialsProvider, refreshWindow time.Duration) (*Token, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.token != nil && (tc.token.Expiry.IsZero() || time.Until(tc.token.Expiry) > refreshWindow) {
+		return tc.token, nil
+	}
+
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil || token.AccessToken == "" {
+		return nil, fmt.Errorf("令牌提供者返回了空令牌")
+	}
+
+	tc.token = token
+	return token, nil
+}
+
+// invalidate 丢弃缓存的令牌，下次使用时重新获取
+func (tc *tokenCache) invalidate(token *Token) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	// 只丢弃被拒绝的令牌，避免并发请求把刚刷新的令牌也丢掉
+	if token == nil || tc.token == token {
+		tc.token = nil
+	}
+}
+
+// authContext 为请求附加访问令牌，未配置 Credentials 时原样返回
+func (c *Client) authContext(ctx context.Context) (context.Context, *Token, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return withToken(ctx, token), token, nil
+}
+
+// token 返回当前有效的访问令牌，未配置 Credentials 时返回nil
+func (c *Client) token(ctx context.Context) (*Token, error) {
+	if c.config.Credentials == nil {
+		return nil, nil
+	}
+
+	token, err := c.tokens.get(ctx, c.config.Credentials, c.tokenRefreshWindow())
+	if err != nil {
+		return nil, fmt.Errorf("获取访问令牌失败: %w", err)
+	}
+	return token, nil
+}
+
+// withToken 把访问令牌写入outgoing metadata，token 为nil时原样返回
+func withToken(ctx context.Context, token *Token) context.Context {
+	if token == nil {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataAuthorization, token.authorization())
+}
+
+// invokeWithCredentials 附加访问令牌后执行一元调用，令牌被拒绝时刷新令牌并重试一次，
+// gRPC错误和响应中非零的 ResponseStatus 转换为 *StatusError
+func invokeWithCredentials[T any](c *Client, ctx context.Context, call func(ctx context.Context) (T, error)) (T, error) {
+	authCtx, token, err := c.authContext(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	resp, err := call(authCtx)
+	if token != nil && status.Code(err) == codes.Unauthenticated {
+		c.tokens.invalidate(token)
+		if authCtx, _, err = c.authContext(ctx); err != nil {
+			var zero T
+			return zero, err
+		}
+		resp, err = call(authCtx)
+	}
+
+	if err = responseError(resp, err); err != nil {
+		var zero T
+		return zero, err
+	}
+	return resp, nil
+}
+
+// recreateStreamForAuth 消息流因令牌被拒绝而断开时，刷新令牌并重建流，不触发断线重连
+//
+// 重建后的流在收到消息前很快再次被拒绝时返回false，交由常规重连流程按退避策略处理。
+func (c *Client) recreateStreamForAuth(stream grpc.BidiStreamingClient[imv1.MessageRequest, imv1.MessageResponse], err error) bool {
+	if c.config.Credentials == nil || status.Code(err) != codes.Unauthenticated {
+		return false
+	}
+
+	if last := c.authRecreatedAt.Load(); last != 0 && time.Since(time.Unix(0, last)) < authRecreateGuard {
+		return false
+	}
+
+	// 刷新令牌可能是网络请求，在加锁之前完成
+	c.tokens.invalidate(nil)
+	token, err := c.streamToken(c.ctx)
+	if err != nil {
+		c.logger.Error("刷新访问令牌失败", "error", err)
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stream != stream || c.State() != StateReady {
+		return true
+	}
+
+	c.logger.Warn("访问令牌被拒绝，刷新令牌后重建消息流")
+	c.authRecreatedAt.Store(time.Now().UnixNano())
+	c.closeStream()
+	if err := c.createStream(token); err != nil {
+		c.logger.Error("重建消息流失败", "error", err)
+		return false
+	}
+	c.notifyOutbox()
+	return true
+}
+
+// tokenRefreshWindow 返回令牌提前刷新的时间窗口
+func (c *Client) tokenRefreshWindow() time.Duration {
+	if c.config.TokenRefreshWindow > 0 {
+		return c.config.TokenRefreshWindow
+	}
+	return defaultTokenRefreshWindow
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+func TestTokenRefreshDoesNotHoldClientLock(t *testing.T) {
+	srv := newTestServer(t)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	config := newTestConfig(srv, "alice")
+	config.Credentials = CredentialsProviderFunc(func(ctx context.Context) (*Token, error) {
+		// 第一次之后的令牌请求阻塞，模拟缓慢的令牌服务
+		if calls.Add(1) > 1 {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return &Token{AccessToken: "token"}, nil
+	})
+	c := connectTestClient(t, config)
+
+	c.tokens.invalidate(nil)
+	dropStreams(t, srv, 1)
+	waitState(t, c, StateReconnecting)
+
+	// 重连在等待令牌时，其他调用不应被 c.mu 阻塞
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.JoinRoom("room1", nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("重连期间 JoinRoom 应返回未连接错误")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("获取令牌期间 JoinRoom 被阻塞")
+	}
+
+	close(release)
+	waitState(t, c, StateReady)
+}
+
+func TestSendDuringAuthRecreate(t *testing.T) {
+	srv := newTestServer(t)
+
+	var tokens atomic.Int32
+	config := newTestConfig(srv, "alice")
+	config.DefaultRoomID = "room1"
+	config.Credentials = CredentialsProviderFunc(func(ctx context.Context) (*Token, error) {
+		tokens.Add(1)
+		return &Token{AccessToken: "token"}, nil
+	})
+	c := connectTestClient(t, config)
+	changes, unsubscribe := c.SubscribeState()
+	defer unsubscribe()
+
+	// 令牌被拒绝时在 Ready 状态下重建消息流，同时持续发送
+	stop := sendContinuously(t, c, "room1")
+	waitStreams(t, srv, 1)
+	before := tokens.Load()
+	srv.FailStreams(status.Error(codes.Unauthenticated, "token expired"))
+	waitStreams(t, srv, 1)
+	stop()
+
+	if tokens.Load() <= before {
+		t.Fatal("重建消息流前没有刷新令牌")
+	}
+	select {
+	case change := <-changes:
+		t.Fatalf("重新认证不应改变连接状态: %s -> %s", change.From, change.To)
+	default:
+	}
+	if err := c.SendTextMessage("room1", "after"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	waitReceived(t, srv, "after")
+}
+
+func TestUnaryTokenRefreshDoesNotHoldClientLock(t *testing.T) {
+	calls := map[string]func(c *Client) error{
+		"JoinRoom": func(c *Client) error {
+			_, err := c.JoinRoom("room1", nil)
+			return err
+		},
+		"LeaveRoom": func(c *Client) error {
+			_, err := c.LeaveRoom("room1")
+			return err
+		},
+		"GetRoomInfo": func(c *Client) error {
+			_, err := c.GetRoomInfo("room1")
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			srv := newTestServer(t)
+
+			var blocking atomic.Bool
+			fetching := make(chan struct{}, 1)
+			release := make(chan struct{})
+			config := newTestConfig(srv, "alice")
+			config.Credentials = CredentialsProviderFunc(func(ctx context.Context) (*Token, error) {
+				if blocking.Load() {
+					fetching <- struct{}{}
+					<-release
+				}
+				return &Token{AccessToken: "token"}, nil
+			})
+			c := connectTestClient(t, config)
+			unblock := sync.OnceFunc(func() { close(release) })
+			t.Cleanup(unblock)
+
+			// 一元调用等待令牌期间，需要写锁的 Disconnect 不应被阻塞
+			c.tokens.invalidate(nil)
+			blocking.Store(true)
+			done := make(chan error, 1)
+			go func() { done <- call(c) }()
+			<-fetching
+
+			disconnected := make(chan struct{})
+			go func() {
+				c.Disconnect()
+				close(disconnected)
+			}()
+			select {
+			case <-disconnected:
+			case <-time.After(time.Second):
+				t.Fatalf("%s 获取令牌期间 Disconnect 被阻塞", name)
+			}
+
+			unblock()
+			select {
+			case <-done:
+			case <-time.After(testTimeout):
+				t.Fatalf("%s 没有返回", name)
+			}
+		})
+	}
+}
+
+func TestUnaryRetriesUnauthenticated(t *testing.T) {
+	srv := newTestServer(t)
+
+	var tokens, rejected atomic.Int32
+	config := newTestConfig(srv, "alice")
+	config.Credentials = CredentialsProviderFunc(func(ctx context.Context) (*Token, error) {
+		return &Token{AccessToken: fmt.Sprintf("token-%d", tokens.Add(1))}, nil
+	})
+	// 服务端拒绝第一次 JoinRoom 的令牌
+	config.UnaryInterceptors = []grpc.UnaryClientInterceptor{
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if method == imv1.IMService_JoinRoom_FullMethodName && rejected.Add(1) == 1 {
+				return status.Error(codes.Unauthenticated, "token expired")
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		},
+	}
+	c := connectTestClient(t, config)
+
+	before := tokens.Load()
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	if got := tokens.Load() - before; got != 1 {
+		t.Fatalf("令牌被拒绝后获取了 %d 次新令牌, want 1", got)
+	}
+	if got := rejected.Load(); got != 2 {
+		t.Fatalf("JoinRoom 调用 %d 次, want 2", got)
+	}
+	if !srv.Rooms().IsMember("room1", "alice") {
+		t.Fatal("重试后应加入房间")
+	}
+}
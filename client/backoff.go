@@ -0,0 +1,138 @@
+package client
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BackoffPolicy 重连退避策略
+type BackoffPolicy interface {
+	// Next 返回第 attempt 次（从1开始）重连失败后的等待时间，prev 为上一次的等待时间，
+	// 返回 false 表示放弃重连
+	Next(attempt int, prev time.Duration) (time.Duration, bool)
+}
+
+// ConstantBackoff 固定间隔退避，MaxRetries 小于等于0时无限重试
+type ConstantBackoff struct {
+	Interval   time.Duration
+	MaxRetries int
+}
+
+// NewConstantBackoff 创建固定间隔退避策略
+func NewConstantBackoff(interval time.Duration, maxRetries int) *ConstantBackoff {
+	return &ConstantBackoff{
+		Interval:   interval,
+		MaxRetries: maxRetries,
+	}
+}
+
+// Next 返回下一次重连前的等待时间
+func (b *ConstantBackoff) Next(attempt int, prev time.Duration) (time.Duration, bool) {
+	if b.MaxRetries > 0 && attempt >= b.MaxRetries {
+		return 0, false
+	}
+	return b.Interval, true
+}
+
+// ExponentialBackoff 指数退避，等待时间按 Multiplier 倍增长并以 Max 封顶，
+// Jitter 为随机抖动比例（0~1），MaxRetries 小于等于0时无限重试
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+	MaxRetries int
+}
+
+// NewExponentialBackoff 创建指数退避策略（倍数2，抖动20%，无限重试）
+func NewExponentialBackoff(initial, max time.Duration) *ExponentialBackoff {
+	return &ExponentialBackoff{
+		Initial:    initial,
+		Max:        max,
+		Multiplier: 2,
+		Jitter:     0.2,
+	}
+}
+
+// Next 返回下一次重连前的等待时间
+func (b *ExponentialBackoff) Next(attempt int, prev time.Duration) (time.Duration, bool) {
+	if b.MaxRetries > 0 && attempt >= b.MaxRetries {
+		return 0, false
+	}
+
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(attempt-1))
+	if b.Jitter > 0 {
+		delay += delay * b.Jitter * (2*randFloat64() - 1)
+	}
+
+	// 先加抖动再封顶，保证等待时间不超过 Max；Max 为0时 math.Pow 可能溢出为 +Inf
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	return clampDuration(delay), true
+}
+
+// clampDuration 把浮点数转换为 time.Duration，超出范围（包括 +Inf）时取最大值，NaN（Initial 为0）时为0
+func clampDuration(d float64) time.Duration {
+	switch {
+	case math.IsNaN(d) || d <= 0:
+		return 0
+	case d >= float64(math.MaxInt64):
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// DecorrelatedJitterBackoff 去相关抖动退避：等待时间在 [Base, prev*3] 之间随机选取并以 Max 封顶，
+// MaxRetries 小于等于0时无限重试
+type DecorrelatedJitterBackoff struct {
+	Base       time.Duration
+	Max        time.Duration
+	MaxRetries int
+}
+
+// NewDecorrelatedJitterBackoff 创建去相关抖动退避策略（无限重试）
+func NewDecorrelatedJitterBackoff(base, max time.Duration) *DecorrelatedJitterBackoff {
+	return &DecorrelatedJitterBackoff{
+		Base: base,
+		Max:  max,
+	}
+}
+
+// Next 返回下一次重连前的等待时间
+func (b *DecorrelatedJitterBackoff) Next(attempt int, prev time.Duration) (time.Duration, bool) {
+	if b.MaxRetries > 0 && attempt >= b.MaxRetries {
+		return 0, false
+	}
+
+	if prev < b.Base {
+		prev = b.Base
+	}
+
+	upper := float64(prev) * 3
+	delay := float64(b.Base) + randFloat64()*(upper-float64(b.Base))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+
+	return clampDuration(delay), true
+}
+
+var (
+	backoffRand   = rand.New(rand.NewSource(time.Now().UnixNano()))
+	backoffRandMu sync.Mutex
+)
+
+// randFloat64 返回 [0,1) 之间的随机数
+func randFloat64() float64 {
+	backoffRandMu.Lock()
+	defer backoffRandMu.Unlock()
+	return backoffRand.Float64()
+}
@@ -0,0 +1,196 @@
+package client
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		attempt    int
+		wantOK     bool
+	}{
+		{"无限重试", 0, 100, true},
+		{"未到上限", 3, 2, true},
+		{"达到上限", 3, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewConstantBackoff(time.Second, tt.maxRetries)
+			delay, ok := b.Next(tt.attempt, 0)
+			if ok != tt.wantOK {
+				t.Fatalf("Next(%d) ok = %v, want %v", tt.attempt, ok, tt.wantOK)
+			}
+			if ok && delay != time.Second {
+				t.Fatalf("Next(%d) = %v, want 1s", tt.attempt, delay)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff ExponentialBackoff
+		attempt int
+		min     time.Duration
+		max     time.Duration
+		wantOK  bool
+	}{
+		{
+			name:    "无抖动按倍数增长",
+			backoff: ExponentialBackoff{Initial: time.Second, Max: time.Minute, Multiplier: 2},
+			attempt: 3,
+			min:     4 * time.Second,
+			max:     4 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "抖动范围",
+			backoff: ExponentialBackoff{Initial: time.Second, Max: time.Minute, Multiplier: 2, Jitter: 0.2},
+			attempt: 1,
+			min:     800 * time.Millisecond,
+			max:     1200 * time.Millisecond,
+			wantOK:  true,
+		},
+		{
+			name:    "抖动后仍以Max封顶",
+			backoff: ExponentialBackoff{Initial: time.Second, Max: 10 * time.Second, Multiplier: 2, Jitter: 0.5},
+			attempt: 4,
+			min:     4 * time.Second,
+			max:     10 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "封顶后的等待时间",
+			backoff: ExponentialBackoff{Initial: time.Second, Max: 10 * time.Second, Multiplier: 2},
+			attempt: 1000,
+			min:     10 * time.Second,
+			max:     10 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "未设置Max时溢出取最大值",
+			backoff: ExponentialBackoff{Initial: time.Second, Multiplier: 2},
+			attempt: 2000,
+			min:     math.MaxInt64,
+			max:     math.MaxInt64,
+			wantOK:  true,
+		},
+		{
+			name:    "Initial为0",
+			backoff: ExponentialBackoff{Multiplier: 2},
+			attempt: 2000,
+			min:     0,
+			max:     0,
+			wantOK:  true,
+		},
+		{
+			name:    "Multiplier小于1时按2计算",
+			backoff: ExponentialBackoff{Initial: time.Second, Max: time.Minute},
+			attempt: 2,
+			min:     2 * time.Second,
+			max:     2 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "达到MaxRetries",
+			backoff: ExponentialBackoff{Initial: time.Second, Max: time.Minute, Multiplier: 2, MaxRetries: 5},
+			attempt: 5,
+			wantOK:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 抖动是随机的，多次取样检查范围
+			for i := 0; i < 200; i++ {
+				delay, ok := tt.backoff.Next(tt.attempt, 0)
+				if ok != tt.wantOK {
+					t.Fatalf("Next(%d) ok = %v, want %v", tt.attempt, ok, tt.wantOK)
+				}
+				if !ok {
+					return
+				}
+				if delay < tt.min || delay > tt.max {
+					t.Fatalf("Next(%d) = %v, want [%v, %v]", tt.attempt, delay, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff DecorrelatedJitterBackoff
+		prev    time.Duration
+		attempt int
+		min     time.Duration
+		max     time.Duration
+		wantOK  bool
+	}{
+		{
+			name:    "首次等待在Base到3倍Base之间",
+			backoff: DecorrelatedJitterBackoff{Base: time.Second, Max: time.Minute},
+			attempt: 1,
+			min:     time.Second,
+			max:     3 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "根据上一次等待时间增长",
+			backoff: DecorrelatedJitterBackoff{Base: time.Second, Max: time.Minute},
+			prev:    5 * time.Second,
+			attempt: 3,
+			min:     time.Second,
+			max:     15 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "以Max封顶",
+			backoff: DecorrelatedJitterBackoff{Base: time.Second, Max: 2 * time.Second},
+			prev:    time.Hour,
+			attempt: 10,
+			min:     time.Second,
+			max:     2 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "未设置Max时溢出取最大值",
+			backoff: DecorrelatedJitterBackoff{Base: time.Second},
+			prev:    math.MaxInt64,
+			attempt: 10,
+			min:     time.Second,
+			max:     math.MaxInt64,
+			wantOK:  true,
+		},
+		{
+			name:    "达到MaxRetries",
+			backoff: DecorrelatedJitterBackoff{Base: time.Second, Max: time.Minute, MaxRetries: 3},
+			attempt: 3,
+			wantOK:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 200; i++ {
+				delay, ok := tt.backoff.Next(tt.attempt, tt.prev)
+				if ok != tt.wantOK {
+					t.Fatalf("Next(%d) ok = %v, want %v", tt.attempt, ok, tt.wantOK)
+				}
+				if !ok {
+					return
+				}
+				if delay < tt.min || delay > tt.max {
+					t.Fatalf("Next(%d, %v) = %v, want [%v, %v]", tt.attempt, tt.prev, delay, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
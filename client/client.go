@@ -2,14 +2,18 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -29,19 +33,87 @@ type Config struct {
 	RequestTimeout    time.Duration `json:"request_timeout"`
 	HeartbeatInterval time.Duration `json:"heartbeat_interval"`
 
+	// Dialer 自定义拨号函数（如测试用的 bufconn 或代理），为nil时使用TCP
+	Dialer func(ctx context.Context, address string) (net.Conn, error) `json:"-"`
+
+	// TLS 传输安全配置，为nil时使用明文连接
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// 拦截器配置，gRPC拦截器用于SDK自管理连接和 NewClientWithConn，DialOptions 只用于SDK自管理连接
+	UnaryInterceptors   []grpc.UnaryClientInterceptor  `json:"-"`
+	StreamInterceptors  []grpc.StreamClientInterceptor `json:"-"`
+	DialOptions         []grpc.DialOption              `json:"-"` // 追加到SDK默认拨号选项之后
+	MessageInterceptors []MessageInterceptor           `json:"-"` // 拦截流上收发的每一条消息
+
+	// Logger 日志输出，为nil时不输出日志
+	Logger Logger `json:"-"`
+
+	// Metrics 指标收集，为nil时不收集
+	Metrics Metrics `json:"-"`
+
+	// 链路追踪配置，TracerProvider 为nil时不启用，Propagator 为nil时使用 W3C Trace Context
+	TracerProvider trace.TracerProvider          `json:"-"`
+	Propagator     propagation.TextMapPropagator `json:"-"`
+
+	// 认证配置，Credentials 为nil时不携带访问令牌
+	Credentials        CredentialsProvider `json:"-"`
+	TokenRefreshWindow time.Duration       `json:"token_refresh_window"` // 令牌过期前提前刷新的时间
+
 	// 重连配置
 	MaxRetries    int           `json:"max_retries"`
 	RetryInterval time.Duration `json:"retry_interval"`
+	Backoff       BackoffPolicy `json:"-"` // 重连退避策略，为nil时按 MaxRetries/RetryInterval 固定间隔重试，MaxRetries 小于等于0时不自动重连
+
+	// ACK配置
+	AckTimeout            time.Duration `json:"ack_timeout"`             // 等待ACK的默认超时时间
+	AckRetransmitInterval time.Duration `json:"ack_retransmit_interval"` // 未收到ACK时的重传间隔
+	AckMaxRetransmits     int           `json:"ack_max_retransmits"`     // 最大重传次数，小于0表示不重传
+	AutoAck               bool          `json:"auto_ack"`                // 自动回复 ack_required 的入站消息
 
 	// 用户信息
 	UserID        string `json:"user_id"`
 	DefaultRoomID string `json:"default_room_id"`
 
+	// 重连后自动重新加入通过 JoinRoom 加入的房间
+	AutoRejoinRooms bool `json:"auto_rejoin_rooms"`
+
+	// 断线补齐，重连后通过 GetHistory 拉取每个房间断线期间错过的消息并分发，同一房间最近的重复消息只分发一次
+	GapFill      bool `json:"gap_fill"`
+	GapFillLimit int  `json:"gap_fill_limit"` // 每个房间最多补齐的消息数，为0时使用500
+
+	// ReorderTimeout 房间消息序号不连续时等待缺失消息到达的时间，超时后通过 GetHistory 拉取，为0时使用200ms
+	ReorderTimeout time.Duration `json:"reorder_timeout"`
+
+	// 发件箱，配置后消息先持久化再发送，断线期间的消息在重连后按顺序补发
+	Outbox Outbox `json:"-"`
+
+	// 转写配置，OnTranscript 不为nil时 UploadAudio 成功后自动订阅转写结果
+	TranscriptPollBackoff BackoffPolicy             `json:"-"`                  // 轮询转写结果的间隔策略，为nil时从500ms指数增长到5s
+	TranscriptTimeout     time.Duration             `json:"transcript_timeout"` // 自动订阅时的最长等待时间
+	OnTranscript          func(*imv1.Transcription) `json:"-"`                  // 转写完成或失败时回调
+
+	// 音频上传配置
+	UploadChunkSize int           `json:"upload_chunk_size"` // 上传数据块大小，为0时使用32KB
+	UploadBackoff   BackoffPolicy `json:"-"`                 // 续传上传的重试策略，为nil时最多重试5次
+
+	// 实时语音配置
+	VoiceJitterDelay time.Duration `json:"voice_jitter_delay"` // 语音帧在采集时间之后的播放延迟，为0时使用60ms
+
+	// 消息发送方式（stream/unary/stream_with_fallback），为空时通过消息流发送
+	SendMode SendMode `json:"send_mode"`
+
+	// 文本和富文本消息的内容编码（raw/protobuf），为空时文本使用raw、富文本使用protobuf
+	ContentEncoding string `json:"content_encoding"`
+
 	// 回调函数
 	OnMessage    func(*imv1.MessageResponse) `json:"-"`
 	OnConnect    func()                      `json:"-"`
 	OnDisconnect func(error)                 `json:"-"`
 	OnError      func(error)                 `json:"-"`
+	OnGiveUp     func(error)                 `json:"-"` // 放弃重连时回调，之后可调用 Reconnect 恢复
+
+	// OnRoomRestoreFailed 重连后重新加入房间失败时回调
+	OnRoomRestoreFailed func(roomID string, err error) `json:"-"`
 }
 
 // DefaultConfig 返回默认配置
@@ -54,6 +126,17 @@ func DefaultConfig() *Config {
 		MaxRetries:        3,
 		RetryInterval:     5 * time.Second,
 		LoadBalancer:      discovery.NewRoundRobinBalancer(),
+
+		AckTimeout:            defaultAckTimeout,
+		AckRetransmitInterval: defaultAckRetransmitInterval,
+		AckMaxRetransmits:     defaultAckMaxRetransmits,
+		AutoAck:               true,
+
+		AutoRejoinRooms: true,
+		GapFill:         true,
+
+		UploadChunkSize:   defaultUploadChunkSize,
+		TranscriptTimeout: defaultTranscriptTimeout,
 	}
 }
 
@@ -64,20 +147,53 @@ type Client struct {
 	client imv1.IMServiceClient
 	stream grpc.BidiStreamingClient[imv1.MessageRequest, imv1.MessageResponse]
 
+	// streamCancel 取消当前消息流，sendMu 串行化流上的 Send 和 CloseSend（gRPC 不允许二者并发）
+	streamCancel context.CancelFunc
+	sendMu       sync.Mutex
+
 	// 状态管理
-	connected bool
-	mu        sync.RWMutex
-	ctx       context.Context
-	cancel    context.CancelFunc
+	state   *stateTracker
+	started bool
+	mu      sync.RWMutex
+	ctx     context.Context
+	cancel  context.CancelFunc
 
 	// 服务发现
 	services []*discovery.ServiceInfo
+	address  string // 当前连接的服务地址
+
+	logger Logger
+
+	// TLS证书加载
+	tls *tlsReloader
+
+	// 访问令牌
+	tokens          *tokenCache
+	authRecreatedAt atomic.Int64
+
+	// 最近一次心跳发送时间，用于计算心跳往返时间
+	lastPing atomic.Int64
 
 	// 消息处理
-	messageCh chan *imv1.MessageRequest
+	messageCh     chan *imv1.MessageRequest
+	outboxCh      chan struct{}
+	outboxChecked sync.Once // 首次处理发件箱时报告重放时丢弃的数据
+	handlers      *handlerRegistry
+
+	// ACK跟踪
+	acks *ackTracker
+
+	// 已加入的房间
+	rooms *roomTracker
+
+	// 每个房间的接收记录和消息序列，dispatchMu 保证补齐的消息与消息流上的消息串行分发
+	history    *historyTracker
+	sequences  *sequencer
+	dispatchMu sync.Mutex
 
 	// 重连
-	reconnectCh chan struct{}
+	reconnectCh       chan struct{}
+	manualReconnectCh chan struct{}
 }
 
 // NewClient 创建新的IM客户端
@@ -90,18 +206,7 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("用户ID不能为空")
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-
-	client := &Client{
-		config:      config,
-		connected:   false,
-		ctx:         ctx,
-		cancel:      cancel,
-		messageCh:   make(chan *imv1.MessageRequest, 100),
-		reconnectCh: make(chan struct{}, 1),
-	}
-
-	return client, nil
+	return newClient(config, nil), nil
 }
 
 // NewClientWithGRPC 使用已有的gRPC客户端创建IM客户端
@@ -121,21 +226,17 @@ func NewClientWithGRPC(grpcClient imv1.IMServiceClient, userID string) (*Client,
 		HeartbeatInterval: 30 * time.Second,
 		MaxRetries:        3,
 		RetryInterval:     5 * time.Second,
-	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+		AckTimeout:            defaultAckTimeout,
+		AckRetransmitInterval: defaultAckRetransmitInterval,
+		AckMaxRetransmits:     defaultAckMaxRetransmits,
+		AutoAck:               true,
 
-	client := &Client{
-		config:      config,
-		client:      grpcClient, // 直接使用传入的gRPC客户端
-		connected:   false,
-		ctx:         ctx,
-		cancel:      cancel,
-		messageCh:   make(chan *imv1.MessageRequest, 100),
-		reconnectCh: make(chan struct{}, 1),
+		AutoRejoinRooms: true,
+		GapFill:         true,
 	}
 
-	return client, nil
+	return newClient(config, grpcClient), nil
 }
 
 // NewClientWithGRPCAndConfig 使用已有的gRPC客户端和自定义配置创建IM客户端
@@ -152,245 +253,388 @@ func NewClientWithGRPCAndConfig(grpcClient imv1.IMServiceClient, config *Config)
 		return nil, fmt.Errorf("用户ID不能为空")
 	}
 
+	return newClient(config, grpcClient), nil
+}
+
+// NewClientWithConn 使用已有的gRPC连接和自定义配置创建IM客户端，
+// Config 中的 UnaryInterceptors 和 StreamInterceptors 会作用于该连接上SDK发起的调用
+func NewClientWithConn(conn grpc.ClientConnInterface, config *Config) (*Client, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("gRPC连接不能为空")
+	}
+
+	if config == nil {
+		return nil, fmt.Errorf("配置不能为空")
+	}
+
+	if config.UserID == "" {
+		return nil, fmt.Errorf("用户ID不能为空")
+	}
+
+	return newClient(config, imv1.NewIMServiceClient(newInterceptedConn(conn, config))), nil
+}
+
+// newClient 初始化客户端，grpcClient 为nil时由SDK自行建立连接
+func newClient(config *Config, grpcClient imv1.IMServiceClient) *Client {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	client := &Client{
+	return &Client{
 		config:      config,
 		client:      grpcClient, // 直接使用传入的gRPC客户端
-		connected:   false,
+		state:       newStateTracker(),
 		ctx:         ctx,
 		cancel:      cancel,
 		messageCh:   make(chan *imv1.MessageRequest, 100),
+		outboxCh:    make(chan struct{}, 1),
+		handlers:    newHandlerRegistry(),
+		acks:        newAckTracker(),
+		rooms:       newRoomTracker(),
+		history:     newHistoryTracker(),
+		sequences:   newSequencer(),
+		tokens:      newTokenCache(),
+		logger:      newClientLogger(config),
 		reconnectCh: make(chan struct{}, 1),
-	}
 
-	return client, nil
+		manualReconnectCh: make(chan struct{}, 1),
+	}
 }
 
 // Connect 连接到IM服务
 func (c *Client) Connect() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return c.ConnectContext(context.Background())
+}
+
+// ConnectContext 连接到IM服务，ctx 只约束服务发现、建立连接和创建消息流的过程，
+// 连接建立后的生命周期不受 ctx 影响
+func (c *Client) ConnectContext(ctx context.Context) error {
+	change, changed, err := c.connect(ctx)
+	c.fireStateCallbacks(change, changed, nil)
+	return err
+}
 
-	if c.connected {
-		return fmt.Errorf("客户端已连接")
+// connect 建立连接并启动后台goroutines
+func (c *Client) connect(ctx context.Context) (StateChange, bool, error) {
+	// 获取令牌可能是网络请求，在加锁之前完成
+	token, err := c.streamToken(ctx)
+	if err != nil {
+		return StateChange{}, false, fmt.Errorf("创建流连接失败: %w", err)
 	}
 
-	// 如果已经有gRPC客户端（通过NewClientWithGRPC创建），跳过连接建立
-	if c.client != nil {
-		// 直接创建流连接
-		if err := c.createStream(); err != nil {
-			return fmt.Errorf("创建流连接失败: %v", err)
-		}
-	} else {
-		// 原有的连接建立流程
-		// 发现服务
-		if err := c.discoverServices(); err != nil {
-			return fmt.Errorf("服务发现失败: %v", err)
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		// 建立连接
-		if err := c.establishConnection(); err != nil {
-			return fmt.Errorf("建立连接失败: %v", err)
-		}
+	switch state := c.State(); state {
+	case StateIdle:
+	case StateReady:
+		return StateChange{}, false, fmt.Errorf("客户端已连接")
+	default:
+		return StateChange{}, false, fmt.Errorf("客户端当前状态为 %s，无法连接", state)
+	}
+	if c.started {
+		return StateChange{}, false, fmt.Errorf("客户端已放弃重连，请调用 Reconnect 恢复连接")
+	}
 
-		// 创建流连接
-		if err := c.createStream(); err != nil {
-			return fmt.Errorf("创建流连接失败: %v", err)
-		}
+	c.setState(StateConnecting)
+	if err := c.dial(ctx, token); err != nil {
+		c.setState(StateIdle)
+		return StateChange{}, false, err
 	}
 
-	c.connected = true
+	c.started = true
+	change, changed := c.setState(StateReady)
 
 	// 启动后台goroutines
 	go c.handleMessages()
 	go c.handleHeartbeat()
+	go c.handleAcks()
 
-	// 只有在使用自己管理的连接时才启动重连逻辑
-	if c.conn != nil {
-		go c.handleReconnect()
+	// 补齐通过 ResumeSequence 恢复的房间
+	for roomID := range c.sequences.snapshot() {
+		go c.fillResumed(roomID)
 	}
 
+	// 启动重连逻辑，使用外部gRPC客户端时只重新创建消息流
+	go c.handleReconnect()
+
 	// 监听服务变化（只在有服务发现时）
 	if c.config.Discovery != nil {
 		go c.watchServices()
 	}
 
-	// 触发连接回调
-	if c.config.OnConnect != nil {
-		c.config.OnConnect()
+	return change, changed, nil
+}
+
+// dial 建立首次连接，token 用于创建消息流
+func (c *Client) dial(ctx context.Context, token *Token) error {
+	// 如果已经有gRPC客户端（通过NewClientWithGRPC创建），跳过连接建立
+	if c.client != nil {
+		// 直接创建流连接
+		if err := c.createStream(token); err != nil {
+			return fmt.Errorf("创建流连接失败: %w", err)
+		}
+		return nil
+	}
+
+	// 原有的连接建立流程
+	// 发现服务
+	services, err := c.discoverServices(ctx, c.services)
+	if err != nil {
+		return fmt.Errorf("服务发现失败: %w", err)
+	}
+
+	// 建立连接
+	creds, err := c.transportCredentials()
+	if err != nil {
+		return fmt.Errorf("建立连接失败: %w", err)
+	}
+	conn, address, err := c.establishConnection(ctx, services, creds)
+	if err != nil {
+		return fmt.Errorf("建立连接失败: %w", err)
+	}
+	c.useConnection(&connection{conn: conn, address: address, services: services})
+
+	// 创建流连接
+	if err := c.createStream(token); err != nil {
+		return fmt.Errorf("创建流连接失败: %w", err)
 	}
 
 	return nil
 }
 
 // Disconnect 断开连接
+//
+// 连接可用时先进入 Draining 状态，等待已排队的消息发送完成（最长 RequestTimeout），
+// 然后关闭连接进入 Closed 状态，之后客户端不可再使用。
 func (c *Client) Disconnect() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if !c.connected {
+	state := c.State()
+	if state == StateClosed || (state == StateIdle && !c.started) {
+		c.mu.Unlock()
 		return nil
 	}
+	drainChange, drainChanged := c.setState(StateDraining)
+	c.mu.Unlock()
 
-	c.connected = false
-	c.cancel()
+	c.fireStateCallbacks(drainChange, drainChanged, fmt.Errorf("客户端主动断开"))
 
-	if c.stream != nil {
-		c.stream.CloseSend()
+	if state == StateReady {
+		c.drain()
 	}
 
+	c.mu.Lock()
+	c.cancel()
+	c.closeStream()
+
 	// 只关闭自己管理的连接，不关闭注入的gRPC客户端
 	if c.conn != nil {
 		c.conn.Close()
 	}
 
+	c.setState(StateClosed)
+	c.mu.Unlock()
+
 	return nil
 }
 
+// drain 等待发送队列清空
+func (c *Client) drain() {
+	deadline := time.Now().Add(c.config.RequestTimeout)
+	for len(c.messageCh) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // SendTextMessage 发送文本消息
 func (c *Client) SendTextMessage(roomID, content string) error {
-	return c.SendMessage(&imv1.MessageRequest{
-		MessageId: c.generateMessageID(),
-		UserId:    c.config.UserID,
-		RoomId:    roomID,
-		Type:      imv1.MessageType_MESSAGE_TYPE_TEXT,
-		Content:   []byte(content),
-		Timestamp: timestamppb.New(time.Now()),
-	})
+	return c.SendTextMessageContext(context.Background(), roomID, content)
+}
+
+// SendTextMessageContext 发送文本消息
+func (c *Client) SendTextMessageContext(ctx context.Context, roomID, content string) error {
+	return c.SendContentContext(ctx, roomID, imv1.MessageType_MESSAGE_TYPE_TEXT, &imv1.TextContent{Text: content})
 }
 
 // SendMessage 发送消息
+//
+// 配置了 Outbox 时消息写入发件箱后即返回，未连接时也不会失败，
+// 连接恢复后按入队顺序发送。Config.SendMode 为 unary 或重连期间的
+// stream_with_fallback 时通过一元 SendMessage RPC 同步发送。
 func (c *Client) SendMessage(msg *imv1.MessageRequest) error {
+	return c.SendMessageContext(context.Background(), msg)
+}
+
+// SendMessageContext 发送消息，ctx 约束等待写入发送队列的时间，并作为发送span的父span
+//
+// 消息写入发送队列后即返回，之后取消 ctx 不会撤回消息。
+func (c *Client) SendMessageContext(ctx context.Context, msg *imv1.MessageRequest) error {
+	_, err := c.send(ctx, msg)
+	return err
+}
+
+// send 在发送span内按 SendMode 发送消息，使用一元RPC发送时返回服务端响应
+func (c *Client) send(ctx context.Context, msg *imv1.MessageRequest) (*imv1.SendMessageResponse, error) {
+	span := c.startSendSpan(ctx, msg)
+	resp, err := c.routeMessage(ctx, msg)
+	endSpan(span, err)
+	return resp, err
+}
+
+// sendMessage 写入发件箱或发送队列
+func (c *Client) sendMessage(ctx context.Context, msg *imv1.MessageRequest) error {
+	if c.config.Outbox != nil {
+		if err := c.config.Outbox.Enqueue(msg); err != nil {
+			return fmt.Errorf("消息写入发件箱失败: %w", err)
+		}
+		c.notifyOutbox()
+		return nil
+	}
+
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	state := c.State()
+	c.mu.RUnlock()
+	if state != StateReady {
+		return c.notReadyError()
+	}
 
-	if !c.connected {
-		return fmt.Errorf("客户端未连接")
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
+	timer := time.NewTimer(c.config.RequestTimeout)
+	defer timer.Stop()
+
 	select {
 	case c.messageCh <- msg:
+		c.metrics().QueueDepth(len(c.messageCh))
 		return nil
-	case <-time.After(c.config.RequestTimeout):
-		return fmt.Errorf("发送消息超时")
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.ctx.Done():
+		return ErrClosed
+	case <-timer.C:
+		return ErrSendTimeout
 	}
 }
 
 // JoinRoom 加入房间
 func (c *Client) JoinRoom(roomID string, metadata map[string]string) (*imv1.JoinRoomResponse, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	return c.JoinRoomContext(context.Background(), roomID, metadata)
+}
 
-	if !c.connected {
-		return nil, fmt.Errorf("客户端未连接")
+// JoinRoomContext 加入房间
+func (c *Client) JoinRoomContext(ctx context.Context, roomID string, metadata map[string]string) (*imv1.JoinRoomResponse, error) {
+	grpcClient, err := c.readyClient()
+	if err != nil {
+		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(c.ctx, c.config.RequestTimeout)
+	ctx, cancel := c.requestContext(ctx)
 	defer cancel()
 
-	return c.client.JoinRoom(ctx, &imv1.JoinRoomRequest{
-		UserId:   c.config.UserID,
-		RoomId:   roomID,
-		Metadata: metadata,
+	ctx, finish := c.startRPC(ctx, "JoinRoom", roomID)
+	resp, err := invokeWithCredentials(c, ctx, func(ctx context.Context) (*imv1.JoinRoomResponse, error) {
+		return grpcClient.JoinRoom(ctx, &imv1.JoinRoomRequest{
+			UserId:   c.config.UserID,
+			RoomId:   roomID,
+			Metadata: metadata,
+		})
 	})
+	finish(err)
+	if err != nil {
+		return nil, err
+	}
+
+	c.rooms.add(roomID, metadata)
+	if lastActive := resp.GetRoomInfo().GetLastActive(); lastActive != nil {
+		c.history.join(roomID, lastActive.AsTime())
+	} else {
+		c.history.join(roomID, time.Now())
+	}
+	// 恢复的序列落后于服务端时立即拉取缺失的消息
+	if lastSequence := resp.GetRoomInfo().GetLastSequence(); lastSequence > 0 && c.sequences.join(roomID, lastSequence) {
+		c.scheduleGapFetch(roomID)
+	}
+	return resp, nil
 }
 
 // LeaveRoom 离开房间
 func (c *Client) LeaveRoom(roomID string) (*imv1.LeaveRoomResponse, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	return c.LeaveRoomContext(context.Background(), roomID)
+}
 
-	if !c.connected {
-		return nil, fmt.Errorf("客户端未连接")
+// LeaveRoomContext 离开房间
+func (c *Client) LeaveRoomContext(ctx context.Context, roomID string) (*imv1.LeaveRoomResponse, error) {
+	grpcClient, err := c.readyClient()
+	if err != nil {
+		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(c.ctx, c.config.RequestTimeout)
+	ctx, cancel := c.requestContext(ctx)
 	defer cancel()
 
-	return c.client.LeaveRoom(ctx, &imv1.LeaveRoomRequest{
-		UserId: c.config.UserID,
-		RoomId: roomID,
+	ctx, finish := c.startRPC(ctx, "LeaveRoom", roomID)
+	resp, err := invokeWithCredentials(c, ctx, func(ctx context.Context) (*imv1.LeaveRoomResponse, error) {
+		return grpcClient.LeaveRoom(ctx, &imv1.LeaveRoomRequest{
+			UserId: c.config.UserID,
+			RoomId: roomID,
+		})
 	})
+	finish(err)
+	if err != nil {
+		return nil, err
+	}
+
+	c.rooms.remove(roomID)
+	c.history.remove(roomID)
+	c.sequences.remove(roomID)
+	return resp, nil
 }
 
 // GetRoomInfo 获取房间信息
 func (c *Client) GetRoomInfo(roomID string) (*imv1.GetRoomInfoResponse, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	return c.GetRoomInfoContext(context.Background(), roomID)
+}
 
-	if !c.connected {
-		return nil, fmt.Errorf("客户端未连接")
+// GetRoomInfoContext 获取房间信息
+func (c *Client) GetRoomInfoContext(ctx context.Context, roomID string) (*imv1.GetRoomInfoResponse, error) {
+	grpcClient, err := c.readyClient()
+	if err != nil {
+		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(c.ctx, c.config.RequestTimeout)
+	ctx, cancel := c.requestContext(ctx)
 	defer cancel()
 
-	return c.client.GetRoomInfo(ctx, &imv1.GetRoomInfoRequest{
-		RoomId: roomID,
-		UserId: c.config.UserID,
+	ctx, finish := c.startRPC(ctx, "GetRoomInfo", roomID)
+	resp, err := invokeWithCredentials(c, ctx, func(ctx context.Context) (*imv1.GetRoomInfoResponse, error) {
+		return grpcClient.GetRoomInfo(ctx, &imv1.GetRoomInfoRequest{
+			RoomId: roomID,
+			UserId: c.config.UserID,
+		})
 	})
+	finish(err)
+	return resp, err
 }
 
-// UploadAudio 上传音频
-func (c *Client) UploadAudio(roomID string, audioData []byte, format string, duration float64) (*imv1.UploadAudioResponse, error) {
+// readyClient 返回 Ready 状态下当前连接的 gRPC 客户端
+//
+// 只在读锁内取出客户端，获取令牌和等待响应时不持有 c.mu，避免 CredentialsProvider
+// 或缓慢的请求阻塞重连和重新认证。
+func (c *Client) readyClient() (imv1.IMServiceClient, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	if !c.connected {
-		return nil, fmt.Errorf("客户端未连接")
+	if c.State() != StateReady {
+		return nil, c.notReadyError()
 	}
-
-	ctx, cancel := context.WithTimeout(c.ctx, c.config.RequestTimeout)
-	defer cancel()
-
-	stream, err := c.client.UploadAudio(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("创建上传流失败: %v", err)
-	}
-
-	// 发送元数据
-	err = stream.Send(&imv1.UploadAudioRequest{
-		Data: &imv1.UploadAudioRequest_Metadata{
-			Metadata: &imv1.AudioMetadata{
-				UserId:   c.config.UserID,
-				RoomId:   roomID,
-				Format:   format,
-				Size:     int64(len(audioData)),
-				Duration: duration,
-			},
-		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("发送音频元数据失败: %v", err)
-	}
-
-	// 分块发送音频数据
-	chunkSize := 1024 * 32 // 32KB chunks
-	for i := 0; i < len(audioData); i += chunkSize {
-		end := i + chunkSize
-		if end > len(audioData) {
-			end = len(audioData)
-		}
-
-		err = stream.Send(&imv1.UploadAudioRequest{
-			Data: &imv1.UploadAudioRequest_Chunk{
-				Chunk: audioData[i:end],
-			},
-		})
-		if err != nil {
-			return nil, fmt.Errorf("发送音频数据失败: %v", err)
-		}
-	}
-
-	return stream.CloseAndRecv()
+	return c.client, nil
 }
 
 // IsConnected 检查连接状态
 func (c *Client) IsConnected() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.connected
+	return c.State() == StateReady
 }
 
 // SetServices 设置服务列表（用于直连模式）
@@ -403,83 +647,135 @@ func (c *Client) SetServices(services []*discovery.ServiceInfo) {
 	}
 }
 
-// discoverServices 发现服务
-func (c *Client) discoverServices() error {
+// discoverServices 发现服务，未配置服务发现时返回 known（已有的服务列表）
+func (c *Client) discoverServices(ctx context.Context, known []*discovery.ServiceInfo) ([]*discovery.ServiceInfo, error) {
 	if c.config.Discovery == nil {
 		// 如果没有配置服务发现，检查是否已有服务列表
-		if len(c.services) == 0 {
-			return fmt.Errorf("未配置服务发现且没有可用服务")
+		if len(known) == 0 {
+			return nil, fmt.Errorf("未配置服务发现且没有可用服务")
 		}
-		return nil
+		return known, nil
 	}
 
-	ctx, cancel := context.WithTimeout(c.ctx, c.config.ConnectTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.config.ConnectTimeout)
 	defer cancel()
 
 	services, err := c.config.Discovery.Discover(ctx, c.config.ServiceName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(services) == 0 {
-		return fmt.Errorf("未发现可用服务")
+		return nil, fmt.Errorf("未发现可用服务")
 	}
 
-	c.services = services
 	c.config.LoadBalancer.Update(services)
-
-	return nil
+	return services, nil
 }
 
-// establishConnection 建立gRPC连接
-func (c *Client) establishConnection() error {
-	if len(c.services) == 0 {
-		return fmt.Errorf("没有可用的服务")
+// establishConnection 从 services 中选择服务并使用 creds 建立gRPC连接，不修改客户端状态，调用时无需持有 c.mu
+func (c *Client) establishConnection(ctx context.Context, services []*discovery.ServiceInfo, creds credentials.TransportCredentials) (*grpc.ClientConn, string, error) {
+	if len(services) == 0 {
+		return nil, "", fmt.Errorf("没有可用的服务")
 	}
 
-	service, err := c.config.LoadBalancer.Select(c.services)
+	service, err := c.config.LoadBalancer.Select(services)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
 	address := fmt.Sprintf("%s:%d", service.Address, service.Port)
 
-	ctx, cancel := context.WithTimeout(c.ctx, c.config.ConnectTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.config.ConnectTimeout)
 	defer cancel()
 
-	conn, err := grpc.DialContext(ctx, address,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
 		grpc.WithBlock(),
-	)
+	}
+	if c.config.Dialer != nil {
+		opts = append(opts, grpc.WithContextDialer(c.config.Dialer))
+	}
+	opts = append(opts, c.dialOptions()...)
+
+	conn, err := grpc.DialContext(ctx, address, opts...)
 	if err != nil {
-		return fmt.Errorf("连接到 %s 失败: %v", address, err)
+		return nil, "", fmt.Errorf("连接到 %s 失败: %w", address, err)
 	}
 
-	c.conn = conn
-	c.client = imv1.NewIMServiceClient(conn)
+	c.logger.Info("已连接到服务", "service_id", service.ID, "address", address)
+	return conn, address, nil
+}
+
+// redial 重新发现服务并建立新的gRPC连接，调用时不能持有 c.mu
+func (c *Client) redial(ctx context.Context) (*connection, error) {
+	// TLS证书在首次使用时加载，与 Connect 一样在 c.mu 内初始化
+	c.mu.Lock()
+	known := c.services
+	creds, err := c.transportCredentials()
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	services, err := c.discoverServices(ctx, known)
+	if err != nil {
+		return nil, err
+	}
+	conn, address, err := c.establishConnection(ctx, services, creds)
+	if err != nil {
+		return nil, err
+	}
+	return &connection{conn: conn, address: address, services: services}, nil
+}
+
+// connection 新建立的gRPC连接
+type connection struct {
+	conn     *grpc.ClientConn
+	address  string
+	services []*discovery.ServiceInfo
+}
+
+// useConnection 切换到新建立的连接，调用方需持有 c.mu 写锁
+func (c *Client) useConnection(conn *connection) {
+	c.conn = conn.conn
+	c.client = imv1.NewIMServiceClient(conn.conn)
+	c.address = conn.address
+	c.services = conn.services
 }
 
-// createStream 创建双向流
-func (c *Client) createStream() error {
-	// 创建带有用户信息的 metadata context
-	ctx := c.ctx
-	if c.config.UserID != "" && c.config.DefaultRoomID != "" {
-		ctx = metadata.AppendToOutgoingContext(c.ctx,
-			"user-id", c.config.UserID,
-			"room-id", c.config.DefaultRoomID)
+// streamToken 获取创建消息流使用的访问令牌，可能需要请求 CredentialsProvider，调用时不能持有 c.mu
+func (c *Client) streamToken(ctx context.Context) (*Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.ConnectTimeout)
+	defer cancel()
+	return c.token(ctx)
+}
+
+// createStream 使用 streamToken 获取的令牌创建双向流，流的生命周期跟随客户端，调用方需持有 c.mu 写锁
+func (c *Client) createStream(token *Token) error {
+	// 创建带有用户信息的 metadata context，sequence-ack 让服务端在ACK中返回自己发送的消息的序号
+	streamCtx, cancel := context.WithCancel(c.ctx)
+	streamCtx = metadata.AppendToOutgoingContext(streamCtx, "sequence-ack", "true")
+	// 带上 user-id 让服务端在收到第一条消息之前登记会话，只接收消息的客户端也能收到推送
+	if c.config.UserID != "" {
+		streamCtx = metadata.AppendToOutgoingContext(streamCtx, "user-id", c.config.UserID)
 	}
+	if c.config.DefaultRoomID != "" {
+		streamCtx = metadata.AppendToOutgoingContext(streamCtx, "room-id", c.config.DefaultRoomID)
+	}
+	streamCtx = withToken(streamCtx, token)
 
-	stream, err := c.client.StreamMessages(ctx)
+	stream, err := c.client.StreamMessages(streamCtx)
 	if err != nil {
-		return fmt.Errorf("创建消息流失败: %v", err)
+		cancel()
+		return fmt.Errorf("创建消息流失败: %w", err)
 	}
 
 	c.stream = stream
+	c.streamCancel = cancel
 
-	// 启动接收消息的goroutine
-	go c.receiveMessages()
+	// 启动接收消息的goroutine，发件箱在进入 Ready 后补发
+	go c.receiveMessages(stream)
 
 	return nil
 }
@@ -491,58 +787,132 @@ func (c *Client) handleMessages() {
 		case <-c.ctx.Done():
 			return
 		case msg := <-c.messageCh:
-			if err := c.stream.Send(msg); err != nil {
-				log.Printf("发送消息失败: %v", err)
+			if err := c.interceptSend(msg); err != nil {
 				if c.config.OnError != nil {
 					c.config.OnError(err)
 				}
-				// 触发重连
-				select {
-				case c.reconnectCh <- struct{}{}:
-				default:
+				continue
+			}
+			if err := c.sendOnStream(msg); err != nil {
+				c.logger.Error("发送消息失败", "message_id", msg.MessageId, "room_id", msg.RoomId, "error", err)
+				if c.config.OnError != nil {
+					c.config.OnError(err)
 				}
+				c.streamSendFailed(err)
+				continue
 			}
+			c.observeSent(msg)
+		case <-c.outboxCh:
+			c.flushOutbox()
 		}
 	}
 }
 
-// receiveMessages 接收消息
-func (c *Client) receiveMessages() {
+// sendOnStream 在当前消息流上发送消息，与 CloseSend 串行执行；
+// 发送期间流被替换（重连或重新认证）时在新的流上重发一次
+func (c *Client) sendOnStream(msg *imv1.MessageRequest) error {
+	for retried := false; ; retried = true {
+		c.mu.RLock()
+		stream := c.stream
+		c.mu.RUnlock()
+		if stream == nil {
+			return ErrNotConnected
+		}
+
+		c.sendMu.Lock()
+		err := stream.Send(msg)
+		c.sendMu.Unlock()
+		if err == nil || retried || c.isCurrentStream(stream) {
+			return err
+		}
+	}
+}
+
+// streamSendFailed 处理消息流发送失败；io.EOF 表示流已被服务端结束，
+// 由接收协程根据流的最终状态决定重新认证还是断线重连
+func (c *Client) streamSendFailed(err error) {
+	if err != io.EOF {
+		c.triggerReconnect()
+	}
+}
+
+// closeStream 结束当前消息流，调用方需持有 c.mu 写锁；
+// 先取消流的 context 让阻塞中的 Send 立即返回，再与发送串行地调用 CloseSend
+func (c *Client) closeStream() {
+	if c.stream == nil {
+		return
+	}
+	c.streamCancel()
+	c.sendMu.Lock()
+	c.stream.CloseSend()
+	c.sendMu.Unlock()
+}
+
+// receiveMessages 接收消息，只读取创建时绑定的流，流被替换后旧的goroutine自动退出
+func (c *Client) receiveMessages(stream grpc.BidiStreamingClient[imv1.MessageRequest, imv1.MessageResponse]) {
 	for {
 		select {
 		case <-c.ctx.Done():
 			return
 		default:
-			msg, err := c.stream.Recv()
+			msg, err := stream.Recv()
 			if err != nil {
+				if c.ctx.Err() != nil || !c.isCurrentStream(stream) {
+					return
+				}
+				if c.recreateStreamForAuth(stream, err) {
+					return
+				}
+
 				if err == io.EOF {
-					log.Println("服务器关闭了连接")
+					c.logger.Warn("服务器关闭了连接", "address", c.currentAddress())
 				} else {
-					log.Printf("接收消息失败: %v", err)
+					c.logger.Error("接收消息失败", "address", c.currentAddress(), "error", err)
 				}
 
 				if c.config.OnError != nil {
 					c.config.OnError(err)
 				}
 
-				// 触发重连
-				select {
-				case c.reconnectCh <- struct{}{}:
-				default:
-				}
+				c.triggerReconnect()
 				return
 			}
+			c.authRecreatedAt.Store(0)
+			c.observeReceived(msg)
+			if err := c.interceptReceive(msg); err != nil {
+				if c.config.OnError != nil {
+					c.config.OnError(err)
+				}
+				continue
+			}
 			if msg.Type == imv1.MessageType_MESSAGE_TYPE_HEARTBEAT {
 				continue
 			}
-			// 处理接收到的消息
-			if c.config.OnMessage != nil {
-				c.config.OnMessage(msg)
+			// 匹配到等待中的发送消息或携带序号的ACK不再向上分发
+			if msg.Type == imv1.MessageType_MESSAGE_TYPE_ACK && c.handleAckMessage(msg) {
+				continue
+			}
+			if c.config.GapFill && !c.history.mark(msg) {
+				continue
+			}
+			// 处理接收到的消息，带有房间序号的消息按序分发
+			span := c.startReceiveSpan(msg)
+			c.deliver(msg, true)
+			span.End()
+			if msg.AckRequired && c.config.AutoAck {
+				c.sendAutoAck(msg)
 			}
 		}
 	}
 }
 
+// isCurrentStream 判断流是否仍是当前使用的流
+func (c *Client) isCurrentStream(stream grpc.BidiStreamingClient[imv1.MessageRequest, imv1.MessageResponse]) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stream == stream
+}
+
 // handleHeartbeat 处理心跳
 func (c *Client) handleHeartbeat() {
 	ticker := time.NewTicker(c.config.HeartbeatInterval)
@@ -565,7 +935,7 @@ func (c *Client) handleHeartbeat() {
 			select {
 			case c.messageCh <- heartbeat:
 			case <-time.After(5 * time.Second):
-				log.Println("心跳发送超时")
+				c.logger.Warn("心跳发送超时", "room_id", heartbeat.RoomId)
 			}
 		}
 	}
@@ -579,72 +949,188 @@ func (c *Client) handleReconnect() {
 			return
 		case <-c.reconnectCh:
 			c.mu.Lock()
-			if c.connected {
-				c.connected = false
-				if c.config.OnDisconnect != nil {
-					c.config.OnDisconnect(fmt.Errorf("连接断开"))
-				}
+			if c.State() != StateReady {
+				c.mu.Unlock()
+				continue
+			}
+			change, changed := c.setState(StateReconnecting)
+			c.mu.Unlock()
 
-				// 尝试重连
-				for i := 0; i < c.config.MaxRetries; i++ {
-					log.Printf("尝试重连 (%d/%d)...", i+1, c.config.MaxRetries)
-
-					if err := c.reconnect(); err != nil {
-						log.Printf("重连失败: %v", err)
-						time.Sleep(c.config.RetryInterval)
-						continue
-					}
-
-					log.Println("重连成功")
-					c.connected = true
-					if c.config.OnConnect != nil {
-						c.config.OnConnect()
-					}
-					break
-				}
+			c.fireStateCallbacks(change, changed, fmt.Errorf("连接断开"))
+			c.reconnectLoop(false)
+		case <-c.manualReconnectCh:
+			c.mu.Lock()
+			if state := c.State(); state != StateReady && state != StateIdle {
+				c.mu.Unlock()
+				continue
 			}
+			change, changed := c.setState(StateReconnecting)
 			c.mu.Unlock()
+
+			c.fireStateCallbacks(change, changed, fmt.Errorf("手动重连"))
+			c.reconnectLoop(true)
 		}
 	}
 }
 
-// reconnect 重连逻辑
-func (c *Client) reconnect() error {
-	// 关闭旧流连接
-	if c.stream != nil {
-		c.stream.CloseSend()
+// reconnectLoop 按退避策略重连直到成功、放弃或客户端关闭，等待期间不持有锁；
+// 没有可用的退避策略时，自动重连直接放弃，手动重连只尝试一次
+func (c *Client) reconnectLoop(manual bool) {
+	policy := c.backoffPolicy()
+	if policy == nil && !manual {
+		c.giveUp(0, errReconnectDisabled)
+		return
 	}
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		c.logger.Info("尝试重连", "attempt", attempt)
+
+		// 获取令牌和建立新连接可能耗时较长，在加锁之前完成，期间旧连接仍可用于一元调用
+		token, err := c.streamToken(c.ctx)
+		var conn *connection
+		if err == nil && c.managesConnection() {
+			conn, err = c.redial(c.ctx)
+		}
+
+		c.mu.Lock()
+		if c.State() != StateReconnecting {
+			// 重连期间客户端已被关闭
+			c.mu.Unlock()
+			if conn != nil {
+				conn.conn.Close()
+			}
+			return
+		}
+		if err == nil {
+			err = c.reconnect(token, conn)
+		}
+		var change StateChange
+		var changed bool
+		if err == nil {
+			change, changed = c.setState(StateReady)
+		}
+		c.mu.Unlock()
+
+		if err == nil {
+			c.metrics().ReconnectAttempt(ReconnectSuccess)
+			c.logger.Info("重连成功", "attempt", attempt, "address", c.currentAddress())
+			// 丢弃旧连接断开时残留的重连信号
+			select {
+			case <-c.reconnectCh:
+			default:
+			}
+			// 先恢复房间再补发发件箱，避免服务端丢失成员关系时补发的消息被拒绝
+			c.restoreRooms()
+			c.fireStateCallbacks(change, changed, nil)
+			c.fillGaps()
+			return
+		}
 
-	// 如果使用外部 gRPC 客户端（通过 NewClientWithGRPC 创建），跳过连接重建
-	if c.conn == nil {
-		// 直接重新创建流
-		return c.createStream()
+		c.logger.Warn("重连失败", "attempt", attempt, "error", err)
+
+		var next time.Duration
+		ok := false
+		if policy != nil {
+			next, ok = policy.Next(attempt, delay)
+		}
+		if !ok {
+			c.giveUp(attempt, err)
+			return
+		}
+		delay = next
+		c.metrics().ReconnectAttempt(ReconnectFailure)
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(delay):
+		case <-c.manualReconnectCh:
+			// 手动重连时跳过等待，立即重试
+		}
 	}
+}
 
-	// 原有的重连逻辑（用于通过服务发现创建的客户端）
-	if c.conn != nil {
-		c.conn.Close()
+// giveUp 放弃重连，回到 Idle 状态并触发 OnGiveUp
+func (c *Client) giveUp(attempt int, err error) {
+	c.metrics().ReconnectAttempt(ReconnectGiveUp)
+	c.logger.Error("放弃重连", "attempt", attempt, "error", err)
+	c.mu.Lock()
+	if c.State() == StateReconnecting {
+		c.setState(StateIdle)
+	}
+	c.mu.Unlock()
+	if c.config.OnGiveUp != nil {
+		c.config.OnGiveUp(err)
 	}
+}
 
-	// 重新发现服务
-	if err := c.discoverServices(); err != nil {
-		return err
+// Reconnect 手动触发重连，可用于放弃重连后恢复连接，
+// 重连进行中调用会跳过当前的退避等待立即重试；使用外部gRPC客户端时只重新创建消息流
+func (c *Client) Reconnect() error {
+	if c.ctx.Err() != nil {
+		return ErrClosed
 	}
 
-	// 重新建立连接
-	if err := c.establishConnection(); err != nil {
-		return err
+	select {
+	case c.manualReconnectCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// errReconnectDisabled 未配置 Backoff 且 MaxRetries 小于等于0时不自动重连
+var errReconnectDisabled = errors.New("未配置 Backoff 且 MaxRetries 小于等于0，不自动重连")
+
+// backoffPolicy 返回重连退避策略，未配置时按 MaxRetries/RetryInterval 固定间隔重试最多 MaxRetries 次；
+// MaxRetries 小于等于0时返回nil，与 Backoff 出现之前的行为一致，不自动重连
+func (c *Client) backoffPolicy() BackoffPolicy {
+	if c.config.Backoff != nil {
+		return c.config.Backoff
+	}
+	if c.config.MaxRetries <= 0 {
+		return nil
+	}
+	return NewConstantBackoff(c.config.RetryInterval, c.config.MaxRetries)
+}
+
+// triggerReconnect 触发重连
+func (c *Client) triggerReconnect() {
+	select {
+	case c.reconnectCh <- struct{}{}:
+	default:
+	}
+}
+
+// reconnect 重连逻辑，切换到 redial 建立的新连接（使用外部gRPC客户端时为nil）并用 token 创建新的消息流，
+// 调用方需持有 c.mu 写锁
+func (c *Client) reconnect(token *Token, conn *connection) error {
+	// 关闭旧流连接
+	c.closeStream()
+
+	// 使用外部 gRPC 客户端（通过 NewClientWithGRPC 创建）时只重新创建流
+	if conn != nil {
+		oldConn := c.conn
+		c.useConnection(conn)
+		oldConn.Close()
 	}
 
 	// 重新创建流
-	return c.createStream()
+	return c.createStream(token)
+}
+
+// managesConnection 是否使用SDK自管理的连接
+func (c *Client) managesConnection() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn != nil
 }
 
 // watchServices 监听服务变化
 func (c *Client) watchServices() {
 	serviceCh, err := c.config.Discovery.Watch(c.ctx, c.config.ServiceName)
 	if err != nil {
-		log.Printf("监听服务变化失败: %v", err)
+		c.logger.Error("监听服务变化失败", "service", c.config.ServiceName, "error", err)
 		return
 	}
 
@@ -654,14 +1140,41 @@ func (c *Client) watchServices() {
 			return
 		case services := <-serviceCh:
 			if services != nil {
+				c.mu.Lock()
 				c.services = services
+				c.mu.Unlock()
 				c.config.LoadBalancer.Update(services)
-				log.Printf("服务列表更新: %d个服务", len(services))
+				c.logger.Info("服务列表更新", "service", c.config.ServiceName, "count", len(services))
+				c.metrics().DiscoveryUpdate(len(services))
 			}
 		}
 	}
 }
 
+// requestContext 返回请求使用的 ctx：保留调用方的截止时间、取消和 outgoing metadata，
+// 调用方未设置截止时间时使用 RequestTimeout，客户端关闭时同样取消
+func (c *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	var cancel context.CancelFunc
+	if _, ok := ctx.Deadline(); ok {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, c.config.RequestTimeout)
+	}
+
+	stop := context.AfterFunc(c.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// currentAddress 返回当前连接的服务地址，使用外部gRPC客户端时为空
+func (c *Client) currentAddress() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.address
+}
+
 // generateMessageID 生成消息ID
 func (c *Client) generateMessageID() string {
 	return fmt.Sprintf("%s_%d", c.config.UserID, time.Now().UnixNano())
@@ -0,0 +1,363 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const testTimeout = 5 * time.Second
+
+// newTestServer 启动进程内的参考服务端
+func newTestServer(t *testing.T) *imtest.Server {
+	t.Helper()
+
+	srv := imtest.NewServer(nil)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestConfig 返回通过 imtest 服务发现和 Config.Dialer 连接到 srv 的配置，重连间隔缩短到50ms
+func newTestConfig(srv *imtest.Server, userID string) *Config {
+	disc := imtest.NewDiscovery()
+	disc.SetServices("im-service", srv.ServiceInfo())
+
+	config := DefaultConfig()
+	config.Discovery = disc
+	config.Dialer = srv.Dialer()
+	config.UserID = userID
+	config.ConnectTimeout = testTimeout
+	config.RequestTimeout = testTimeout
+	config.Backoff = NewConstantBackoff(50*time.Millisecond, 0)
+	return config
+}
+
+// connectTestClient 创建并连接客户端，测试结束时断开
+func connectTestClient(t *testing.T, config *Config) *Client {
+	t.Helper()
+
+	c, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { c.Disconnect() })
+	return c
+}
+
+// waitState 等待客户端进入指定状态
+func waitState(t *testing.T, c *Client, state State) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err := c.WaitForState(ctx, state); err != nil {
+		t.Fatalf("等待状态 %s 失败，当前状态 %s: %v", state, c.State(), err)
+	}
+}
+
+// waitStreams 等待服务端建立 n 个消息流
+func waitStreams(t *testing.T, srv *imtest.Server, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(testTimeout)
+	for srv.ActiveStreams() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待 %d 个消息流超时，当前 %d 个", n, srv.ActiveStreams())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitSession 等待服务端登记用户的消息流，之后发布的消息都会推送给该用户
+func waitSession(t *testing.T, srv *imtest.Server, userID string) {
+	t.Helper()
+
+	heartbeat := &imv1.MessageResponse{Type: imv1.MessageType_MESSAGE_TYPE_HEARTBEAT}
+	deadline := time.Now().Add(testTimeout)
+	for srv.Push(userID, heartbeat) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("服务端没有登记 %s 的消息流", userID)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// dropStreams 等待服务端建立 n 个消息流后全部断开
+func dropStreams(t *testing.T, srv *imtest.Server, n int) {
+	t.Helper()
+
+	waitStreams(t, srv, n)
+	srv.DropStreams()
+}
+
+// expectStateChanges 依次等待指定的状态变化
+func expectStateChanges(t *testing.T, ch <-chan StateChange, want ...StateChange) {
+	t.Helper()
+
+	for _, w := range want {
+		select {
+		case got := <-ch:
+			if got != w {
+				t.Fatalf("状态变化 = %s -> %s, want %s -> %s", got.From, got.To, w.From, w.To)
+			}
+		case <-time.After(testTimeout):
+			t.Fatalf("等待状态变化 %s -> %s 超时", w.From, w.To)
+		}
+	}
+}
+
+func TestInjectedConnReconnectsStream(t *testing.T) {
+	srv := newTestServer(t)
+	conn, err := srv.Dial()
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	config := newTestConfig(srv, "alice")
+	config.Discovery = nil
+	c, err := NewClientWithConn(conn, config)
+	if err != nil {
+		t.Fatalf("NewClientWithConn: %v", err)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	changes, cancel := c.SubscribeState()
+	defer cancel()
+
+	// 发送与消息流替换并发进行，配合 -race 检查注入连接的重连路径
+	stop := sendContinuously(t, c, "room1")
+	for i := 0; i < 3; i++ {
+		dropStreams(t, srv, 1)
+		expectStateChanges(t, changes,
+			StateChange{From: StateReady, To: StateReconnecting},
+			StateChange{From: StateReconnecting, To: StateReady},
+		)
+	}
+	stop()
+
+	// 重新创建的消息流可以继续收发消息
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	if err := c.SendTextMessage("room1", "hello"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	waitReceived(t, srv, "hello")
+}
+
+func TestReconnectRestoresRoomsAndFlushesOutbox(t *testing.T) {
+	srv := newTestServer(t)
+	config := newTestConfig(srv, "alice")
+	outbox := NewMemoryOutbox()
+	config.Outbox = outbox
+	c := connectTestClient(t, config)
+	disc := config.Discovery.(*imtest.Discovery)
+
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	changes, cancel := c.SubscribeState()
+	defer cancel()
+
+	// 服务发现失败使客户端停留在 Reconnecting，期间服务端丢失成员关系
+	disc.FailDiscover(errors.New("discovery down"))
+	dropStreams(t, srv, 1)
+	expectStateChanges(t, changes, StateChange{From: StateReady, To: StateReconnecting})
+	if err := srv.Rooms().Leave("room1", "alice"); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+
+	// 断开期间发送的消息保留在发件箱中
+	if err := c.SendTextMessage("room1", "offline"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	if outbox.Len() != 1 {
+		t.Fatalf("发件箱消息数 = %d, want 1", outbox.Len())
+	}
+
+	disc.FailDiscover(nil)
+	expectStateChanges(t, changes, StateChange{From: StateReconnecting, To: StateReady})
+
+	waitReceived(t, srv, "offline")
+	waitMember(t, srv, "room1", "alice")
+	deadline := time.Now().Add(testTimeout)
+	for outbox.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("重连后发件箱消息数 = %d, want 0", outbox.Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 重新加入房间后才补发，消息被服务端接受并写入历史
+	var texts []string
+	for _, msg := range serverHistory(t, srv, "room1") {
+		if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+			texts = append(texts, string(msg.Content))
+		}
+	}
+	if len(texts) != 1 || texts[0] != "offline" {
+		t.Fatalf("房间历史 = %v, want [offline]", texts)
+	}
+}
+
+func TestReconnectDisabledWithoutRetries(t *testing.T) {
+	srv := newTestServer(t)
+	config := newTestConfig(srv, "alice")
+	config.Backoff = nil
+	config.MaxRetries = 0
+	config.RetryInterval = 0
+	gaveUp := make(chan error, 1)
+	config.OnGiveUp = func(err error) { gaveUp <- err }
+	c := connectTestClient(t, config)
+	disc := config.Discovery.(*imtest.Discovery)
+
+	// MaxRetries 为0时断开后不再尝试重连，也不会以0间隔空转
+	discovers := disc.DiscoverCalls()
+	dropStreams(t, srv, 1)
+	select {
+	case err := <-gaveUp:
+		if !errors.Is(err, errReconnectDisabled) {
+			t.Fatalf("OnGiveUp(%v), want errReconnectDisabled", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("没有触发 OnGiveUp")
+	}
+	waitState(t, c, StateIdle)
+	if n := disc.DiscoverCalls(); n != discovers {
+		t.Fatalf("DiscoverCalls = %d, want %d（不应尝试重连）", n, discovers)
+	}
+
+	// 手动重连仍然可以恢复连接
+	if err := c.Reconnect(); err != nil {
+		t.Fatalf("Reconnect: %v", err)
+	}
+	waitState(t, c, StateReady)
+}
+
+// sendContinuously 在后台持续发送文本消息，返回停止函数
+func sendContinuously(t *testing.T, c *Client, roomID string) (stop func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			c.SendTextMessage(roomID, fmt.Sprintf("msg-%d", i))
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func TestSendDuringDropStreams(t *testing.T) {
+	srv := newTestServer(t)
+	config := newTestConfig(srv, "alice")
+	config.DefaultRoomID = "room1"
+	c := connectTestClient(t, config)
+
+	changes, unsubscribe := c.SubscribeState()
+	defer unsubscribe()
+
+	stop := sendContinuously(t, c, "room1")
+	for i := 0; i < 5; i++ {
+		dropStreams(t, srv, 1)
+		expectStateChanges(t, changes,
+			StateChange{From: StateReady, To: StateReconnecting},
+			StateChange{From: StateReconnecting, To: StateReady},
+		)
+	}
+	stop()
+
+	// 替换后的消息流仍可正常发送
+	if err := c.SendTextMessage("room1", "after"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	waitReceived(t, srv, "after")
+}
+
+func TestListenOnlyClientReceivesMessages(t *testing.T) {
+	srv := newTestServer(t)
+	received := make(chan *imv1.MessageResponse, 1)
+	config := newTestConfig(srv, "alice")
+	config.OnMessage = func(msg *imv1.MessageResponse) {
+		if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+			received <- msg
+		}
+	}
+	// alice 没有默认房间，也从不在消息流上发送消息
+	alice := connectTestClient(t, config)
+	if _, err := alice.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	bob := connectTestClient(t, newTestConfig(srv, "bob"))
+	if _, err := bob.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+
+	deadline := time.Now().Add(testTimeout)
+	for !slices.Contains(srv.Rooms().OnlineUsers("room1"), "alice") {
+		if time.Now().After(deadline) {
+			t.Fatal("服务端没有登记 alice 的消息流")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := bob.SendTextMessage("room1", "hello"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	select {
+	case msg := <-received:
+		if string(msg.Content) != "hello" || msg.FromUserId != "bob" {
+			t.Fatalf("alice 收到 %s: %q, want bob: hello", msg.FromUserId, msg.Content)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("alice 没有收到消息")
+	}
+}
+
+// waitMember 等待用户重新加入房间
+func waitMember(t *testing.T, srv *imtest.Server, roomID, userID string) {
+	t.Helper()
+
+	deadline := time.Now().Add(testTimeout)
+	for !srv.Rooms().IsMember(roomID, userID) {
+		if time.Now().After(deadline) {
+			t.Fatalf("用户 %s 没有重新加入房间 %s", userID, roomID)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitReceived 等待服务端收到指定文本的消息
+func waitReceived(t *testing.T, srv *imtest.Server, text string) *imv1.MessageRequest {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	msg, err := srv.Received().WaitFor(ctx, imtest.TextRequest(text))
+	if err != nil {
+		t.Fatalf("服务端没有收到消息 %q: %v", text, err)
+	}
+	return msg
+}
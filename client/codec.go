@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const (
+	// MetadataContentEncoding 标识消息 Content 编码方式的metadata key
+	MetadataContentEncoding = "content-encoding"
+
+	// ContentEncodingRaw 原始字节：文本消息为UTF-8文本，富文本消息为原始内容（raw_content）
+	ContentEncodingRaw = "raw"
+	// ContentEncodingProtobuf protobuf编码的 TextContent/AudioContent/RichTextContent/SystemContent
+	ContentEncodingProtobuf = "protobuf"
+
+	// RichTextMarkdown 富文本内容类型：markdown
+	RichTextMarkdown = "markdown"
+	// RichTextHTML 富文本内容类型：html
+	RichTextHTML = "html"
+)
+
+// defaultContentEncoding 未声明编码时的默认编码：文本为原始字节（兼容旧版本），其他类型为protobuf
+func defaultContentEncoding(msgType imv1.MessageType) string {
+	if msgType == imv1.MessageType_MESSAGE_TYPE_TEXT {
+		return ContentEncodingRaw
+	}
+	return ContentEncodingProtobuf
+}
+
+// EncodeContent 按指定编码将内容编码为消息的 Content 字节，encoding 为空时使用该类型的默认编码
+func EncodeContent(msgType imv1.MessageType, content proto.Message, encoding string) ([]byte, error) {
+	if encoding == "" {
+		encoding = defaultContentEncoding(msgType)
+	}
+
+	switch encoding {
+	case ContentEncodingProtobuf:
+		return proto.Marshal(content)
+	case ContentEncodingRaw:
+		switch c := content.(type) {
+		case *imv1.TextContent:
+			return []byte(c.Text), nil
+		case *imv1.RichTextContent:
+			return []byte(c.RawContent), nil
+		default:
+			return nil, fmt.Errorf("消息类型 %s 不支持 %s 编码", msgType, encoding)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的内容编码: %s", encoding)
+	}
+}
+
+// DecodeContent 按消息类型和编码解码 Content，encoding 为空时使用该类型的默认编码；
+// 不支持的消息类型返回nil
+func DecodeContent(msgType imv1.MessageType, data []byte, encoding string) (proto.Message, error) {
+	if encoding == "" {
+		encoding = defaultContentEncoding(msgType)
+	}
+
+	var content proto.Message
+	switch msgType {
+	case imv1.MessageType_MESSAGE_TYPE_TEXT:
+		content = &imv1.TextContent{}
+	case imv1.MessageType_MESSAGE_TYPE_AUDIO:
+		content = &imv1.AudioContent{}
+	case imv1.MessageType_MESSAGE_TYPE_RICH_TEXT:
+		content = &imv1.RichTextContent{}
+	case imv1.MessageType_MESSAGE_TYPE_SYSTEM:
+		content = &imv1.SystemContent{}
+	case imv1.MessageType_MESSAGE_TYPE_ATTACHMENT:
+		content = &imv1.AttachmentContent{}
+	default:
+		return nil, nil
+	}
+
+	switch encoding {
+	case ContentEncodingProtobuf:
+		if err := proto.Unmarshal(data, content); err != nil {
+			return nil, err
+		}
+		return content, nil
+	case ContentEncodingRaw:
+		switch c := content.(type) {
+		case *imv1.TextContent:
+			c.Text = string(data)
+		case *imv1.RichTextContent:
+			c.ContentType = RichTextMarkdown
+			c.RawContent = string(data)
+		default:
+			return nil, fmt.Errorf("消息类型 %s 不支持 %s 编码", msgType, encoding)
+		}
+		return content, nil
+	default:
+		return nil, fmt.Errorf("不支持的内容编码: %s", encoding)
+	}
+}
+
+// SendContent 编码并发送内容消息，编码方式由 Config.ContentEncoding 决定并写入 content-encoding metadata
+func (c *Client) SendContent(roomID string, msgType imv1.MessageType, content proto.Message) error {
+	return c.SendContentContext(context.Background(), roomID, msgType, content)
+}
+
+// SendContentContext 编码并发送内容消息
+func (c *Client) SendContentContext(ctx context.Context, roomID string, msgType imv1.MessageType, content proto.Message) error {
+	msg, err := c.newContentMessage(roomID, msgType, content)
+	if err != nil {
+		return err
+	}
+	return c.SendMessageContext(ctx, msg)
+}
+
+// SendRichText 发送markdown富文本消息
+func (c *Client) SendRichText(roomID, markdown string) error {
+	return c.SendRichTextContext(context.Background(), roomID, markdown)
+}
+
+// SendRichTextContext 发送markdown富文本消息
+func (c *Client) SendRichTextContext(ctx context.Context, roomID, markdown string) error {
+	return c.SendContentContext(ctx, roomID, imv1.MessageType_MESSAGE_TYPE_RICH_TEXT, &imv1.RichTextContent{
+		ContentType: RichTextMarkdown,
+		RawContent:  markdown,
+	})
+}
+
+// SendAudioMessage 发送已上传音频的消息，upload 为 UploadAudio 的返回结果
+func (c *Client) SendAudioMessage(roomID string, upload *imv1.UploadAudioResponse, format string, duration float64, size int64) error {
+	return c.SendAudioMessageContext(context.Background(), roomID, upload, format, duration, size)
+}
+
+// SendAudioMessageContext 发送已上传音频的消息
+func (c *Client) SendAudioMessageContext(ctx context.Context, roomID string, upload *imv1.UploadAudioResponse, format string, duration float64, size int64) error {
+	if upload == nil || upload.AudioId == "" {
+		return fmt.Errorf("音频上传结果不能为空")
+	}
+
+	return c.SendContentContext(ctx, roomID, imv1.MessageType_MESSAGE_TYPE_AUDIO, &imv1.AudioContent{
+		AudioId:  upload.AudioId,
+		AudioUrl: upload.AudioUrl,
+		Duration: duration,
+		Format:   format,
+		Size:     size,
+	})
+}
+
+// SendSystemMessage 发送系统消息
+func (c *Client) SendSystemMessage(roomID, eventType string, eventData map[string]string) error {
+	return c.SendSystemMessageContext(context.Background(), roomID, eventType, eventData)
+}
+
+// SendSystemMessageContext 发送系统消息
+func (c *Client) SendSystemMessageContext(ctx context.Context, roomID, eventType string, eventData map[string]string) error {
+	return c.SendContentContext(ctx, roomID, imv1.MessageType_MESSAGE_TYPE_SYSTEM, &imv1.SystemContent{
+		EventType: eventType,
+		EventData: eventData,
+	})
+}
+
+// newContentMessage 构造内容消息
+func (c *Client) newContentMessage(roomID string, msgType imv1.MessageType, content proto.Message) (*imv1.MessageRequest, error) {
+	encoding := c.contentEncoding(msgType)
+
+	data, err := EncodeContent(msgType, content, encoding)
+	if err != nil {
+		return nil, fmt.Errorf("编码消息内容失败: %w", err)
+	}
+
+	return &imv1.MessageRequest{
+		MessageId: c.generateMessageID(),
+		UserId:    c.config.UserID,
+		RoomId:    roomID,
+		Type:      msgType,
+		Content:   data,
+		Metadata: map[string]string{
+			MetadataContentEncoding: encoding,
+		},
+		Timestamp: timestamppb.New(time.Now()),
+	}, nil
+}
+
+// contentEncoding 发送时使用的编码，只支持protobuf的类型不受 ContentEncoding 配置影响
+func (c *Client) contentEncoding(msgType imv1.MessageType) string {
+	switch msgType {
+	case imv1.MessageType_MESSAGE_TYPE_TEXT, imv1.MessageType_MESSAGE_TYPE_RICH_TEXT:
+		if c.config.ContentEncoding != "" {
+			return c.config.ContentEncoding
+		}
+	}
+	return defaultContentEncoding(msgType)
+}
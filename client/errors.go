@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+var (
+	// ErrNotConnected 客户端未连接
+	ErrNotConnected = errors.New("客户端未连接")
+	// ErrClosed 客户端已关闭
+	ErrClosed = errors.New("客户端已关闭")
+	// ErrSendTimeout 发送队列已满，在 RequestTimeout 内未能写入
+	ErrSendTimeout = errors.New("发送消息超时")
+	// ErrAckTimeout 在超时时间内未收到消息的ACK
+	ErrAckTimeout = errors.New("等待ACK超时")
+	// ErrMessageRejected 服务端ACK表示消息处理失败
+	ErrMessageRejected = errors.New("消息被服务端拒绝")
+	// ErrTranscriptFailed 服务端转写音频失败
+	ErrTranscriptFailed = errors.New("音频转写失败")
+	// ErrVoiceStreamClosed 语音流已关闭
+	ErrVoiceStreamClosed = errors.New("语音流已关闭")
+	// ErrMessagesLost 房间消息序号不连续，缺失的消息已无法从服务端获取
+	ErrMessagesLost = errors.New("房间消息已丢失")
+	// ErrOutboxCorrupted 发件箱文件中有无效的记录，该记录及之后的内容已被丢弃
+	ErrOutboxCorrupted = errors.New("发件箱文件已损坏")
+)
+
+// StatusError 服务端返回的错误状态，来自响应中非零的 ResponseStatus 或 gRPC 状态码
+//
+// Code 与 gRPC 状态码含义相同，可以通过 status.Code(err) 读取。
+type StatusError struct {
+	Code    codes.Code
+	Message string
+	Details map[string]string
+
+	cause error // 原始的gRPC错误，来自 ResponseStatus 时为nil
+}
+
+// Error 返回错误描述
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("服务端返回错误: %s", e.Code)
+	}
+	return fmt.Sprintf("服务端返回错误: %s: %s", e.Code, e.Message)
+}
+
+// Unwrap 返回原始的gRPC错误
+func (e *StatusError) Unwrap() error {
+	return e.cause
+}
+
+// GRPCStatus 返回对应的gRPC状态，使 status.Code/status.FromError 可以识别该错误
+func (e *StatusError) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
+}
+
+// Retryable 判断该错误是否是临时性的、可以重试
+func (e *StatusError) Retryable() bool {
+	switch e.Code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryable 判断错误是否是临时性的、重试可能成功
+//
+// 未连接、发送超时、ACK超时以及 Unavailable/DeadlineExceeded/ResourceExhausted/Aborted
+// 状态的错误可以重试；参数错误、权限错误和客户端已关闭等错误不可重试。
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch {
+	case errors.Is(err, ErrClosed), errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, ErrNotConnected), errors.Is(err, ErrSendTimeout),
+		errors.Is(err, ErrAckTimeout), errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable()
+	}
+	if s, ok := status.FromError(err); ok {
+		return (&StatusError{Code: s.Code()}).Retryable()
+	}
+	return false
+}
+
+// statusError 把非零的 ResponseStatus 转换为 *StatusError，成功时返回nil
+func statusError(s *imv1.ResponseStatus) error {
+	if s == nil || s.Code == 0 {
+		return nil
+	}
+	return &StatusError{
+		Code:    codes.Code(s.Code),
+		Message: s.Message,
+		Details: s.Details,
+	}
+}
+
+// responseError 把gRPC错误转换为 *StatusError，调用成功时检查响应中的 ResponseStatus
+func responseError(resp any, err error) error {
+	if err != nil {
+		if s, ok := status.FromError(err); ok && s.Code() != codes.OK {
+			return &StatusError{
+				Code:    s.Code(),
+				Message: s.Message(),
+				cause:   err,
+			}
+		}
+		return err
+	}
+
+	if r, ok := resp.(interface{ GetStatus() *imv1.ResponseStatus }); ok {
+		return statusError(r.GetStatus())
+	}
+	return nil
+}
+
+// notReadyError 返回客户端不可用时的错误，已关闭时为 ErrClosed，否则为 ErrNotConnected
+func (c *Client) notReadyError() error {
+	if c.State() == StateClosed {
+		return ErrClosed
+	}
+	return ErrNotConnected
+}
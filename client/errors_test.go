@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+func TestResponseError(t *testing.T) {
+	grpcErr := status.Error(codes.PermissionDenied, "不是房间成员")
+
+	tests := []struct {
+		name     string
+		resp     any
+		err      error
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{"成功", &imv1.JoinRoomResponse{}, nil, codes.OK, ""},
+		{"状态码为0", &imv1.JoinRoomResponse{Status: &imv1.ResponseStatus{Message: "ok"}}, nil, codes.OK, ""},
+		{"响应中的错误状态", &imv1.GetRoomInfoResponse{Status: &imv1.ResponseStatus{
+			Code:    int32(codes.NotFound),
+			Message: "房间不存在",
+			Details: map[string]string{"room_id": "room1"},
+		}}, nil, codes.NotFound, "房间不存在"},
+		{"gRPC错误", nil, grpcErr, codes.PermissionDenied, "不是房间成员"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := responseError(tt.resp, tt.err)
+			if tt.wantCode == codes.OK {
+				if err != nil {
+					t.Fatalf("responseError = %v, want nil", err)
+				}
+				return
+			}
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("responseError = %v, want *StatusError", err)
+			}
+			if statusErr.Code != tt.wantCode || statusErr.Message != tt.wantMsg {
+				t.Fatalf("StatusError = %v %q, want %v %q", statusErr.Code, statusErr.Message, tt.wantCode, tt.wantMsg)
+			}
+			// 转换后的错误仍然可以用 grpc status 包读取状态码
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("status.Code = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+
+	err := responseError(nil, grpcErr)
+	if !errors.Is(err, grpcErr) {
+		t.Fatal("转换后的错误应包装原始的gRPC错误")
+	}
+	err = responseError(&imv1.GetRoomInfoResponse{Status: &imv1.ResponseStatus{
+		Code:    int32(codes.NotFound),
+		Details: map[string]string{"room_id": "room1"},
+	}}, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Details["room_id"] != "room1" {
+		t.Fatalf("responseError = %v, want 带有 room_id 详情的 *StatusError", err)
+	}
+	if errors.Unwrap(err) != nil {
+		t.Fatal("来自 ResponseStatus 的错误没有原始的gRPC错误")
+	}
+	if err := responseError(nil, io.EOF); err != io.EOF {
+		t.Fatal("非gRPC错误应原样返回")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"未连接", ErrNotConnected, true},
+		{"发送超时", fmt.Errorf("发送失败: %w", ErrSendTimeout), true},
+		{"ACK超时", ErrAckTimeout, true},
+		{"context 超时", context.DeadlineExceeded, true},
+		{"客户端已关闭", ErrClosed, false},
+		{"context 取消", context.Canceled, false},
+		{"消息被拒绝", ErrMessageRejected, false},
+		{"Unavailable", &StatusError{Code: codes.Unavailable}, true},
+		{"ResourceExhausted", &StatusError{Code: codes.ResourceExhausted}, true},
+		{"Aborted", fmt.Errorf("加入房间失败: %w", &StatusError{Code: codes.Aborted}), true},
+		{"InvalidArgument", &StatusError{Code: codes.InvalidArgument}, false},
+		{"PermissionDenied", &StatusError{Code: codes.PermissionDenied}, false},
+		{"原始gRPC错误", status.Error(codes.DeadlineExceeded, "timeout"), true},
+		{"原始gRPC错误不可重试", status.Error(codes.NotFound, "not found"), false},
+		{"其他错误", io.ErrUnexpectedEOF, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Fatalf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// Handler 原始消息处理器
+type Handler func(msg *imv1.MessageResponse)
+
+// Middleware 消息处理中间件，可在分发前后执行逻辑或拦截消息
+type Middleware func(next Handler) Handler
+
+// TextHandler 文本消息处理器
+type TextHandler func(msg *imv1.MessageResponse, content *imv1.TextContent)
+
+// AudioHandler 音频消息处理器
+type AudioHandler func(msg *imv1.MessageResponse, content *imv1.AudioContent)
+
+// RichTextHandler 富文本消息处理器
+type RichTextHandler func(msg *imv1.MessageResponse, content *imv1.RichTextContent)
+
+// SystemHandler 系统消息处理器
+type SystemHandler func(msg *imv1.MessageResponse, content *imv1.SystemContent)
+
+// AttachmentHandler 附件消息处理器
+type AttachmentHandler func(msg *imv1.MessageResponse, content *imv1.AttachmentContent)
+
+// Router 按消息类型分发的处理器注册表，同一类型重复注册会覆盖之前的处理器
+type Router struct {
+	text       TextHandler
+	audio      AudioHandler
+	richText   RichTextHandler
+	system     SystemHandler
+	attachment AttachmentHandler
+	fallback   Handler
+	mu         sync.RWMutex
+}
+
+// HandleText 注册文本消息处理器
+func (r *Router) HandleText(h TextHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.text = h
+}
+
+// HandleAudio 注册音频消息处理器
+func (r *Router) HandleAudio(h AudioHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.audio = h
+}
+
+// HandleRichText 注册富文本消息处理器
+func (r *Router) HandleRichText(h RichTextHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.richText = h
+}
+
+// HandleSystem 注册系统消息处理器
+func (r *Router) HandleSystem(h SystemHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.system = h
+}
+
+// HandleAttachment 注册附件消息处理器
+func (r *Router) HandleAttachment(h AttachmentHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attachment = h
+}
+
+// HandleDefault 注册其他类型消息（如加入/离开房间通知）的处理器
+func (r *Router) HandleDefault(h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = h
+}
+
+// dispatch 将已解码的消息分发给对应类型的处理器。
+// 处理器在释放锁之后调用，处理器内可以重新注册同一路由的处理器
+func (r *Router) dispatch(msg *imv1.MessageResponse, content proto.Message) {
+	if handler := r.handler(content); handler != nil {
+		handler(msg)
+	}
+}
+
+// handler 返回内容类型对应的处理器，没有注册时返回 nil
+func (r *Router) handler(content proto.Message) Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	switch c := content.(type) {
+	case *imv1.TextContent:
+		if h := r.text; h != nil {
+			return func(msg *imv1.MessageResponse) { h(msg, c) }
+		}
+	case *imv1.AudioContent:
+		if h := r.audio; h != nil {
+			return func(msg *imv1.MessageResponse) { h(msg, c) }
+		}
+	case *imv1.RichTextContent:
+		if h := r.richText; h != nil {
+			return func(msg *imv1.MessageResponse) { h(msg, c) }
+		}
+	case *imv1.SystemContent:
+		if h := r.system; h != nil {
+			return func(msg *imv1.MessageResponse) { h(msg, c) }
+		}
+	case *imv1.AttachmentContent:
+		if h := r.attachment; h != nil {
+			return func(msg *imv1.MessageResponse) { h(msg, c) }
+		}
+	default:
+		return r.fallback
+	}
+	return nil
+}
+
+// handlerRegistry 客户端的处理器注册表：全局路由、房间路由和中间件
+type handlerRegistry struct {
+	global      Router
+	rooms       map[string]*Router
+	middlewares []Middleware
+	mu          sync.RWMutex
+}
+
+// newHandlerRegistry 创建处理器注册表
+func newHandlerRegistry() *handlerRegistry {
+	return &handlerRegistry{
+		rooms: make(map[string]*Router),
+	}
+}
+
+// HandleText 注册全局文本消息处理器
+func (c *Client) HandleText(h TextHandler) {
+	c.handlers.global.HandleText(h)
+}
+
+// HandleAudio 注册全局音频消息处理器
+func (c *Client) HandleAudio(h AudioHandler) {
+	c.handlers.global.HandleAudio(h)
+}
+
+// HandleRichText 注册全局富文本消息处理器
+func (c *Client) HandleRichText(h RichTextHandler) {
+	c.handlers.global.HandleRichText(h)
+}
+
+// HandleSystem 注册全局系统消息处理器
+func (c *Client) HandleSystem(h SystemHandler) {
+	c.handlers.global.HandleSystem(h)
+}
+
+// HandleAttachment 注册全局附件消息处理器
+func (c *Client) HandleAttachment(h AttachmentHandler) {
+	c.handlers.global.HandleAttachment(h)
+}
+
+// HandleDefault 注册全局其他类型消息的处理器
+func (c *Client) HandleDefault(h Handler) {
+	c.handlers.global.HandleDefault(h)
+}
+
+// Room 返回指定房间的处理器注册表，房间处理器先于全局处理器执行
+func (c *Client) Room(roomID string) *Router {
+	c.handlers.mu.Lock()
+	defer c.handlers.mu.Unlock()
+
+	router, exists := c.handlers.rooms[roomID]
+	if !exists {
+		router = &Router{}
+		c.handlers.rooms[roomID] = router
+	}
+	return router
+}
+
+// Use 添加消息处理中间件，按添加顺序由外到内执行
+func (c *Client) Use(middlewares ...Middleware) {
+	c.handlers.mu.Lock()
+	defer c.handlers.mu.Unlock()
+	c.handlers.middlewares = append(c.handlers.middlewares, middlewares...)
+}
+
+// dispatch 经过中间件链后分发消息
+func (c *Client) dispatch(msg *imv1.MessageResponse) {
+	c.handlers.mu.RLock()
+	handler := Handler(c.route)
+	for i := len(c.handlers.middlewares) - 1; i >= 0; i-- {
+		handler = c.handlers.middlewares[i](handler)
+	}
+	c.handlers.mu.RUnlock()
+
+	handler(msg)
+}
+
+// route 调用 OnMessage 回调，并解码内容分发给房间处理器和全局处理器
+func (c *Client) route(msg *imv1.MessageResponse) {
+	if c.config.OnMessage != nil {
+		c.config.OnMessage(msg)
+	}
+
+	content, err := DecodeContent(msg.Type, msg.Content, msg.Metadata[MetadataContentEncoding])
+	if err != nil {
+		if c.config.OnError != nil {
+			c.config.OnError(fmt.Errorf("解析消息 %s 内容失败: %w", msg.MessageId, err))
+		}
+		return
+	}
+
+	c.handlers.mu.RLock()
+	room := c.handlers.rooms[msg.RoomId]
+	c.handlers.mu.RUnlock()
+
+	if room != nil {
+		room.dispatch(msg, content)
+	}
+	c.handlers.global.dispatch(msg, content)
+}
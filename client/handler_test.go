@@ -0,0 +1,54 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+func TestRouterHandlerCanReregister(t *testing.T) {
+	var r Router
+	var calls []string
+	r.HandleText(func(msg *imv1.MessageResponse, content *imv1.TextContent) {
+		calls = append(calls, "first:"+content.Text)
+		r.HandleText(func(msg *imv1.MessageResponse, content *imv1.TextContent) {
+			calls = append(calls, "second:"+content.Text)
+		})
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.dispatch(&imv1.MessageResponse{}, &imv1.TextContent{Text: "a"})
+		r.dispatch(&imv1.MessageResponse{}, &imv1.TextContent{Text: "b"})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("处理器内重新注册处理器时死锁")
+	}
+	if len(calls) != 2 || calls[0] != "first:a" || calls[1] != "second:b" {
+		t.Fatalf("calls = %v", calls)
+	}
+}
+
+func TestRouterDispatchByType(t *testing.T) {
+	var r Router
+	var got []string
+	r.HandleSystem(func(msg *imv1.MessageResponse, content *imv1.SystemContent) {
+		got = append(got, "system")
+	})
+	r.HandleDefault(func(msg *imv1.MessageResponse) {
+		got = append(got, "default")
+	})
+
+	r.dispatch(&imv1.MessageResponse{}, &imv1.TextContent{})
+	r.dispatch(&imv1.MessageResponse{}, &imv1.SystemContent{})
+	r.dispatch(&imv1.MessageResponse{}, nil)
+
+	if len(got) != 2 || got[0] != "system" || got[1] != "default" {
+		t.Fatalf("got = %v, want [system default]", got)
+	}
+}
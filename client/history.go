@@ -0,0 +1,375 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const (
+	defaultHistoryPageSize = 50
+	defaultGapFillLimit    = 500
+	gapFillPageSize        = 100
+	historySeenSize        = 256
+)
+
+// HistoryOptions FetchHistory 的翻页参数
+type HistoryOptions struct {
+	// Direction 翻页方向，为 UNSPECIFIED 时从新到旧
+	Direction imv1.HistoryDirection
+	// StartTime 从新到旧时返回此时间之前的消息，从旧到新时返回此时间及之后的消息；
+	// 为零值时从最新（或最早）的消息开始
+	StartTime time.Time
+	// Cursor 从 HistoryIterator.Cursor 返回的位置继续翻页，不为空时忽略 StartTime
+	Cursor string
+	// Types 只返回这些类型的消息，为空时返回所有类型
+	Types []imv1.MessageType
+	// PageSize 每次请求的消息数，为0时使用50
+	PageSize int
+	// Limit 最多返回的消息总数，为0时不限制
+	Limit int
+}
+
+// HistoryIterator 房间历史消息的迭代器，按需逐页请求
+//
+//	it := client.FetchHistory(ctx, roomID, client.HistoryOptions{})
+//	for it.Next() {
+//		msg := it.Message()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type HistoryIterator struct {
+	client *Client
+	ctx    context.Context
+	roomID string
+	opts   HistoryOptions
+
+	page    []*imv1.MessageResponse
+	pos     int
+	msg     *imv1.MessageResponse
+	count   int
+	cursor  string
+	fetched bool
+	hasMore bool
+	err     error
+}
+
+// FetchHistory 返回房间历史消息的迭代器，第一次调用 Next 时才发起请求；
+// ctx 约束整个迭代过程，每一页请求另外受 RequestTimeout 限制
+func (c *Client) FetchHistory(ctx context.Context, roomID string, opts HistoryOptions) *HistoryIterator {
+	return &HistoryIterator{
+		client: c,
+		ctx:    ctx,
+		roomID: roomID,
+		opts:   opts,
+		cursor: opts.Cursor,
+	}
+}
+
+// Next 前进到下一条消息，没有更多消息或请求失败时返回 false
+func (it *HistoryIterator) Next() bool {
+	if it.err != nil || (it.opts.Limit > 0 && it.count >= it.opts.Limit) {
+		return false
+	}
+
+	for it.pos >= len(it.page) {
+		if it.fetched && !it.hasMore {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.page) == 0 {
+			return false
+		}
+	}
+
+	it.msg = it.page[it.pos]
+	it.pos++
+	it.count++
+	return true
+}
+
+// Message 返回当前消息
+func (it *HistoryIterator) Message() *imv1.MessageResponse {
+	return it.msg
+}
+
+// Err 返回迭代中止的原因，正常结束时为nil
+func (it *HistoryIterator) Err() error {
+	return it.err
+}
+
+// Cursor 返回最后一次请求的页末位置，可以作为 HistoryOptions.Cursor 在该页之后继续翻页
+func (it *HistoryIterator) Cursor() string {
+	return it.cursor
+}
+
+// fetch 请求下一页
+func (it *HistoryIterator) fetch() error {
+	pageSize := it.opts.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	if it.opts.Limit > 0 {
+		pageSize = min(pageSize, it.opts.Limit-it.count)
+	}
+
+	req := &imv1.GetHistoryRequest{
+		RoomId:    it.roomID,
+		Cursor:    it.cursor,
+		Direction: it.opts.Direction,
+		Limit:     int32(pageSize),
+		Types:     it.opts.Types,
+	}
+	if it.cursor == "" && !it.opts.StartTime.IsZero() {
+		req.StartTime = timestamppb.New(it.opts.StartTime)
+	}
+
+	resp, err := it.client.getHistory(it.ctx, req)
+	if err != nil {
+		return fmt.Errorf("获取房间 %s 历史消息失败: %w", it.roomID, err)
+	}
+
+	it.page = resp.Messages
+	it.pos = 0
+	it.fetched = true
+	it.hasMore = resp.HasMore
+	if resp.NextCursor != "" {
+		it.cursor = resp.NextCursor
+	}
+	return nil
+}
+
+// getHistory 请求一页历史消息
+func (c *Client) getHistory(ctx context.Context, req *imv1.GetHistoryRequest) (*imv1.GetHistoryResponse, error) {
+	grpcClient, err := c.readyClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	req.UserId = c.config.UserID
+	ctx, finish := c.startRPC(ctx, "GetHistory", req.RoomId)
+	resp, err := invokeWithCredentials(c, ctx, func(ctx context.Context) (*imv1.GetHistoryResponse, error) {
+		return grpcClient.GetHistory(ctx, req)
+	})
+	finish(err)
+	return resp, err
+}
+
+// historyTracker 记录每个房间最后收到消息的时间和最近的消息ID，用于重连后补齐消息并去重
+type historyTracker struct {
+	rooms map[string]*roomHistory
+	mu    sync.Mutex
+}
+
+// roomHistory 一个房间的接收记录
+type roomHistory struct {
+	last  time.Time
+	seen  map[string]struct{}
+	order []string // 按接收顺序排列的消息ID，超过 historySeenSize 时淘汰最早的
+}
+
+// newHistoryTracker 创建接收记录
+func newHistoryTracker() *historyTracker {
+	return &historyTracker{
+		rooms: make(map[string]*roomHistory),
+	}
+}
+
+// join 记录加入房间的时间，已有接收记录时不覆盖
+func (t *historyTracker) join(roomID string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, exists := t.rooms[roomID]; !exists {
+		t.rooms[roomID] = &roomHistory{
+			last: at,
+			seen: make(map[string]struct{}),
+		}
+	}
+}
+
+// remove 移除房间的接收记录
+func (t *historyTracker) remove(roomID string) {
+	t.mu.Lock()
+	delete(t.rooms, roomID)
+	t.mu.Unlock()
+}
+
+// mark 记录收到的房间消息，消息ID最近已经收到过时返回 false
+func (t *historyTracker) mark(msg *imv1.MessageResponse) bool {
+	if msg.RoomId == "" {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h, exists := t.rooms[msg.RoomId]
+	if !exists {
+		h = &roomHistory{seen: make(map[string]struct{})}
+		t.rooms[msg.RoomId] = h
+	}
+
+	if msg.Timestamp != nil {
+		if at := msg.Timestamp.AsTime(); at.After(h.last) {
+			h.last = at
+		}
+	}
+
+	if msg.MessageId == "" {
+		return true
+	}
+	if _, dup := h.seen[msg.MessageId]; dup {
+		return false
+	}
+	h.seen[msg.MessageId] = struct{}{}
+	h.order = append(h.order, msg.MessageId)
+	if len(h.order) > historySeenSize {
+		delete(h.seen, h.order[0])
+		h.order = h.order[1:]
+	}
+	return true
+}
+
+// snapshot 返回每个房间最后收到消息的时间
+func (t *historyTracker) snapshot() map[string]time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rooms := make(map[string]time.Time, len(t.rooms))
+	for roomID, h := range t.rooms {
+		if !h.last.IsZero() {
+			rooms[roomID] = h.last
+		}
+	}
+	return rooms
+}
+
+// fillGaps 重连成功后拉取每个房间断线期间错过的消息并分发：
+// 有消息序号的房间从最后分发的序号之后拉取，其他房间从最后收到消息的时间开始拉取
+func (c *Client) fillGaps() {
+	if !c.config.GapFill {
+		return
+	}
+
+	sequences := c.sequences.snapshot()
+	for roomID, last := range c.history.snapshot() {
+		if _, sequenced := sequences[roomID]; !sequenced {
+			if !c.reportGapFill(roomID, c.fillGap(roomID, last)) {
+				return
+			}
+		}
+	}
+	for roomID, last := range sequences {
+		if last > 0 && !c.reportGapFill(roomID, c.fillSequenceGap(roomID, last)) {
+			return
+		}
+	}
+}
+
+// reportGapFill 报告补齐失败，返回是否继续补齐其他房间
+func (c *Client) reportGapFill(roomID string, err error) bool {
+	if err == nil {
+		return true
+	}
+	if c.ctx.Err() != nil {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.Code == codes.Unimplemented {
+		c.logger.Debug("服务端不支持历史消息，跳过消息补齐")
+		return false
+	}
+	c.logger.Warn("补齐断线期间的消息失败", "room_id", roomID, "error", err)
+	if c.config.OnError != nil {
+		c.config.OnError(err)
+	}
+	return true
+}
+
+// fillGap 按时间补齐单个房间的消息，跳过已经收到的和自己发送的消息
+func (c *Client) fillGap(roomID string, last time.Time) error {
+	limit := c.gapFillLimit()
+	it := c.FetchHistory(c.ctx, roomID, HistoryOptions{
+		Direction: imv1.HistoryDirection_HISTORY_DIRECTION_FORWARD,
+		StartTime: last,
+		PageSize:  gapFillPageSize,
+		Limit:     limit,
+	})
+
+	filled := 0
+	for it.Next() {
+		msg := it.Message()
+		if msg.FromUserId == c.config.UserID || !c.history.mark(msg) {
+			continue
+		}
+		span := c.startReceiveSpan(msg)
+		c.deliver(msg, true)
+		span.End()
+		filled++
+	}
+	return c.finishGapFill(it, roomID, filled, limit)
+}
+
+// fillSequenceGap 从序号 last 之后补齐单个房间的消息，自己发送的消息只推进序号
+func (c *Client) fillSequenceGap(roomID string, last uint64) error {
+	limit := c.gapFillLimit()
+	it := c.FetchHistory(c.ctx, roomID, HistoryOptions{
+		Direction: imv1.HistoryDirection_HISTORY_DIRECTION_FORWARD,
+		Cursor:    strconv.FormatUint(last, 10),
+		PageSize:  gapFillPageSize,
+		Limit:     limit,
+	})
+
+	filled := 0
+	for it.Next() {
+		msg := it.Message()
+		own := msg.FromUserId == c.config.UserID
+		c.history.mark(msg)
+		span := c.startReceiveSpan(msg)
+		c.deliver(msg, !own)
+		span.End()
+		if !own {
+			filled++
+		}
+	}
+	return c.finishGapFill(it, roomID, filled, limit)
+}
+
+// finishGapFill 记录补齐的结果
+func (c *Client) finishGapFill(it *HistoryIterator, roomID string, filled, limit int) error {
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if filled > 0 {
+		c.logger.Info("补齐断线期间的消息", "room_id", roomID, "count", filled)
+	}
+	if it.count >= limit && it.hasMore {
+		c.logger.Warn("补齐的消息达到上限，剩余的消息需要通过 FetchHistory 获取", "room_id", roomID, "limit", limit)
+	}
+	return nil
+}
+
+// gapFillLimit 每个房间最多补齐的消息数
+func (c *Client) gapFillLimit() int {
+	if c.config.GapFillLimit > 0 {
+		return c.config.GapFillLimit
+	}
+	return defaultGapFillLimit
+}
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// publishTexts 以 bob 的身份在房间发布文本消息
+func publishTexts(t *testing.T, srv *imtest.Server, roomID string, texts ...string) {
+	t.Helper()
+
+	for _, text := range texts {
+		_, err := srv.Rooms().Publish(&imv1.MessageResponse{
+			MessageId:  text,
+			Type:       imv1.MessageType_MESSAGE_TYPE_TEXT,
+			RoomId:     roomID,
+			FromUserId: "bob",
+			Content:    []byte(text),
+		}, 0)
+		if err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+}
+
+// collectHistory 迭代全部历史消息，返回消息内容
+func collectHistory(t *testing.T, it *HistoryIterator) []string {
+	t.Helper()
+
+	var texts []string
+	for it.Next() {
+		texts = append(texts, string(it.Message().Content))
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("FetchHistory: %v", err)
+	}
+	return texts
+}
+
+func TestFetchHistory(t *testing.T) {
+	srv := newTestServer(t)
+	interceptor, calls := countHistoryCalls(0)
+	config := newTestConfig(srv, "alice")
+	config.UnaryInterceptors = []grpc.UnaryClientInterceptor{interceptor}
+	c := connectTestClient(t, config)
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	var texts []string
+	for i := 1; i <= 7; i++ {
+		texts = append(texts, fmt.Sprintf("msg-%d", i))
+	}
+	publishTexts(t, srv, "room1", texts...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	textOnly := []imv1.MessageType{imv1.MessageType_MESSAGE_TYPE_TEXT}
+
+	// 从旧到新逐页请求，每页3条
+	got := collectHistory(t, c.FetchHistory(ctx, "room1", HistoryOptions{
+		Direction: imv1.HistoryDirection_HISTORY_DIRECTION_FORWARD,
+		Types:     textOnly,
+		PageSize:  3,
+	}))
+	if !slices.Equal(got, texts) {
+		t.Fatalf("FetchHistory = %v, want %v", got, texts)
+	}
+	if n := len(calls()); n != 3 {
+		t.Fatalf("GetHistory 调用 %d 次, want 3", n)
+	}
+
+	// 默认从新到旧，Limit 限制返回的总数
+	it := c.FetchHistory(ctx, "room1", HistoryOptions{Types: textOnly, PageSize: 2, Limit: 3})
+	got = collectHistory(t, it)
+	if want := []string{"msg-7", "msg-6", "msg-5"}; !slices.Equal(got, want) {
+		t.Fatalf("FetchHistory = %v, want %v", got, want)
+	}
+
+	// 从 Cursor 返回的位置继续翻页
+	got = collectHistory(t, c.FetchHistory(ctx, "room1", HistoryOptions{Types: textOnly, Cursor: it.Cursor()}))
+	if want := []string{"msg-4", "msg-3", "msg-2", "msg-1"}; !slices.Equal(got, want) {
+		t.Fatalf("FetchHistory = %v, want %v", got, want)
+	}
+}
+
+func TestFetchHistoryError(t *testing.T) {
+	srv := newTestServer(t)
+	c := connectTestClient(t, newTestConfig(srv, "alice"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	it := c.FetchHistory(ctx, "missing", HistoryOptions{})
+	if it.Next() {
+		t.Fatal("不存在的房间不应返回消息")
+	}
+	var statusErr *StatusError
+	if !errors.As(it.Err(), &statusErr) {
+		t.Fatalf("Err = %v, want *StatusError", it.Err())
+	}
+}
+
+func TestReconnectFillsGapFromHistory(t *testing.T) {
+	srv := newTestServer(t)
+	interceptor, calls := countHistoryCalls(0)
+	received := make(chan *imv1.MessageResponse, 16)
+	config := newTestConfig(srv, "alice")
+	config.UnaryInterceptors = []grpc.UnaryClientInterceptor{interceptor}
+	config.OnMessage = func(msg *imv1.MessageResponse) {
+		if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+			received <- msg
+		}
+	}
+	c := connectTestClient(t, config)
+	disc := config.Discovery.(*imtest.Discovery)
+	waitSession(t, srv, "alice")
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	// 收到加入房间的系统消息后客户端才有该房间的序号记录
+	deadline := time.Now().Add(testTimeout)
+	for c.LastSequence("room1") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("没有收到加入房间的系统消息")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	changes, unsubscribe := c.SubscribeState()
+	defer unsubscribe()
+
+	// 断线期间发布的消息只保存在房间历史中
+	disc.FailDiscover(errors.New("discovery down"))
+	dropStreams(t, srv, 1)
+	expectStateChanges(t, changes, StateChange{From: StateReady, To: StateReconnecting})
+	publishTexts(t, srv, "room1", "missed-1", "missed-2")
+	before := len(calls())
+	disc.FailDiscover(nil)
+	expectStateChanges(t, changes, StateChange{From: StateReconnecting, To: StateReady})
+
+	for _, want := range []string{"missed-1", "missed-2"} {
+		select {
+		case msg := <-received:
+			if string(msg.Content) != want {
+				t.Fatalf("收到 %q, want %q", msg.Content, want)
+			}
+		case <-time.After(testTimeout):
+			t.Fatalf("等待补齐的消息 %q 超时", want)
+		}
+	}
+	if n := len(calls()); n <= before {
+		t.Fatal("重连后应通过 GetHistory 补齐断线期间的消息")
+	}
+}
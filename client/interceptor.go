@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// MessageInterceptor SDK消息拦截器
+//
+// InterceptSend 在每条消息（包括心跳、ACK、重传和发件箱补发的消息）调用 stream.Send 之前执行，
+// InterceptReceive 在每条收到的消息分发给 OnMessage 和处理器之前执行。
+// 拦截器可以直接修改消息，返回错误时丢弃该消息并通过 OnError 回调报告。
+type MessageInterceptor interface {
+	InterceptSend(msg *imv1.MessageRequest) error
+	InterceptReceive(msg *imv1.MessageResponse) error
+}
+
+// MessageInterceptorFuncs 函数形式的消息拦截器，未设置的函数不做处理
+type MessageInterceptorFuncs struct {
+	Send    func(msg *imv1.MessageRequest) error
+	Receive func(msg *imv1.MessageResponse) error
+}
+
+// InterceptSend 拦截发送的消息
+func (f MessageInterceptorFuncs) InterceptSend(msg *imv1.MessageRequest) error {
+	if f.Send == nil {
+		return nil
+	}
+	return f.Send(msg)
+}
+
+// InterceptReceive 拦截收到的消息
+func (f MessageInterceptorFuncs) InterceptReceive(msg *imv1.MessageResponse) error {
+	if f.Receive == nil {
+		return nil
+	}
+	return f.Receive(msg)
+}
+
+// interceptSend 按顺序执行发送拦截器
+func (c *Client) interceptSend(msg *imv1.MessageRequest) error {
+	for _, interceptor := range c.config.MessageInterceptors {
+		if err := interceptor.InterceptSend(msg); err != nil {
+			return fmt.Errorf("消息 %s 被拦截器丢弃: %w", msg.MessageId, err)
+		}
+	}
+	return nil
+}
+
+// interceptReceive 按顺序执行接收拦截器
+func (c *Client) interceptReceive(msg *imv1.MessageResponse) error {
+	for _, interceptor := range c.config.MessageInterceptors {
+		if err := interceptor.InterceptReceive(msg); err != nil {
+			return fmt.Errorf("消息 %s 被拦截器丢弃: %w", msg.MessageId, err)
+		}
+	}
+	return nil
+}
+
+// dialOptions 返回 Config 中配置的拦截器和自定义拨号选项
+func (c *Client) dialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+	if len(c.config.UnaryInterceptors) > 0 {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(c.config.UnaryInterceptors...))
+	}
+	if len(c.config.StreamInterceptors) > 0 {
+		opts = append(opts, grpc.WithChainStreamInterceptor(c.config.StreamInterceptors...))
+	}
+	return append(opts, c.config.DialOptions...)
+}
+
+// interceptedConn 在已有连接上执行 Config 中配置的拦截器
+type interceptedConn struct {
+	cc     grpc.ClientConnInterface
+	unary  []grpc.UnaryClientInterceptor
+	stream []grpc.StreamClientInterceptor
+}
+
+// newInterceptedConn 包装已有连接，未配置拦截器时原样返回
+func newInterceptedConn(cc grpc.ClientConnInterface, config *Config) grpc.ClientConnInterface {
+	if len(config.UnaryInterceptors) == 0 && len(config.StreamInterceptors) == 0 {
+		return cc
+	}
+	return &interceptedConn{
+		cc:     cc,
+		unary:  config.UnaryInterceptors,
+		stream: config.StreamInterceptors,
+	}
+}
+
+// Invoke 经过一元拦截器链后执行一元调用
+func (ic *interceptedConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	return ic.invoke(0, ctx, method, args, reply, opts...)
+}
+
+// invoke 执行第 i 个一元拦截器
+func (ic *interceptedConn) invoke(i int, ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	if i == len(ic.unary) {
+		return ic.cc.Invoke(ctx, method, args, reply, opts...)
+	}
+
+	next := func(ctx context.Context, method string, args, reply interface{}, _ *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return ic.invoke(i+1, ctx, method, args, reply, opts...)
+	}
+	return ic.unary[i](ctx, method, args, reply, ic.clientConn(), next, opts...)
+}
+
+// NewStream 经过流拦截器链后创建流
+func (ic *interceptedConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return ic.newStream(0, ctx, desc, method, opts...)
+}
+
+// newStream 执行第 i 个流拦截器
+func (ic *interceptedConn) newStream(i int, ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if i == len(ic.stream) {
+		return ic.cc.NewStream(ctx, desc, method, opts...)
+	}
+
+	next := func(ctx context.Context, desc *grpc.StreamDesc, _ *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return ic.newStream(i+1, ctx, desc, method, opts...)
+	}
+	return ic.stream[i](ctx, desc, ic.clientConn(), method, next, opts...)
+}
+
+// clientConn 返回传给拦截器的 *grpc.ClientConn，底层连接不是 *grpc.ClientConn 时为nil
+func (ic *interceptedConn) clientConn() *grpc.ClientConn {
+	conn, _ := ic.cc.(*grpc.ClientConn)
+	return conn
+}
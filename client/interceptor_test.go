@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// chainRecorder 记录拦截器的执行顺序，每条记录为 "拦截器名称 方法名"
+type chainRecorder struct {
+	calls *imtest.Recorder[string]
+}
+
+// unary 返回名为 name 的一元拦截器
+func (r chainRecorder) unary(name string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		r.calls.Record(name + " " + method)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// stream 返回名为 name 的流拦截器
+func (r chainRecorder) stream(name string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		r.calls.Record(name + " " + method)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// order 返回调用 method 时拦截器的执行顺序
+func (r chainRecorder) order(method string) []string {
+	var names []string
+	for _, call := range r.calls.Filter(func(call string) bool { return strings.HasSuffix(call, " "+method) }) {
+		names = append(names, strings.TrimSuffix(call, " "+method))
+	}
+	return names
+}
+
+func TestInterceptorChainOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		connect func(t *testing.T, srv *imtest.Server, config *Config) *Client
+	}{
+		{"SDK管理的连接", func(t *testing.T, srv *imtest.Server, config *Config) *Client {
+			return connectTestClient(t, config)
+		}},
+		{"注入的连接", func(t *testing.T, srv *imtest.Server, config *Config) *Client {
+			conn, err := srv.Dial()
+			if err != nil {
+				t.Fatalf("Dial: %v", err)
+			}
+			t.Cleanup(func() { conn.Close() })
+
+			config.Discovery = nil
+			c, err := NewClientWithConn(conn, config)
+			if err != nil {
+				t.Fatalf("NewClientWithConn: %v", err)
+			}
+			if err := c.Connect(); err != nil {
+				t.Fatalf("Connect: %v", err)
+			}
+			t.Cleanup(func() { c.Disconnect() })
+			return c
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			rec := chainRecorder{calls: imtest.NewRecorder[string]()}
+			config := newTestConfig(srv, "alice")
+			config.UnaryInterceptors = []grpc.UnaryClientInterceptor{rec.unary("first"), rec.unary("second")}
+			config.StreamInterceptors = []grpc.StreamClientInterceptor{rec.stream("first"), rec.stream("second")}
+			c := tt.connect(t, srv, config)
+
+			if _, err := c.JoinRoom("room1", nil); err != nil {
+				t.Fatalf("JoinRoom: %v", err)
+			}
+
+			// 拦截器按配置的顺序执行，第一个在最外层
+			want := []string{"first", "second"}
+			if got := rec.order(imv1.IMService_JoinRoom_FullMethodName); !slices.Equal(got, want) {
+				t.Fatalf("JoinRoom 拦截器顺序 = %v, want %v", got, want)
+			}
+			if got := rec.order(imv1.IMService_StreamMessages_FullMethodName); !slices.Equal(got, want) {
+				t.Fatalf("StreamMessages 拦截器顺序 = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMessageInterceptorDropsMessage(t *testing.T) {
+	srv := newTestServer(t)
+	errs := make(chan error, 8)
+	received := make(chan *imv1.MessageResponse, 8)
+	config := newTestConfig(srv, "alice")
+	config.OnError = func(err error) { errs <- err }
+	config.OnMessage = func(msg *imv1.MessageResponse) {
+		if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+			received <- msg
+		}
+	}
+	config.MessageInterceptors = []MessageInterceptor{
+		// 第一个拦截器的修改对之后的拦截器可见
+		MessageInterceptorFuncs{
+			Send: func(msg *imv1.MessageRequest) error {
+				if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+					if msg.Metadata == nil {
+						msg.Metadata = make(map[string]string)
+					}
+					msg.Metadata["checked"] = "true"
+				}
+				return nil
+			},
+		},
+		MessageInterceptorFuncs{
+			Send: func(msg *imv1.MessageRequest) error {
+				if string(msg.Content) == "secret" {
+					return errDropped
+				}
+				return nil
+			},
+			Receive: func(msg *imv1.MessageResponse) error {
+				if msg.FromUserId == "spammer" {
+					return errDropped
+				}
+				return nil
+			},
+		},
+	}
+	c := connectTestClient(t, config)
+	waitSession(t, srv, "alice")
+
+	expectDropped := func() {
+		t.Helper()
+		select {
+		case err := <-errs:
+			if !errors.Is(err, errDropped) {
+				t.Fatalf("OnError = %v, want errDropped", err)
+			}
+		case <-time.After(testTimeout):
+			t.Fatal("被丢弃的消息没有通过 OnError 报告")
+		}
+	}
+
+	// 被拦截的消息不发往服务端，之后的消息正常发送
+	if err := c.SendTextMessage("room1", "secret"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	if err := c.SendTextMessage("room1", "hello"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	expectDropped()
+	msg := waitReceived(t, srv, "hello")
+	if msg.Metadata["checked"] != "true" {
+		t.Fatalf("Metadata = %v, want 第一个拦截器添加的 checked", msg.Metadata)
+	}
+	if n := len(srv.Received().Filter(imtest.TextRequest("secret"))); n != 0 {
+		t.Fatalf("服务端收到 %d 条被拦截的消息, want 0", n)
+	}
+
+	// 被拦截的消息不分发给 OnMessage
+	for _, from := range []string{"spammer", "bob"} {
+		srv.Push("alice", &imv1.MessageResponse{
+			MessageId:  "from-" + from,
+			Type:       imv1.MessageType_MESSAGE_TYPE_TEXT,
+			RoomId:     "room1",
+			FromUserId: from,
+			Content:    []byte("hi"),
+		})
+	}
+	expectDropped()
+	select {
+	case msg := <-received:
+		if msg.FromUserId != "bob" {
+			t.Fatalf("OnMessage 收到 %s 的消息, want bob", msg.FromUserId)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("等待 bob 的消息超时")
+	}
+}
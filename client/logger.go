@@ -0,0 +1,63 @@
+package client
+
+import (
+	"log/slog"
+)
+
+// Logger 结构化日志接口，args 为交替出现的键值对
+//
+// *slog.Logger 直接实现了该接口。
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// NewSlogLogger 使用 slog 输出日志，logger 为nil时使用 slog.Default()
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger
+}
+
+// NopLogger 返回丢弃所有日志的 Logger，未配置 Logger 时使用
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+// nopLogger 丢弃所有日志
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// fieldLogger 为每条日志附加固定字段
+type fieldLogger struct {
+	logger Logger
+	fields []any
+}
+
+// newClientLogger 创建附带 user_id 字段的客户端日志
+func newClientLogger(config *Config) Logger {
+	if config.Logger == nil {
+		return nopLogger{}
+	}
+	return &fieldLogger{
+		logger: config.Logger,
+		fields: []any{"user_id", config.UserID},
+	}
+}
+
+func (l *fieldLogger) Debug(msg string, args ...any) { l.logger.Debug(msg, l.with(args)...) }
+func (l *fieldLogger) Info(msg string, args ...any)  { l.logger.Info(msg, l.with(args)...) }
+func (l *fieldLogger) Warn(msg string, args ...any)  { l.logger.Warn(msg, l.with(args)...) }
+func (l *fieldLogger) Error(msg string, args ...any) { l.logger.Error(msg, l.with(args)...) }
+
+// with 在日志参数前加上固定字段
+func (l *fieldLogger) with(args []any) []any {
+	return append(append(make([]any, 0, len(l.fields)+len(args)), l.fields...), args...)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// ReconnectOutcome 重连结果
+type ReconnectOutcome string
+
+const (
+	ReconnectSuccess ReconnectOutcome = "success" // 重连成功
+	ReconnectFailure ReconnectOutcome = "failure" // 本次重连失败，稍后重试
+	ReconnectGiveUp  ReconnectOutcome = "give_up" // 重连失败并放弃
+)
+
+// Metrics 客户端指标收集接口
+//
+// 方法会在收发消息的goroutine中同步调用，实现需要线程安全且不能阻塞。
+// metrics 包提供了基于 Prometheus 的实现。
+type Metrics interface {
+	// MessageSent 消息写入流，latency 为消息创建（Timestamp）到写入流的耗时，没有 Timestamp 时为0
+	MessageSent(msgType imv1.MessageType, latency time.Duration)
+	// MessageReceived 从流中收到消息
+	MessageReceived(msgType imv1.MessageType)
+	// QueueDepth 发送队列中等待发送的消息数量
+	QueueDepth(depth int)
+	// RPCCompleted 一元调用或上传完成
+	RPCCompleted(method string, latency time.Duration, err error)
+	// ReconnectAttempt 一次重连尝试结束
+	ReconnectAttempt(outcome ReconnectOutcome)
+	// HeartbeatRTT 心跳往返时间
+	HeartbeatRTT(rtt time.Duration)
+	// DiscoveryUpdate 服务发现推送了新的服务列表
+	DiscoveryUpdate(services int)
+}
+
+// noopMetrics 未配置 Metrics 时使用的空实现
+type noopMetrics struct{}
+
+func (noopMetrics) MessageSent(imv1.MessageType, time.Duration) {}
+func (noopMetrics) MessageReceived(imv1.MessageType)            {}
+func (noopMetrics) QueueDepth(int)                              {}
+func (noopMetrics) RPCCompleted(string, time.Duration, error)   {}
+func (noopMetrics) ReconnectAttempt(ReconnectOutcome)           {}
+func (noopMetrics) HeartbeatRTT(time.Duration)                  {}
+func (noopMetrics) DiscoveryUpdate(int)                         {}
+
+// metrics 返回配置的指标收集器
+func (c *Client) metrics() Metrics {
+	if c.config.Metrics != nil {
+		return c.config.Metrics
+	}
+	return noopMetrics{}
+}
+
+// startRPC 开始记录一次RPC调用的span和耗时，返回调用结束时执行的函数
+func (c *Client) startRPC(ctx context.Context, method, roomID string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := c.startSpan(ctx, method, roomID)
+
+	return ctx, func(err error) {
+		endSpan(span, err)
+		c.metrics().RPCCompleted(method, time.Since(start), err)
+	}
+}
+
+// observeSent 记录写入流的消息
+func (c *Client) observeSent(msg *imv1.MessageRequest) {
+	var latency time.Duration
+	if msg.Timestamp != nil {
+		latency = time.Since(msg.Timestamp.AsTime())
+	}
+	if msg.Type == imv1.MessageType_MESSAGE_TYPE_HEARTBEAT {
+		c.lastPing.Store(time.Now().UnixNano())
+	}
+
+	metrics := c.metrics()
+	metrics.MessageSent(msg.Type, latency)
+	metrics.QueueDepth(len(c.messageCh))
+}
+
+// observeReceived 记录从流中收到的消息
+func (c *Client) observeReceived(msg *imv1.MessageResponse) {
+	metrics := c.metrics()
+	metrics.MessageReceived(msg.Type)
+
+	if msg.Type == imv1.MessageType_MESSAGE_TYPE_HEARTBEAT {
+		if sent := c.lastPing.Swap(0); sent != 0 {
+			metrics.HeartbeatRTT(time.Since(time.Unix(0, sent)))
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package client
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// Outbox 发件箱接口
+//
+// 配置发件箱后，SendMessage 会先把消息写入发件箱再返回，
+// 流连接可用时按入队顺序发送，发送成功后再从发件箱移除，
+// 因此断线期间以及进程重启前未发出的消息都不会丢失。
+type Outbox interface {
+	// Enqueue 消息入队，MessageId 已存在时忽略
+	Enqueue(msg *imv1.MessageRequest) error
+
+	// Pending 按入队顺序返回所有待发送的消息
+	Pending() ([]*imv1.MessageRequest, error)
+
+	// Remove 移除已发送的消息
+	Remove(messageID string) error
+
+	// Len 待发送的消息数量
+	Len() int
+
+	// Close 关闭发件箱
+	Close() error
+}
+
+// MemoryOutbox 内存发件箱，消息可以跨断线保留，但不能跨进程重启
+type MemoryOutbox struct {
+	messages []*imv1.MessageRequest
+	index    map[string]struct{}
+	mu       sync.Mutex
+}
+
+// NewMemoryOutbox 创建内存发件箱
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{
+		index: make(map[string]struct{}),
+	}
+}
+
+// Enqueue 消息入队
+func (o *MemoryOutbox) Enqueue(msg *imv1.MessageRequest) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, exists := o.index[msg.MessageId]; exists {
+		return nil
+	}
+
+	o.index[msg.MessageId] = struct{}{}
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// Pending 返回所有待发送的消息
+func (o *MemoryOutbox) Pending() ([]*imv1.MessageRequest, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	result := make([]*imv1.MessageRequest, len(o.messages))
+	copy(result, o.messages)
+	return result, nil
+}
+
+// Remove 移除已发送的消息
+func (o *MemoryOutbox) Remove(messageID string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, exists := o.index[messageID]; !exists {
+		return nil
+	}
+
+	delete(o.index, messageID)
+	for i, msg := range o.messages {
+		if msg.MessageId == messageID {
+			o.messages = append(o.messages[:i], o.messages[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Len 待发送的消息数量
+func (o *MemoryOutbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.messages)
+}
+
+// Close 关闭发件箱
+func (o *MemoryOutbox) Close() error {
+	return nil
+}
+
+const (
+	outboxOpEnqueue byte = 'E'
+	outboxOpRemove  byte = 'R'
+
+	// outboxCompactThreshold 已移除记录超过该数量且多于待发送消息时压缩日志
+	outboxCompactThreshold = 1024
+	// maxOutboxRecordSize 单条记录的最大长度，与 gRPC 默认的最大消息大小一致
+	maxOutboxRecordSize = 4 << 20
+)
+
+// errOutboxRecordTooLarge 记录长度超过上限，说明文件已损坏
+var errOutboxRecordTooLarge = errors.New("发件箱记录长度超过上限")
+
+// FileOutbox 基于追加日志的文件发件箱
+//
+// 每条记录由 1 字节操作类型、4 字节长度和数据组成：入队记录保存
+// protobuf 编码的 MessageRequest，移除记录保存 MessageId。
+// 打开时重放日志恢复待发送消息，已移除的记录过多时自动压缩。
+// 重放时丢弃的损坏内容可以通过 ReplayError 获取，客户端首次处理发件箱时会通过 OnError 报告。
+type FileOutbox struct {
+	path      string
+	file      *os.File
+	memory    *MemoryOutbox
+	removed   int
+	replayErr error
+	mu        sync.Mutex
+}
+
+// NewFileOutbox 打开（或创建）文件发件箱
+func NewFileOutbox(path string) (*FileOutbox, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开发件箱文件失败: %w", err)
+	}
+
+	o := &FileOutbox{
+		path:   path,
+		file:   file,
+		memory: NewMemoryOutbox(),
+	}
+
+	if err := o.replay(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// replay 重放日志，末尾不完整的记录（写入时进程退出）和长度损坏的记录及其之后的内容会被截断，
+// 丢弃的字节数记录在 replayErr 中
+func (o *FileOutbox) replay() error {
+	reader := bufio.NewReader(o.file)
+	var offset int64
+	var cause error
+
+	for {
+		op, payload, n, err := readOutboxRecord(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, errOutboxRecordTooLarge) {
+				cause = err
+				break
+			}
+			return fmt.Errorf("读取发件箱文件失败: %w", err)
+		}
+
+		switch op {
+		case outboxOpEnqueue:
+			msg := &imv1.MessageRequest{}
+			if err := proto.Unmarshal(payload, msg); err != nil {
+				return fmt.Errorf("解析发件箱消息失败: %w", err)
+			}
+			o.memory.Enqueue(msg)
+		case outboxOpRemove:
+			o.memory.Remove(string(payload))
+			o.removed++
+		default:
+			return fmt.Errorf("未知的发件箱记录类型: %q", op)
+		}
+		offset += n
+	}
+
+	if cause != nil {
+		info, err := o.file.Stat()
+		if err != nil {
+			return fmt.Errorf("读取发件箱文件失败: %w", err)
+		}
+		o.replayErr = fmt.Errorf("%w: %s 在偏移 %d 处的记录无效（%v），丢弃了之后的 %d 字节",
+			ErrOutboxCorrupted, o.path, offset, cause, info.Size()-offset)
+	}
+
+	if err := o.file.Truncate(offset); err != nil {
+		return fmt.Errorf("截断发件箱文件失败: %w", err)
+	}
+	if _, err := o.file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("定位发件箱文件失败: %w", err)
+	}
+
+	return nil
+}
+
+// ReplayError 返回打开时重放日志丢弃损坏内容的错误（包装 ErrOutboxCorrupted），没有丢弃数据时返回nil
+func (o *FileOutbox) ReplayError() error {
+	return o.replayErr
+}
+
+// Enqueue 消息入队并同步落盘
+func (o *FileOutbox) Enqueue(msg *imv1.MessageRequest) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return fmt.Errorf("发件箱已关闭")
+	}
+
+	if _, exists := o.memory.index[msg.MessageId]; exists {
+		return nil
+	}
+
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	if len(payload) > maxOutboxRecordSize {
+		return fmt.Errorf("消息 %s 大小 %d 字节: %w", msg.MessageId, len(payload), errOutboxRecordTooLarge)
+	}
+
+	if err := writeOutboxRecord(o.file, outboxOpEnqueue, payload); err != nil {
+		return err
+	}
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("同步发件箱文件失败: %w", err)
+	}
+
+	return o.memory.Enqueue(msg)
+}
+
+// Pending 返回所有待发送的消息
+func (o *FileOutbox) Pending() ([]*imv1.MessageRequest, error) {
+	return o.memory.Pending()
+}
+
+// Remove 移除已发送的消息并同步落盘
+func (o *FileOutbox) Remove(messageID string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return fmt.Errorf("发件箱已关闭")
+	}
+
+	if _, exists := o.memory.index[messageID]; !exists {
+		return nil
+	}
+
+	if err := writeOutboxRecord(o.file, outboxOpRemove, []byte(messageID)); err != nil {
+		return err
+	}
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("同步发件箱文件失败: %w", err)
+	}
+
+	o.memory.Remove(messageID)
+	o.removed++
+
+	if o.removed > outboxCompactThreshold && o.removed > o.memory.Len() {
+		return o.compact()
+	}
+	return nil
+}
+
+// compact 只保留待发送消息重写日志
+func (o *FileOutbox) compact() error {
+	tmpPath := o.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("压缩发件箱失败: %w", err)
+	}
+
+	pending, _ := o.memory.Pending()
+	for _, msg := range pending {
+		payload, err := proto.Marshal(msg)
+		if err == nil {
+			err = writeOutboxRecord(tmp, outboxOpEnqueue, payload)
+		}
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("压缩发件箱失败: %w", err)
+		}
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("压缩发件箱失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, o.path); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("压缩发件箱失败: %w", err)
+	}
+
+	o.file.Close()
+	o.file = tmp
+	o.removed = 0
+
+	// 同步目录，保证掉电后重命名仍然有效
+	if err := syncDir(filepath.Dir(o.path)); err != nil {
+		return fmt.Errorf("同步发件箱目录失败: %w", err)
+	}
+	return nil
+}
+
+// syncDir 把目录项的变化同步到磁盘
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// Len 待发送的消息数量
+func (o *FileOutbox) Len() int {
+	return o.memory.Len()
+}
+
+// Close 关闭发件箱
+func (o *FileOutbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil {
+		return nil
+	}
+
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
+
+// writeOutboxRecord 写入一条日志记录
+func writeOutboxRecord(w io.Writer, op byte, payload []byte) error {
+	record := make([]byte, 5+len(payload))
+	record[0] = op
+	binary.BigEndian.PutUint32(record[1:5], uint32(len(payload)))
+	copy(record[5:], payload)
+
+	if _, err := w.Write(record); err != nil {
+		return fmt.Errorf("写入发件箱文件失败: %w", err)
+	}
+	return nil
+}
+
+// readOutboxRecord 读取一条日志记录，返回记录占用的字节数
+func readOutboxRecord(r io.Reader) (byte, []byte, int64, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, 0, err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:5])
+	if size > maxOutboxRecordSize {
+		return 0, nil, 0, fmt.Errorf("%w: %d 字节", errOutboxRecordTooLarge, size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, 0, err
+	}
+
+	return header[0], payload, int64(len(header) + len(payload)), nil
+}
+
+// reportOutboxReplay 通过 OnError 报告发件箱打开时因文件损坏丢弃的数据
+func (c *Client) reportOutboxReplay() {
+	replayer, ok := c.config.Outbox.(interface{ ReplayError() error })
+	if !ok {
+		return
+	}
+	if err := replayer.ReplayError(); err != nil {
+		c.logger.Warn("发件箱文件已损坏", "error", err)
+		if c.config.OnError != nil {
+			c.config.OnError(err)
+		}
+	}
+}
+
+// notifyOutbox 通知发送协程处理发件箱
+func (c *Client) notifyOutbox() {
+	if c.config.Outbox == nil {
+		return
+	}
+
+	select {
+	case c.outboxCh <- struct{}{}:
+	default:
+	}
+}
+
+// flushOutbox 按顺序发送发件箱中的消息，发送失败时保留剩余消息并触发重连（流已结束时由接收协程处理）
+func (c *Client) flushOutbox() {
+	if c.config.Outbox == nil {
+		return
+	}
+	c.outboxChecked.Do(c.reportOutboxReplay)
+	if !c.IsConnected() {
+		return
+	}
+
+	pending, err := c.config.Outbox.Pending()
+	if err != nil {
+		if c.config.OnError != nil {
+			c.config.OnError(fmt.Errorf("读取发件箱失败: %w", err))
+		}
+		return
+	}
+
+	for _, msg := range pending {
+		if err := c.interceptSend(msg); err != nil {
+			// 被拦截器丢弃的消息不再保留
+			if c.config.OnError != nil {
+				c.config.OnError(err)
+			}
+		} else if err := c.sendOnStream(msg); err != nil {
+			if c.config.OnError != nil {
+				c.config.OnError(err)
+			}
+			c.streamSendFailed(err)
+			return
+		} else {
+			c.observeSent(msg)
+		}
+
+		if err := c.config.Outbox.Remove(msg.MessageId); err != nil && c.config.OnError != nil {
+			c.config.OnError(fmt.Errorf("从发件箱移除消息失败: %w", err))
+		}
+	}
+}
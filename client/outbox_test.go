@@ -0,0 +1,222 @@
+package client
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+func pendingIDs(t *testing.T, o Outbox) []string {
+	t.Helper()
+
+	pending, err := o.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	ids := make([]string, len(pending))
+	for i, msg := range pending {
+		ids[i] = msg.MessageId
+	}
+	return ids
+}
+
+func TestFileOutboxReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	o, err := NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("NewFileOutbox: %v", err)
+	}
+	for _, id := range []string{"m1", "m2", "m3"} {
+		if err := o.Enqueue(&imv1.MessageRequest{MessageId: id, RoomId: "r1"}); err != nil {
+			t.Fatalf("Enqueue %s: %v", id, err)
+		}
+	}
+	if err := o.Remove("m2"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	o.Close()
+
+	o, err = NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("重新打开发件箱失败: %v", err)
+	}
+	defer o.Close()
+	if ids := pendingIDs(t, o); len(ids) != 2 || ids[0] != "m1" || ids[1] != "m3" {
+		t.Fatalf("Pending = %v, want [m1 m3]", ids)
+	}
+}
+
+func TestFileOutboxTruncatesCorruptTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	o, err := NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("NewFileOutbox: %v", err)
+	}
+	if err := o.Enqueue(&imv1.MessageRequest{MessageId: "m1"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := o.ReplayError(); err != nil {
+		t.Fatalf("ReplayError = %v, want nil", err)
+	}
+	o.Close()
+
+	info, _ := os.Stat(path)
+	valid := info.Size()
+
+	// 长度字段损坏的记录不能导致按损坏的长度分配内存
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	var header [5]byte
+	header[0] = outboxOpEnqueue
+	binary.BigEndian.PutUint32(header[1:], 0xFFFFFFF0)
+	file.Write(header[:])
+	file.Write([]byte("garbage"))
+	file.Close()
+
+	o, err = NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("打开损坏的发件箱失败: %v", err)
+	}
+	if ids := pendingIDs(t, o); len(ids) != 1 || ids[0] != "m1" {
+		t.Fatalf("Pending = %v, want [m1]", ids)
+	}
+	if info, _ := os.Stat(path); info.Size() != valid {
+		t.Fatalf("文件大小 = %d, want %d（损坏的内容应被截断）", info.Size(), valid)
+	}
+	// 报告丢弃的字节数：5 字节记录头和 7 字节数据
+	if err := o.ReplayError(); !errors.Is(err, ErrOutboxCorrupted) || !strings.Contains(err.Error(), "丢弃了之后的 12 字节") {
+		t.Fatalf("ReplayError = %v, want ErrOutboxCorrupted 并报告丢弃了 12 字节", err)
+	}
+
+	// 截断后可以继续写入
+	if err := o.Enqueue(&imv1.MessageRequest{MessageId: "m2"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	o.Close()
+	o, err = NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("NewFileOutbox: %v", err)
+	}
+	defer o.Close()
+	if ids := pendingIDs(t, o); len(ids) != 2 {
+		t.Fatalf("Pending = %v, want [m1 m2]", ids)
+	}
+	if err := o.ReplayError(); err != nil {
+		t.Fatalf("ReplayError = %v, want nil", err)
+	}
+}
+
+func TestFileOutboxCompacts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "outbox.log")
+	o, err := NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("NewFileOutbox: %v", err)
+	}
+	if err := o.Enqueue(&imv1.MessageRequest{MessageId: "keep"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	for i := 0; i <= outboxCompactThreshold; i++ {
+		id := fmt.Sprintf("m%d", i)
+		if err := o.Enqueue(&imv1.MessageRequest{MessageId: id}); err != nil {
+			t.Fatalf("Enqueue %s: %v", id, err)
+		}
+		if err := o.Remove(id); err != nil {
+			t.Fatalf("Remove %s: %v", id, err)
+		}
+	}
+
+	// 压缩后只保留待发送的消息，临时文件已重命名为日志文件
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size() > 64 {
+		t.Fatalf("压缩后文件大小 = %d, want 只包含一条记录", info.Size())
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("临时文件应已重命名: %v", err)
+	}
+
+	// 压缩后继续写入的记录在重新打开后仍然有效
+	if err := o.Enqueue(&imv1.MessageRequest{MessageId: "after"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	o.Close()
+	o, err = NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("NewFileOutbox: %v", err)
+	}
+	defer o.Close()
+	if ids := pendingIDs(t, o); !slices.Equal(ids, []string{"keep", "after"}) {
+		t.Fatalf("Pending = %v, want [keep after]", ids)
+	}
+}
+
+func TestClientReportsCorruptOutbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.log")
+	o, err := NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("NewFileOutbox: %v", err)
+	}
+	if err := o.Enqueue(&imv1.MessageRequest{MessageId: "m1", RoomId: "room1", Type: imv1.MessageType_MESSAGE_TYPE_TEXT, Content: []byte("hello")}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	o.Close()
+
+	// 模拟写入记录时进程退出，只写入了一半
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	var header [5]byte
+	header[0] = outboxOpEnqueue
+	binary.BigEndian.PutUint32(header[1:], 100)
+	file.Write(header[:])
+	file.Write([]byte("partial"))
+	file.Close()
+
+	o, err = NewFileOutbox(path)
+	if err != nil {
+		t.Fatalf("NewFileOutbox: %v", err)
+	}
+	defer o.Close()
+
+	srv := newTestServer(t)
+	errs := make(chan error, 4)
+	config := newTestConfig(srv, "alice")
+	config.Outbox = o
+	config.OnError = func(err error) { errs <- err }
+	connectTestClient(t, config)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrOutboxCorrupted) || !strings.Contains(err.Error(), "丢弃了之后的 12 字节") {
+			t.Fatalf("OnError = %v, want ErrOutboxCorrupted 并报告丢弃了 12 字节", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("没有通过 OnError 报告发件箱损坏")
+	}
+	// 损坏位置之前的消息照常补发
+	waitReceived(t, srv, "hello")
+}
+
+func TestFileOutboxRejectsOversizedMessage(t *testing.T) {
+	o, err := NewFileOutbox(filepath.Join(t.TempDir(), "outbox.log"))
+	if err != nil {
+		t.Fatalf("NewFileOutbox: %v", err)
+	}
+	defer o.Close()
+
+	msg := &imv1.MessageRequest{MessageId: "big", Content: make([]byte, maxOutboxRecordSize+1)}
+	if err := o.Enqueue(msg); err == nil {
+		t.Fatal("超过上限的消息应写入失败")
+	}
+	if o.Len() != 0 {
+		t.Fatalf("Len = %d, want 0", o.Len())
+	}
+}
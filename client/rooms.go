@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// roomTracker 记录已加入的房间及加入时的metadata，用于重连后恢复
+type roomTracker struct {
+	rooms map[string]map[string]string
+	mu    sync.RWMutex
+}
+
+// newRoomTracker 创建房间记录
+func newRoomTracker() *roomTracker {
+	return &roomTracker{
+		rooms: make(map[string]map[string]string),
+	}
+}
+
+// add 记录已加入的房间
+func (t *roomTracker) add(roomID string, metadata map[string]string) {
+	copied := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+
+	t.mu.Lock()
+	t.rooms[roomID] = copied
+	t.mu.Unlock()
+}
+
+// remove 移除房间记录
+func (t *roomTracker) remove(roomID string) {
+	t.mu.Lock()
+	delete(t.rooms, roomID)
+	t.mu.Unlock()
+}
+
+// snapshot 返回当前已加入房间的副本
+func (t *roomTracker) snapshot() map[string]map[string]string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rooms := make(map[string]map[string]string, len(t.rooms))
+	for roomID, metadata := range t.rooms {
+		rooms[roomID] = metadata
+	}
+	return rooms
+}
+
+// JoinedRooms 返回当前已加入的房间ID列表
+func (c *Client) JoinedRooms() []string {
+	rooms := c.rooms.snapshot()
+
+	result := make([]string, 0, len(rooms))
+	for roomID := range rooms {
+		result = append(result, roomID)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// restoreRooms 重连成功后重新加入之前已加入的房间
+func (c *Client) restoreRooms() {
+	if !c.config.AutoRejoinRooms {
+		return
+	}
+
+	for roomID, metadata := range c.rooms.snapshot() {
+		if err := c.rejoinRoom(roomID, metadata); err != nil {
+			if c.config.OnRoomRestoreFailed != nil {
+				c.config.OnRoomRestoreFailed(roomID, err)
+			}
+		}
+	}
+}
+
+// rejoinRoom 重新加入单个房间
+func (c *Client) rejoinRoom(roomID string, metadata map[string]string) error {
+	c.mu.RLock()
+	grpcClient := c.client
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.config.RequestTimeout)
+	defer cancel()
+
+	_, err := invokeWithCredentials(c, ctx, func(ctx context.Context) (*imv1.JoinRoomResponse, error) {
+		return grpcClient.JoinRoom(ctx, &imv1.JoinRoomRequest{
+			UserId:   c.config.UserID,
+			RoomId:   roomID,
+			Metadata: metadata,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("重新加入房间 %s 失败: %w", roomID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// SendMode 消息发送方式
+type SendMode string
+
+const (
+	// SendModeStream 通过消息流发送（默认），流不可用时返回错误或写入发件箱
+	SendModeStream SendMode = "stream"
+	// SendModeUnary 通过一元 SendMessage RPC 同步发送，不依赖消息流
+	SendModeUnary SendMode = "unary"
+	// SendModeStreamWithFallback 流可用时通过流发送，重连期间改用一元RPC发送
+	SendModeStreamWithFallback SendMode = "stream_with_fallback"
+)
+
+// SendMessageUnary 通过一元 SendMessage RPC 同步发送消息，返回服务端分配的消息ID和时间戳
+//
+// 只需要gRPC连接可用，不需要打开消息流，因此可以在 NewClientWithGRPC/NewClientWithConn
+// 创建后直接调用（如在无状态的HTTP处理器中）。UserId 为空时使用 Config.UserID，
+// 服务端返回的错误状态转换为 *StatusError。
+func (c *Client) SendMessageUnary(ctx context.Context, req *imv1.SendMessageRequest) (*imv1.SendMessageResponse, error) {
+	return c.sendUnary(ctx, req)
+}
+
+// sendUnary 执行一元 SendMessage 调用，只在读锁内取出 gRPC 客户端，请求期间不持有 c.mu
+func (c *Client) sendUnary(ctx context.Context, req *imv1.SendMessageRequest) (*imv1.SendMessageResponse, error) {
+	c.mu.RLock()
+	state, grpcClient := c.State(), c.client
+	c.mu.RUnlock()
+
+	if state == StateClosed {
+		return nil, ErrClosed
+	}
+	if grpcClient == nil {
+		return nil, ErrNotConnected
+	}
+
+	if req.UserId == "" {
+		req.UserId = c.config.UserID
+	}
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	ctx, finish := c.startRPC(ctx, "SendMessage", req.RoomId)
+	resp, err := invokeWithCredentials(c, ctx, func(ctx context.Context) (*imv1.SendMessageResponse, error) {
+		return grpcClient.SendMessage(ctx, req)
+	})
+	finish(err)
+	return resp, err
+}
+
+// sendMessageUnary 把流消息转换为一元请求发送
+//
+// 一元请求携带客户端生成的 MessageId，失败后写入发件箱重发时服务端据此去重；
+// 发送拦截器作用于消息副本，被拦截的消息不会发送，原消息仍可写入发件箱。
+func (c *Client) sendMessageUnary(ctx context.Context, msg *imv1.MessageRequest) (*imv1.SendMessageResponse, error) {
+	msg = proto.Clone(msg).(*imv1.MessageRequest)
+	if err := c.interceptSend(msg); err != nil {
+		return nil, err
+	}
+
+	return c.sendUnary(ctx, &imv1.SendMessageRequest{
+		MessageId: msg.MessageId,
+		UserId:    msg.UserId,
+		RoomId:    msg.RoomId,
+		Type:      msg.Type,
+		Content:   msg.Content,
+		Metadata:  msg.Metadata,
+	})
+}
+
+// useUnary 根据 SendMode 和当前状态判断本次发送是否使用一元RPC，调用方需持有 c.mu 读锁
+func (c *Client) useUnary() bool {
+	switch c.config.SendMode {
+	case SendModeUnary:
+		return true
+	case SendModeStreamWithFallback:
+		state := c.State()
+		return state != StateReady && state != StateClosed && c.client != nil
+	default:
+		return false
+	}
+}
+
+// routeMessage 按 SendMode 发送消息，使用一元RPC发送时返回服务端响应
+//
+// StreamWithFallback 模式下一元发送失败且配置了发件箱时，消息写入发件箱等待重连后补发。
+func (c *Client) routeMessage(ctx context.Context, msg *imv1.MessageRequest) (*imv1.SendMessageResponse, error) {
+	c.mu.RLock()
+	unary := c.useUnary()
+	c.mu.RUnlock()
+	if !unary {
+		return nil, c.sendMessage(ctx, msg)
+	}
+	resp, err := c.sendMessageUnary(ctx, msg)
+
+	if err != nil && c.config.SendMode == SendModeStreamWithFallback && c.config.Outbox != nil && IsRetryable(err) {
+		c.logger.Warn("一元发送失败，消息写入发件箱", "message_id", msg.MessageId, "error", err)
+		if err := c.config.Outbox.Enqueue(msg); err != nil {
+			return nil, fmt.Errorf("消息写入发件箱失败: %w", err)
+		}
+		c.notifyOutbox()
+		return nil, nil
+	}
+	return resp, err
+}
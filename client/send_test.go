@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+	"github.com/Dev-Umb/im-grpc-sdk/server"
+)
+
+// serverHistory 返回服务端保留的房间消息
+func serverHistory(t *testing.T, srv *imtest.Server, roomID string) []*imv1.MessageResponse {
+	t.Helper()
+
+	msgs, _, _, err := srv.Rooms().History(roomID, server.HistoryQuery{Forward: true, Limit: 100})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	return msgs
+}
+
+func TestStreamWithFallbackSendsUnaryWhileReconnecting(t *testing.T) {
+	srv := newTestServer(t)
+	config := newTestConfig(srv, "alice")
+	config.DefaultRoomID = "room1"
+	config.SendMode = SendModeStreamWithFallback
+	c := connectTestClient(t, config)
+
+	// 服务发现失败使重连无法完成，消息流已断开但旧连接仍然可用
+	config.Discovery.(*imtest.Discovery).FailDiscover(errors.New("discovery down"))
+	dropStreams(t, srv, 1)
+	waitState(t, c, StateReconnecting)
+
+	msg, err := c.newContentMessage("room1", imv1.MessageType_MESSAGE_TYPE_TEXT, &imv1.TextContent{Text: "fallback"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("重连期间发送失败: %v", err)
+	}
+	if resp == nil || resp.MessageId != msg.MessageId {
+		t.Fatalf("一元发送的响应 = %v, want message_id %s", resp, msg.MessageId)
+	}
+
+	config.Discovery.(*imtest.Discovery).FailDiscover(nil)
+	waitState(t, c, StateReady)
+}
+
+func TestSendMessageUnaryDeduplicatesRetries(t *testing.T) {
+	srv := newTestServer(t)
+	config := newTestConfig(srv, "alice")
+	config.DefaultRoomID = "room1"
+	config.SendMode = SendModeUnary
+	c := connectTestClient(t, config)
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+
+	req := &imv1.SendMessageRequest{
+		MessageId: "retry-1",
+		RoomId:    "room1",
+		Type:      imv1.MessageType_MESSAGE_TYPE_TEXT,
+		Content:   []byte("hello"),
+	}
+	first, err := c.SendMessageUnary(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SendMessageUnary: %v", err)
+	}
+	second, err := c.SendMessageUnary(context.Background(), req)
+	if err != nil {
+		t.Fatalf("重试 SendMessageUnary: %v", err)
+	}
+	if first.Sequence != second.Sequence || first.MessageId != "retry-1" {
+		t.Fatalf("重试的响应 = %v，第一次 = %v", second, first)
+	}
+
+	var count int
+	for _, msg := range serverHistory(t, srv, "room1") {
+		if msg.MessageId == "retry-1" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("消息 retry-1 发布了 %d 次, want 1", count)
+	}
+}
@@ -0,0 +1,392 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const (
+	defaultReorderTimeout = 200 * time.Millisecond
+	maxPendingSequences   = 1000
+	resumeFillAttempts    = 5
+	maxGapFetchDelay      = 30 * time.Second
+)
+
+// sequencedMessage 等待按序分发的消息，dispatch 为 false 的消息只推进序号（如自己发送的消息）
+type sequencedMessage struct {
+	msg      *imv1.MessageResponse
+	dispatch bool
+}
+
+// roomSequence 一个房间的消息序列状态
+type roomSequence struct {
+	last     uint64                      // 已按序分发的最后一条消息的序号
+	known    uint64                      // 已知服务端存在的最大序号
+	pending  map[uint64]sequencedMessage // 序号不连续、暂存等待缺失消息的消息
+	timer    *time.Timer
+	fetching bool
+	retries  int // 连续失败的拉取次数，用于计算重试间隔
+}
+
+// sequencer 按房间序号对消息排序、去重并检测缺失
+type sequencer struct {
+	rooms map[string]*roomSequence
+	mu    sync.Mutex
+}
+
+// newSequencer 创建消息序列
+func newSequencer() *sequencer {
+	return &sequencer{
+		rooms: make(map[string]*roomSequence),
+	}
+}
+
+// room 返回房间的序列状态，调用时需持有锁
+func (s *sequencer) room(roomID string) *roomSequence {
+	rs, exists := s.rooms[roomID]
+	if !exists {
+		rs = &roomSequence{pending: make(map[uint64]sequencedMessage)}
+		s.rooms[roomID] = rs
+	}
+	return rs
+}
+
+// push 加入一条消息，返回可以按序分发的消息；gap 表示仍有消息在等待缺失的序号。
+// 没有序号的消息直接返回，房间的第一条消息作为序列的起点
+func (s *sequencer) push(m sequencedMessage) (ready []sequencedMessage, gap bool) {
+	seq := m.msg.Sequence
+	if seq == 0 || m.msg.RoomId == "" {
+		return []sequencedMessage{m}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs, exists := s.rooms[m.msg.RoomId]
+	if !exists {
+		rs = s.room(m.msg.RoomId)
+		rs.last = seq - 1
+	}
+	if seq <= rs.last {
+		return nil, rs.known > rs.last
+	}
+	if _, dup := rs.pending[seq]; dup {
+		return nil, true
+	}
+
+	rs.pending[seq] = m
+	rs.known = max(rs.known, seq)
+	ready = rs.drain()
+	return ready, rs.known > rs.last
+}
+
+// drain 取出从 last+1 开始连续的消息
+func (rs *roomSequence) drain() []sequencedMessage {
+	var ready []sequencedMessage
+	for {
+		m, ok := rs.pending[rs.last+1]
+		if !ok {
+			return ready
+		}
+		delete(rs.pending, rs.last+1)
+		rs.last++
+		ready = append(ready, m)
+	}
+}
+
+// missing 返回缺失的序号范围 (from, to]，没有缺失时 ok 为 false
+func (rs *roomSequence) missing() (from, to uint64, ok bool) {
+	if len(rs.pending) == 0 {
+		if rs.known > rs.last {
+			return rs.last, rs.known, true
+		}
+		return 0, 0, false
+	}
+
+	to = rs.known
+	for seq := range rs.pending {
+		to = min(to, seq-1)
+	}
+	return rs.last, to, to > rs.last
+}
+
+// skip 放弃等待 to 及之前缺失的消息，按序返回暂存的消息
+func (s *sequencer) skip(roomID string, to uint64) []sequencedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs := s.room(roomID)
+	var skipped []uint64
+	for seq := range rs.pending {
+		if seq <= to {
+			skipped = append(skipped, seq)
+		}
+	}
+	slices.Sort(skipped)
+
+	ready := make([]sequencedMessage, 0, len(skipped))
+	for _, seq := range skipped {
+		ready = append(ready, rs.pending[seq])
+		delete(rs.pending, seq)
+	}
+	rs.last = max(rs.last, to)
+	return append(ready, rs.drain()...)
+}
+
+// join 记录加入房间时服务端最新的序号，房间已有序列状态时只更新已知的最大序号
+func (s *sequencer) join(roomID string, lastSequence uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs, exists := s.rooms[roomID]
+	if !exists {
+		rs = s.room(roomID)
+		rs.last = lastSequence
+	}
+	rs.known = max(rs.known, lastSequence)
+	return rs.known > rs.last
+}
+
+// resume 把房间的序列恢复到 seq，丢弃 seq 及之前暂存的消息
+func (s *sequencer) resume(roomID string, seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rs := s.room(roomID)
+	for pending := range rs.pending {
+		if pending <= seq {
+			delete(rs.pending, pending)
+		}
+	}
+	rs.last = seq
+	rs.known = max(rs.known, seq)
+}
+
+// remove 移除房间的序列状态
+func (s *sequencer) remove(roomID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rs, exists := s.rooms[roomID]; exists {
+		if rs.timer != nil {
+			rs.timer.Stop()
+		}
+		delete(s.rooms, roomID)
+	}
+}
+
+// last 返回房间已按序分发的最后一条消息的序号
+func (s *sequencer) last(roomID string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rs, exists := s.rooms[roomID]; exists {
+		return rs.last
+	}
+	return 0
+}
+
+// snapshot 返回所有房间已按序分发的最后一条消息的序号
+func (s *sequencer) snapshot() map[string]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rooms := make(map[string]uint64, len(s.rooms))
+	for roomID, rs := range s.rooms {
+		rooms[roomID] = rs.last
+	}
+	return rooms
+}
+
+// LastSequence 返回房间内已按序分发的最后一条消息的序号，没有记录时为0
+func (c *Client) LastSequence(roomID string) uint64 {
+	return c.sequences.last(roomID)
+}
+
+// LastSequences 返回每个房间的 LastSequence，可以在重启前保存，之后通过 ResumeSequence 恢复
+func (c *Client) LastSequences() map[string]uint64 {
+	return c.sequences.snapshot()
+}
+
+// ResumeSequence 从保存的序号恢复房间的消息序列，之后的消息从 seq+1 开始按序分发
+//
+// 应在 Connect 之前调用，连接成功后自动通过 GetHistory 拉取 seq 之后错过的消息；
+// 已连接时立即开始拉取。
+func (c *Client) ResumeSequence(roomID string, seq uint64) {
+	c.sequences.resume(roomID, seq)
+
+	if c.State() == StateReady {
+		go c.fillResumed(roomID)
+	}
+}
+
+// fillResumed 补齐通过 ResumeSequence 恢复的房间。
+// DefaultRoomID 由服务端在消息流建立后加入，尚未成为房间成员时等待 ReorderTimeout 后重试
+func (c *Client) fillResumed(roomID string) {
+	for attempt := 1; ; attempt++ {
+		err := c.fillSequenceGap(roomID, c.sequences.last(roomID))
+		var statusErr *StatusError
+		if err == nil || attempt >= resumeFillAttempts ||
+			!errors.As(err, &statusErr) || statusErr.Code != codes.FailedPrecondition {
+			c.reportGapFill(roomID, err)
+			return
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(c.reorderTimeout()):
+		}
+	}
+}
+
+// deliver 按房间序号排序后分发消息，序号不连续时暂存并在 ReorderTimeout 后拉取缺失的消息
+func (c *Client) deliver(msg *imv1.MessageResponse, dispatch bool) {
+	c.dispatchMu.Lock()
+	ready, gap := c.sequences.push(sequencedMessage{msg: msg, dispatch: dispatch})
+	c.dispatchSequenced(ready)
+	c.dispatchMu.Unlock()
+
+	if gap {
+		c.scheduleGapFetch(msg.RoomId)
+	}
+}
+
+// dispatchSequenced 分发已经按序排列的消息，调用时需持有 dispatchMu
+func (c *Client) dispatchSequenced(ready []sequencedMessage) {
+	for _, m := range ready {
+		if m.dispatch {
+			c.dispatch(m.msg)
+		}
+	}
+}
+
+// scheduleGapFetch 等待 ReorderTimeout 后拉取房间缺失的消息，已在等待或拉取中时忽略；
+// 拉取连续失败时等待时间逐次翻倍，最长30秒
+func (c *Client) scheduleGapFetch(roomID string) {
+	c.sequences.mu.Lock()
+	defer c.sequences.mu.Unlock()
+
+	rs, exists := c.sequences.rooms[roomID]
+	if !exists || rs.timer != nil || rs.fetching {
+		return
+	}
+	delay := c.reorderTimeout()
+	for i := 0; i < rs.retries && delay < maxGapFetchDelay; i++ {
+		delay *= 2
+	}
+	rs.timer = time.AfterFunc(min(delay, maxGapFetchDelay), func() {
+		c.fetchMissing(roomID)
+	})
+}
+
+// fetchMissing 通过 GetHistory 拉取缺失的消息并按序分发
+//
+// 拉取到的范围内仍然缺失的消息（已超出服务端保留的历史）视为丢失，通过 OnError 报告 ErrMessagesLost；
+// 可重试的错误按退避间隔重试，暂存的消息过多时不再等待。未连接时不拉取，
+// 重连成功后由 fillGaps 从最后分发的序号补齐。
+func (c *Client) fetchMissing(roomID string) {
+	c.sequences.mu.Lock()
+	rs, exists := c.sequences.rooms[roomID]
+	if !exists {
+		c.sequences.mu.Unlock()
+		return
+	}
+	rs.timer = nil
+	from, to, ok := rs.missing()
+	pending := len(rs.pending)
+	if !ok || c.State() != StateReady {
+		c.sequences.mu.Unlock()
+		return
+	}
+	rs.fetching = true
+	c.sequences.mu.Unlock()
+
+	msgs, err := c.fetchSequences(roomID, from, to)
+
+	c.dispatchMu.Lock()
+	for _, msg := range msgs {
+		ready, _ := c.sequences.push(sequencedMessage{msg: msg, dispatch: msg.FromUserId != c.config.UserID})
+		c.dispatchSequenced(ready)
+	}
+	// 断线导致的失败不重试也不跳过，等待重连后补齐
+	disconnected := err != nil && c.State() != StateReady
+	retry := err != nil && IsRetryable(err) && pending < maxPendingSequences
+	var lost bool
+	if !retry && !disconnected && c.sequences.last(roomID) < to {
+		lost = true
+		from = c.sequences.last(roomID)
+		c.dispatchSequenced(c.sequences.skip(roomID, to))
+	}
+	c.dispatchMu.Unlock()
+
+	c.sequences.mu.Lock()
+	rs.fetching = false
+	if retry && !disconnected {
+		rs.retries++
+	} else {
+		rs.retries = 0
+	}
+	_, _, again := rs.missing()
+	c.sequences.mu.Unlock()
+
+	if disconnected {
+		c.logger.Debug("连接已断开，重连后补齐缺失的消息", "room_id", roomID, "from", from+1, "to", to)
+		return
+	}
+	if err != nil {
+		c.logger.Warn("拉取缺失的消息失败", "room_id", roomID, "from", from+1, "to", to, "error", err)
+	}
+	if lost {
+		c.logger.Warn("房间消息不连续，跳过缺失的消息", "room_id", roomID, "from", from+1, "to", to)
+		if c.config.OnError != nil {
+			c.config.OnError(fmt.Errorf("房间 %s 的消息 %d-%d: %w", roomID, from+1, to, ErrMessagesLost))
+		}
+	}
+	if again {
+		c.scheduleGapFetch(roomID)
+	}
+}
+
+// fetchSequences 拉取序号在 (from, to] 范围内的消息，超过 GapFillLimit 时只拉取最新的部分
+func (c *Client) fetchSequences(roomID string, from, to uint64) ([]*imv1.MessageResponse, error) {
+	limit := uint64(c.gapFillLimit())
+	if to-from > limit {
+		from = to - limit
+	}
+
+	it := c.FetchHistory(c.ctx, roomID, HistoryOptions{
+		Direction: imv1.HistoryDirection_HISTORY_DIRECTION_FORWARD,
+		Cursor:    strconv.FormatUint(from, 10),
+		PageSize:  gapFillPageSize,
+		Limit:     int(to - from),
+	})
+
+	var msgs []*imv1.MessageResponse
+	for it.Next() {
+		if msg := it.Message(); msg.Sequence > from && msg.Sequence <= to {
+			msgs = append(msgs, msg)
+		}
+	}
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].Sequence < msgs[j].Sequence
+	})
+	return msgs, it.Err()
+}
+
+// reorderTimeout 消息序号不连续时等待缺失消息到达的时间
+func (c *Client) reorderTimeout() time.Duration {
+	if c.config.ReorderTimeout > 0 {
+		return c.config.ReorderTimeout
+	}
+	return defaultReorderTimeout
+}
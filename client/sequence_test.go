@@ -0,0 +1,260 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// sequenceTest 加入 room1 的客户端，收到的文本消息和 OnError 报告的错误分别写入 messages 和 errs
+type sequenceTest struct {
+	srv      *imtest.Server
+	client   *Client
+	messages chan *imv1.MessageResponse
+	errs     chan error
+	base     uint64 // alice 加入房间后房间的最新序号
+}
+
+// newSequenceTest 创建已有一条消息的 room1，alice 连接后加入该房间
+func newSequenceTest(t *testing.T, configure func(*Config)) *sequenceTest {
+	t.Helper()
+
+	st := &sequenceTest{
+		srv:      newTestServer(t),
+		messages: make(chan *imv1.MessageResponse, 16),
+		errs:     make(chan error, 16),
+	}
+	if _, _, err := st.srv.Rooms().Join("room1", "bob", nil); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	publishTexts(t, st.srv, "room1", "existing")
+
+	config := newTestConfig(st.srv, "alice")
+	config.OnMessage = func(msg *imv1.MessageResponse) {
+		if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+			st.messages <- msg
+		}
+	}
+	config.OnError = func(err error) { st.errs <- err }
+	if configure != nil {
+		configure(config)
+	}
+	st.client = connectTestClient(t, config)
+
+	// 等待服务端登记消息流后再加入房间，确保收到加入房间的系统消息
+	waitSession(t, st.srv, "alice")
+	if _, err := st.client.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	info, _, err := st.srv.Rooms().Room("room1")
+	if err != nil {
+		t.Fatalf("Room: %v", err)
+	}
+	st.base = info.LastSequence
+	deadline := time.Now().Add(testTimeout)
+	for st.client.LastSequence("room1") != st.base {
+		if time.Now().After(deadline) {
+			t.Fatalf("LastSequence = %d, want %d", st.client.LastSequence("room1"), st.base)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return st
+}
+
+// publishMissed 在 alice 不是房间成员期间发布消息，alice 只能通过 GetHistory 获取
+func (st *sequenceTest) publishMissed(t *testing.T, texts ...string) {
+	t.Helper()
+
+	if err := st.srv.Rooms().Leave("room1", "alice"); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+	publishTexts(t, st.srv, "room1", texts...)
+	if _, _, err := st.srv.Rooms().Join("room1", "alice", nil); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+}
+
+// push 直接向 alice 的消息流推送序号为 base+offset 的消息，服务端历史中不存在该消息
+func (st *sequenceTest) push(t *testing.T, text string, offset uint64) {
+	t.Helper()
+
+	n := st.srv.Push("alice", &imv1.MessageResponse{
+		MessageId:  text,
+		Type:       imv1.MessageType_MESSAGE_TYPE_TEXT,
+		RoomId:     "room1",
+		FromUserId: "bob",
+		Content:    []byte(text),
+		Sequence:   st.base + offset,
+	})
+	if n != 1 {
+		t.Fatalf("Push = %d, want 1", n)
+	}
+}
+
+// expectLastSequence 检查 alice 在 room1 已按序分发的最后一条消息的序号为 base+offset
+func (st *sequenceTest) expectLastSequence(t *testing.T, offset uint64) {
+	t.Helper()
+
+	if got, want := st.client.LastSequence("room1"), st.base+offset; got != want {
+		t.Fatalf("LastSequence = %d, want %d", got, want)
+	}
+}
+
+// expectMessages 依次等待指定内容的消息
+func (st *sequenceTest) expectMessages(t *testing.T, want ...string) {
+	t.Helper()
+
+	for _, text := range want {
+		select {
+		case msg := <-st.messages:
+			if string(msg.Content) != text {
+				t.Fatalf("收到 %q (sequence %d), want %q", msg.Content, msg.Sequence, text)
+			}
+		case <-time.After(testTimeout):
+			t.Fatalf("等待消息 %q 超时", text)
+		}
+	}
+}
+
+// expectLost 等待 OnError 报告 ErrMessagesLost
+func (st *sequenceTest) expectLost(t *testing.T) {
+	t.Helper()
+
+	timeout := time.After(testTimeout)
+	for {
+		select {
+		case err := <-st.errs:
+			if errors.Is(err, ErrMessagesLost) {
+				return
+			}
+		case <-timeout:
+			t.Fatal("等待 ErrMessagesLost 超时")
+		}
+	}
+}
+
+// countHistoryCalls 返回记录 GetHistory 调用时间的拦截器，前 fail 次调用返回 Unavailable
+func countHistoryCalls(fail int) (grpc.UnaryClientInterceptor, func() []time.Time) {
+	var (
+		mu    sync.Mutex
+		calls []time.Time
+	)
+	interceptor := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if method != imv1.IMService_GetHistory_FullMethodName {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		mu.Lock()
+		calls = append(calls, time.Now())
+		n := len(calls)
+		mu.Unlock()
+		if n <= fail {
+			return status.Error(codes.Unavailable, "history unavailable")
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	return interceptor, func() []time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(calls)
+	}
+}
+
+func TestSequenceReordersOutOfOrderMessages(t *testing.T) {
+	st := newSequenceTest(t, func(config *Config) {
+		config.ReorderTimeout = time.Minute
+	})
+
+	st.push(t, "second", 2)
+	st.push(t, "first", 1)
+	st.expectMessages(t, "first", "second")
+	st.expectLastSequence(t, 2)
+}
+
+func TestSequenceFillsGapFromHistory(t *testing.T) {
+	interceptor, calls := countHistoryCalls(0)
+	st := newSequenceTest(t, func(config *Config) {
+		config.ReorderTimeout = 20 * time.Millisecond
+		config.UnaryInterceptors = []grpc.UnaryClientInterceptor{interceptor}
+	})
+
+	st.publishMissed(t, "missed-1", "missed-2")
+	publishTexts(t, st.srv, "room1", "latest")
+	st.expectMessages(t, "missed-1", "missed-2", "latest")
+	if n := len(calls()); n == 0 {
+		t.Fatal("缺失的消息应通过 GetHistory 补齐")
+	}
+	st.expectLastSequence(t, 3)
+}
+
+func TestSequenceReportsUnfillableGap(t *testing.T) {
+	st := newSequenceTest(t, func(config *Config) {
+		config.ReorderTimeout = 20 * time.Millisecond
+	})
+
+	// 服务端历史中没有之前的三条消息
+	st.push(t, "latest", 4)
+	st.expectLost(t)
+	st.expectMessages(t, "latest")
+
+	// 跳过缺失的消息后继续按序分发
+	st.push(t, "next", 5)
+	st.expectMessages(t, "next")
+	st.expectLastSequence(t, 5)
+}
+
+func TestSequenceGapFillLimit(t *testing.T) {
+	st := newSequenceTest(t, func(config *Config) {
+		config.ReorderTimeout = 20 * time.Millisecond
+		config.GapFillLimit = 2
+	})
+
+	// 缺失四条消息，只补齐最新的两条，其余视为丢失
+	st.publishMissed(t, "missed-1", "missed-2", "missed-3", "missed-4")
+	publishTexts(t, st.srv, "room1", "latest")
+	st.expectLost(t)
+	st.expectMessages(t, "missed-3", "missed-4", "latest")
+	st.expectLastSequence(t, 5)
+}
+
+func TestSequenceGapFetchBackoff(t *testing.T) {
+	const reorderTimeout = 20 * time.Millisecond
+	interceptor, calls := countHistoryCalls(3)
+	st := newSequenceTest(t, func(config *Config) {
+		config.ReorderTimeout = reorderTimeout
+		config.UnaryInterceptors = []grpc.UnaryClientInterceptor{interceptor}
+	})
+
+	st.publishMissed(t, "missed")
+	publishTexts(t, st.srv, "room1", "latest")
+	st.expectMessages(t, "missed", "latest")
+
+	// 可重试的失败不跳过缺失的消息，重试间隔从 ReorderTimeout 起逐次翻倍
+	got := calls()
+	if len(got) != 4 {
+		t.Fatalf("GetHistory 调用 %d 次, want 4", len(got))
+	}
+	want := reorderTimeout
+	for i := 1; i < len(got); i++ {
+		want *= 2
+		if interval := got[i].Sub(got[i-1]); interval < want {
+			t.Fatalf("第%d次重试间隔 %v, want >= %v", i, interval, want)
+		}
+	}
+	select {
+	case err := <-st.errs:
+		if errors.Is(err, ErrMessagesLost) {
+			t.Fatalf("可重试的失败不应报告消息丢失: %v", err)
+		}
+	default:
+	}
+}
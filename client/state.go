@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// State 客户端连接状态
+type State int
+
+const (
+	// StateIdle 未连接（尚未调用 Connect，或已放弃重连）
+	StateIdle State = iota
+	// StateConnecting 正在建立首次连接
+	StateConnecting
+	// StateReady 连接可用
+	StateReady
+	// StateReconnecting 连接断开，正在重连
+	StateReconnecting
+	// StateDraining 正在断开，等待已排队的消息发送完成
+	StateDraining
+	// StateClosed 已断开，客户端不可再使用
+	StateClosed
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "Idle"
+	case StateConnecting:
+		return "Connecting"
+	case StateReady:
+		return "Ready"
+	case StateReconnecting:
+		return "Reconnecting"
+	case StateDraining:
+		return "Draining"
+	case StateClosed:
+		return "Closed"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// StateChange 状态变化事件
+type StateChange struct {
+	From State
+	To   State
+}
+
+// stateSubscriberBuffer 状态订阅channel的缓冲大小
+const stateSubscriberBuffer = 16
+
+// stateTracker 维护连接状态并通知等待者和订阅者
+type stateTracker struct {
+	state       State
+	changed     chan struct{} // 每次状态变化时关闭并替换，用于唤醒等待者
+	subscribers map[int]chan StateChange
+	nextID      int
+	mu          sync.Mutex
+}
+
+// newStateTracker 创建状态跟踪器
+func newStateTracker() *stateTracker {
+	return &stateTracker{
+		state:       StateIdle,
+		changed:     make(chan struct{}),
+		subscribers: make(map[int]chan StateChange),
+	}
+}
+
+// current 返回当前状态及状态变化时会关闭的channel
+func (t *stateTracker) current() (State, <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state, t.changed
+}
+
+// set 切换状态，返回状态变化事件及状态是否发生了变化
+func (t *stateTracker) set(to State) (StateChange, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	change := StateChange{From: t.state, To: to}
+	if t.state == to || t.state == StateClosed {
+		return change, false
+	}
+
+	t.state = to
+	close(t.changed)
+	t.changed = make(chan struct{})
+
+	for _, ch := range t.subscribers {
+		// 订阅者消费过慢时丢弃事件，可通过 State() 获取最新状态
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+
+	// 关闭后不会再有状态变化，结束所有订阅
+	if to == StateClosed {
+		for id, ch := range t.subscribers {
+			close(ch)
+			delete(t.subscribers, id)
+		}
+	}
+
+	return change, true
+}
+
+// subscribe 订阅状态变化
+func (t *stateTracker) subscribe() (<-chan StateChange, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ch := make(chan StateChange, stateSubscriberBuffer)
+	if t.state == StateClosed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	id := t.nextID
+	t.nextID++
+	t.subscribers[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if _, exists := t.subscribers[id]; exists {
+				close(ch)
+				delete(t.subscribers, id)
+			}
+		})
+	}
+}
+
+// State 返回当前连接状态
+func (c *Client) State() State {
+	state, _ := c.state.current()
+	return state
+}
+
+// WaitForState 阻塞直到客户端进入指定状态、ctx结束或客户端关闭
+func (c *Client) WaitForState(ctx context.Context, state State) error {
+	for {
+		current, changed := c.state.current()
+		if current == state {
+			return nil
+		}
+		if current == StateClosed {
+			return ErrClosed
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SubscribeState 订阅状态变化，返回事件channel和取消订阅函数；
+// 客户端关闭后channel会被关闭
+func (c *Client) SubscribeState() (<-chan StateChange, func()) {
+	return c.state.subscribe()
+}
+
+// setState 切换状态，不会触发用户回调，可以在持有 c.mu 时调用
+func (c *Client) setState(to State) (StateChange, bool) {
+	return c.state.set(to)
+}
+
+// fireStateCallbacks 根据状态变化触发 OnConnect/OnDisconnect 回调，调用时不能持有 c.mu
+//
+// 每次进入 Ready 触发 OnConnect，每次离开 Ready 触发 OnDisconnect，
+// 首次连接与重连的行为一致。
+func (c *Client) fireStateCallbacks(change StateChange, changed bool, err error) {
+	if !changed {
+		return
+	}
+
+	switch {
+	case change.To == StateReady:
+		// 连接可用后补发发件箱中积压的消息
+		c.notifyOutbox()
+		if c.config.OnConnect != nil {
+			c.config.OnConnect()
+		}
+	case change.From == StateReady:
+		if c.config.OnDisconnect != nil {
+			c.config.OnDisconnect(err)
+		}
+	}
+}
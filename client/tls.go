@@ -0,0 +1,246 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TLSConfig SDK自管理连接的TLS配置
+//
+// 证书文件在每次握手时检查修改时间，变化后自动重新加载：客户端证书在下一次握手生效，
+// CA 在下一次（重）连接生效。重连到负载均衡器选出的其他实例时使用同一份配置。
+type TLSConfig struct {
+	// CA证书，用于校验服务端证书，都为空时使用系统根证书
+	CAFile string `json:"ca_file"`
+	CAPEM  []byte `json:"-"`
+
+	// 客户端证书和私钥，配置后启用双向TLS
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+	CertPEM  []byte `json:"-"`
+	KeyPEM   []byte `json:"-"`
+
+	// ServerName 覆盖校验服务端证书时使用的主机名，服务发现返回IP地址时需要设置
+	ServerName string `json:"server_name"`
+
+	// InsecureSkipVerify 跳过服务端证书校验，仅用于测试
+	InsecureSkipVerify bool `json:"insecure_skip_verify"`
+
+	// MinVersion 最低TLS版本，为0时使用TLS 1.2
+	MinVersion uint16 `json:"min_version"`
+}
+
+// validate 校验TLS配置
+func (tc *TLSConfig) validate() error {
+	if (tc.CertFile == "") != (tc.KeyFile == "") {
+		return fmt.Errorf("TLS客户端证书和私钥文件必须同时配置")
+	}
+	if (len(tc.CertPEM) == 0) != (len(tc.KeyPEM) == 0) {
+		return fmt.Errorf("TLS客户端证书和私钥PEM必须同时配置")
+	}
+	if tc.CertFile != "" && len(tc.CertPEM) > 0 {
+		return fmt.Errorf("TLS客户端证书不能同时配置文件和PEM")
+	}
+	if tc.CAFile != "" && len(tc.CAPEM) > 0 {
+		return fmt.Errorf("TLS CA证书不能同时配置文件和PEM")
+	}
+	return nil
+}
+
+// fileVersion 文件版本，用于判断证书文件是否变化
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile 读取文件版本
+func statFile(path string) (fileVersion, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileVersion{}, err
+	}
+	return fileVersion{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// tlsReloader 加载TLS证书并在文件变化时重新加载
+type tlsReloader struct {
+	config *TLSConfig
+	logger Logger
+
+	rootCAs   *x509.CertPool
+	caVersion fileVersion
+
+	cert        *tls.Certificate
+	certVersion fileVersion
+	keyVersion  fileVersion
+
+	mu sync.Mutex
+}
+
+// newTLSReloader 创建证书加载器并完成首次加载
+func newTLSReloader(config *TLSConfig, logger Logger) (*tlsReloader, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	r := &tlsReloader{config: config, logger: logger}
+
+	if len(config.CAPEM) > 0 {
+		pool, err := parseCAPool(config.CAPEM)
+		if err != nil {
+			return nil, err
+		}
+		r.rootCAs = pool
+	}
+	if len(config.CertPEM) > 0 {
+		cert, err := tls.X509KeyPair(config.CertPEM, config.KeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("解析TLS客户端证书失败: %w", err)
+		}
+		r.cert = &cert
+	}
+
+	if _, err := r.loadCA(); err != nil {
+		return nil, err
+	}
+	if _, err := r.loadCertificate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// transportCredentials 使用当前证书构建gRPC传输凭证
+func (r *tlsReloader) transportCredentials() credentials.TransportCredentials {
+	rootCAs, err := r.loadCA()
+	if err != nil {
+		r.logger.Warn("重新加载TLS CA证书失败，继续使用旧证书", "ca_file", r.config.CAFile, "error", err)
+	}
+
+	minVersion := r.config.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:            rootCAs,
+		ServerName:         r.config.ServerName,
+		InsecureSkipVerify: r.config.InsecureSkipVerify,
+		MinVersion:         minVersion,
+	}
+	if r.hasClientCertificate() {
+		tlsConfig.GetClientCertificate = r.getClientCertificate
+	}
+
+	return credentials.NewTLS(tlsConfig)
+}
+
+// hasClientCertificate 是否配置了客户端证书
+func (r *tlsReloader) hasClientCertificate() bool {
+	return r.config.CertFile != "" || len(r.config.CertPEM) > 0
+}
+
+// getClientCertificate 握手时返回客户端证书，文件变化时先重新加载
+func (r *tlsReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cert, err := r.loadCertificate()
+	if err != nil {
+		r.logger.Warn("重新加载TLS客户端证书失败，继续使用旧证书", "cert_file", r.config.CertFile, "error", err)
+	}
+	return cert, nil
+}
+
+// loadCA 返回CA证书池，CA文件变化时重新加载
+func (r *tlsReloader) loadCA() (*x509.CertPool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.config.CAFile == "" {
+		return r.rootCAs, nil
+	}
+
+	version, err := statFile(r.config.CAFile)
+	if err != nil {
+		return r.rootCAs, fmt.Errorf("读取TLS CA证书失败: %w", err)
+	}
+	if r.rootCAs != nil && version == r.caVersion {
+		return r.rootCAs, nil
+	}
+
+	data, err := os.ReadFile(r.config.CAFile)
+	if err != nil {
+		return r.rootCAs, fmt.Errorf("读取TLS CA证书失败: %w", err)
+	}
+	pool, err := parseCAPool(data)
+	if err != nil {
+		return r.rootCAs, err
+	}
+
+	r.rootCAs = pool
+	r.caVersion = version
+	return pool, nil
+}
+
+// loadCertificate 返回客户端证书，证书或私钥文件变化时重新加载
+func (r *tlsReloader) loadCertificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.config.CertFile == "" {
+		return r.cert, nil
+	}
+
+	certVersion, err := statFile(r.config.CertFile)
+	if err != nil {
+		return r.cert, fmt.Errorf("读取TLS客户端证书失败: %w", err)
+	}
+	keyVersion, err := statFile(r.config.KeyFile)
+	if err != nil {
+		return r.cert, fmt.Errorf("读取TLS客户端私钥失败: %w", err)
+	}
+	if r.cert != nil && certVersion == r.certVersion && keyVersion == r.keyVersion {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return r.cert, fmt.Errorf("加载TLS客户端证书失败: %w", err)
+	}
+
+	r.cert = &cert
+	r.certVersion = certVersion
+	r.keyVersion = keyVersion
+	return r.cert, nil
+}
+
+// parseCAPool 解析PEM格式的CA证书
+func parseCAPool(data []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("解析TLS CA证书失败: 未找到有效的PEM证书")
+	}
+	return pool, nil
+}
+
+// transportCredentials 返回建立连接使用的传输凭证，未配置TLS时使用明文连接；
+// 首次调用时加载证书，调用方需持有 c.mu 写锁
+func (c *Client) transportCredentials() (credentials.TransportCredentials, error) {
+	if c.config.TLS == nil {
+		return insecure.NewCredentials(), nil
+	}
+
+	if c.tls == nil {
+		reloader, err := newTLSReloader(c.config.TLS, c.logger)
+		if err != nil {
+			return nil, fmt.Errorf("加载TLS配置失败: %w", err)
+		}
+		c.tls = reloader
+	}
+
+	return c.tls.transportCredentials(), nil
+}
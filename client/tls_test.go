@@ -0,0 +1,326 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Dev-Umb/im-grpc-sdk/discovery"
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	"github.com/Dev-Umb/im-grpc-sdk/server"
+)
+
+// testCA 测试用的CA，签发服务端和客户端证书
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCA 创建自签名CA
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue 签发证书，返回PEM格式的证书和私钥
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("rand.Int: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// tlsTestServer 要求客户端证书的TLS服务端，记录每次握手的客户端证书
+type tlsTestServer struct {
+	listener *bufconn.Listener
+	conns    []net.Conn
+	peers    []string
+	mu       sync.Mutex
+}
+
+// newTLSTestServer 启动由 ca 签发证书、校验 ca 签发的客户端证书的服务端
+func newTLSTestServer(t *testing.T, ca *testCA) *tlsTestServer {
+	t.Helper()
+
+	certPEM, keyPEM := ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("X509KeyPair: %v", err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	s := &tlsTestServer{listener: bufconn.Listen(1024 * 1024)}
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			s.mu.Lock()
+			s.peers = append(s.peers, cs.PeerCertificates[0].Subject.CommonName)
+			s.mu.Unlock()
+			return nil
+		},
+	})
+	grpcServer := grpc.NewServer(grpc.Creds(creds))
+	server.NewServer(nil).Register(grpcServer)
+	go grpcServer.Serve(s)
+	t.Cleanup(grpcServer.Stop)
+	return s
+}
+
+// Accept 实现 net.Listener，记录连接以便断开
+func (s *tlsTestServer) Accept() (net.Conn, error) {
+	conn, err := s.listener.Accept()
+	if err == nil {
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+	}
+	return conn, err
+}
+
+// Close 实现 net.Listener
+func (s *tlsTestServer) Close() error {
+	return s.listener.Close()
+}
+
+// Addr 实现 net.Listener
+func (s *tlsTestServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// closeConns 断开所有连接，客户端重连时重新握手
+func (s *tlsTestServer) closeConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// lastPeer 返回最近一次握手的客户端证书名称
+func (s *tlsTestServer) lastPeer() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.peers) == 0 {
+		return ""
+	}
+	return s.peers[len(s.peers)-1]
+}
+
+// newTLSTestConfig 返回通过TLS连接到 s 的配置
+func newTLSTestConfig(s *tlsTestServer, tlsConfig *TLSConfig) *Config {
+	disc := imtest.NewDiscovery()
+	disc.SetServices("im-service", &discovery.ServiceInfo{ID: "tls", Name: "im-service", Address: "localhost", Port: 443})
+
+	config := DefaultConfig()
+	config.UserID = "alice"
+	config.Discovery = disc
+	config.Dialer = func(ctx context.Context, address string) (net.Conn, error) {
+		return s.listener.DialContext(ctx)
+	}
+	config.ConnectTimeout = testTimeout
+	config.RequestTimeout = testTimeout
+	config.Backoff = NewConstantBackoff(50*time.Millisecond, 0)
+	config.TLS = tlsConfig
+	return config
+}
+
+// writeFile 写入文件并把修改时间设为 modTime，确保重新加载时能检测到变化
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+}
+
+func TestTLSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TLSConfig
+		wantErr bool
+	}{
+		{"只配置CA文件", TLSConfig{CAFile: "ca.pem"}, false},
+		{"证书和私钥文件", TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem"}, false},
+		{"只有证书文件", TLSConfig{CertFile: "cert.pem"}, true},
+		{"只有私钥PEM", TLSConfig{KeyPEM: []byte("key")}, true},
+		{"证书同时配置文件和PEM", TLSConfig{CertFile: "cert.pem", KeyFile: "key.pem", CertPEM: []byte("cert"), KeyPEM: []byte("key")}, true},
+		{"CA同时配置文件和PEM", TLSConfig{CAFile: "ca.pem", CAPEM: []byte("ca")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMutualTLSWithPEM(t *testing.T) {
+	ca := newTestCA(t)
+	srv := newTLSTestServer(t, ca)
+	certPEM, keyPEM := ca.issue(t, "client-a", x509.ExtKeyUsageClientAuth)
+
+	c := connectTestClient(t, newTLSTestConfig(srv, &TLSConfig{
+		CAPEM:      ca.pem,
+		CertPEM:    certPEM,
+		KeyPEM:     keyPEM,
+		ServerName: "localhost",
+	}))
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	if peer := srv.lastPeer(); peer != "client-a" {
+		t.Fatalf("客户端证书 = %q, want client-a", peer)
+	}
+}
+
+func TestTLSRejectsUnknownServerCA(t *testing.T) {
+	srv := newTLSTestServer(t, newTestCA(t))
+	other := newTestCA(t)
+	certPEM, keyPEM := other.issue(t, "client-a", x509.ExtKeyUsageClientAuth)
+
+	config := newTLSTestConfig(srv, &TLSConfig{
+		CAPEM:      other.pem,
+		CertPEM:    certPEM,
+		KeyPEM:     keyPEM,
+		ServerName: "localhost",
+	})
+	config.ConnectTimeout = 500 * time.Millisecond
+	c, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Disconnect()
+	if err := c.Connect(); err == nil {
+		t.Fatal("服务端证书不是由配置的CA签发，连接应失败")
+	}
+}
+
+func TestTLSReloadsClientCertificate(t *testing.T) {
+	ca := newTestCA(t)
+	srv := newTLSTestServer(t, ca)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, caFile, ca.pem, modTime)
+	certPEM, keyPEM := ca.issue(t, "client-a", x509.ExtKeyUsageClientAuth)
+	writeFile(t, certFile, certPEM, modTime)
+	writeFile(t, keyFile, keyPEM, modTime)
+
+	c := connectTestClient(t, newTLSTestConfig(srv, &TLSConfig{
+		CAFile:     caFile,
+		CertFile:   certFile,
+		KeyFile:    keyFile,
+		ServerName: "localhost",
+	}))
+	// 收到服务端的消息后消息流才绑定在当前连接上，断开连接时不会被透明重试到新连接
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	delivery, err := c.SendTextMessageWithAck("room1", "hello", 0)
+	if err != nil {
+		t.Fatalf("SendTextMessageWithAck: %v", err)
+	}
+	if _, err := waitDelivery(t, delivery); err != nil {
+		t.Fatalf("Delivery: %v", err)
+	}
+	if peer := srv.lastPeer(); peer != "client-a" {
+		t.Fatalf("客户端证书 = %q, want client-a", peer)
+	}
+
+	// 替换证书文件后断开连接，重连时的握手使用新证书
+	certPEM, keyPEM = ca.issue(t, "client-b", x509.ExtKeyUsageClientAuth)
+	writeFile(t, certFile, certPEM, time.Now())
+	writeFile(t, keyFile, keyPEM, time.Now())
+
+	changes, unsubscribe := c.SubscribeState()
+	defer unsubscribe()
+	srv.closeConns()
+	expectStateChanges(t, changes,
+		StateChange{From: StateReady, To: StateReconnecting},
+		StateChange{From: StateReconnecting, To: StateReady},
+	)
+	if peer := srv.lastPeer(); peer != "client-b" {
+		t.Fatalf("重新加载后的客户端证书 = %q, want client-b", peer)
+	}
+	if _, err := c.GetRoomInfo("room1"); err != nil {
+		t.Fatalf("GetRoomInfo: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// tracerName OpenTelemetry instrumentation 名称
+const tracerName = "github.com/Dev-Umb/im-grpc-sdk/client"
+
+// tracer 返回配置的 Tracer，未配置 TracerProvider 时返回nil
+func (c *Client) tracer() trace.Tracer {
+	if c.config.TracerProvider == nil {
+		return nil
+	}
+	return c.config.TracerProvider.Tracer(tracerName)
+}
+
+// propagator 返回跨消息传递追踪上下文的 propagator，默认使用 W3C Trace Context
+func (c *Client) propagator() propagation.TextMapPropagator {
+	if c.config.Propagator != nil {
+		return c.config.Propagator
+	}
+	return propagation.TraceContext{}
+}
+
+// startSpan 为RPC调用创建span，未启用追踪时返回空span
+func (c *Client) startSpan(ctx context.Context, method, roomID string) (context.Context, trace.Span) {
+	tracer := c.tracer()
+	if tracer == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	return tracer.Start(ctx, "im."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", "im.v1.IMService"),
+			attribute.String("rpc.method", method),
+			attribute.String("im.user_id", c.config.UserID),
+			attribute.String("im.room_id", roomID),
+		),
+	)
+}
+
+// startSendSpan 为发送的消息创建 producer span，并把追踪上下文写入消息 metadata
+func (c *Client) startSendSpan(ctx context.Context, msg *imv1.MessageRequest) trace.Span {
+	tracer := c.tracer()
+	if tracer == nil {
+		return trace.SpanFromContext(ctx)
+	}
+
+	ctx, span := tracer.Start(ctx, "im.send "+msg.RoomId,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(messageAttributes(msg.MessageId, msg.RoomId, msg.Type)...),
+	)
+
+	if msg.Metadata == nil {
+		msg.Metadata = make(map[string]string)
+	}
+	c.propagator().Inject(ctx, propagation.MapCarrier(msg.Metadata))
+
+	return span
+}
+
+// startReceiveSpan 为收到的消息创建 consumer span，父span为发送方写入 metadata 的追踪上下文
+func (c *Client) startReceiveSpan(msg *imv1.MessageResponse) trace.Span {
+	tracer := c.tracer()
+	if tracer == nil {
+		return trace.SpanFromContext(c.ctx)
+	}
+
+	_, span := tracer.Start(c.MessageContext(c.ctx, msg), "im.receive "+msg.RoomId,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(messageAttributes(msg.MessageId, msg.RoomId, msg.Type)...),
+		trace.WithAttributes(attribute.String("im.from_user_id", msg.FromUserId)),
+	)
+	return span
+}
+
+// MessageContext 返回携带消息发送方追踪上下文的 context，可在消息处理器中创建子span
+func (c *Client) MessageContext(ctx context.Context, msg *imv1.MessageResponse) context.Context {
+	if len(msg.Metadata) == 0 {
+		return ctx
+	}
+	return c.propagator().Extract(ctx, propagation.MapCarrier(msg.Metadata))
+}
+
+// messageAttributes 返回消息span的属性
+func messageAttributes(messageID, roomID string, msgType imv1.MessageType) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.system", "im"),
+		attribute.String("messaging.destination.name", roomID),
+		attribute.String("messaging.message.id", messageID),
+		attribute.String("im.message_type", msgType.String()),
+	}
+}
+
+// endSpan 结束span，出错时记录错误
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// findSpan 等待名为 name 且属于消息 messageID 的span结束
+func findSpan(t *testing.T, recorder *tracetest.SpanRecorder, name, messageID string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	id := attribute.String("messaging.message.id", messageID)
+	deadline := time.Now().Add(testTimeout)
+	for {
+		for _, span := range recorder.Ended() {
+			if span.Name() == name && slices.Contains(span.Attributes(), id) {
+				return span
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("没有记录到span %q", name)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTraceContextPropagatesThroughMessageMetadata(t *testing.T) {
+	srv := newTestServer(t)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	aliceConfig := newTestConfig(srv, "alice")
+	aliceConfig.TracerProvider = provider
+	alice := connectTestClient(t, aliceConfig)
+
+	received := make(chan *imv1.MessageResponse, 1)
+	bobConfig := newTestConfig(srv, "bob")
+	bobConfig.TracerProvider = provider
+	bobConfig.OnMessage = func(msg *imv1.MessageResponse) {
+		if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+			received <- msg
+		}
+	}
+	bob := connectTestClient(t, bobConfig)
+	waitSession(t, srv, "bob")
+
+	for _, c := range []*Client{alice, bob} {
+		if _, err := c.JoinRoom("room1", nil); err != nil {
+			t.Fatalf("JoinRoom: %v", err)
+		}
+	}
+	waitMember(t, srv, "room1", "bob")
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	if err := alice.SendTextMessageContext(ctx, "room1", "hello"); err != nil {
+		t.Fatalf("SendTextMessageContext: %v", err)
+	}
+	parent.End()
+
+	// 发送方把 producer span 写入消息 metadata 的 traceparent
+	sent := waitReceived(t, srv, "hello")
+	if sent.Metadata["traceparent"] == "" {
+		t.Fatalf("Metadata = %v, want traceparent", sent.Metadata)
+	}
+	producer := findSpan(t, recorder, "im.send room1", sent.MessageId)
+	if producer.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("producer span 的父span不是发送时 context 中的span")
+	}
+
+	// 接收方从 metadata 中恢复发送方的追踪上下文
+	var msg *imv1.MessageResponse
+	select {
+	case msg = <-received:
+	case <-time.After(testTimeout):
+		t.Fatal("等待 bob 收到消息超时")
+	}
+	remote := trace.SpanContextFromContext(bob.MessageContext(context.Background(), msg))
+	if !remote.IsRemote() || remote.TraceID() != parent.SpanContext().TraceID() || remote.SpanID() != producer.SpanContext().SpanID() {
+		t.Fatalf("MessageContext 的追踪上下文 = %v, want producer span %v", remote, producer.SpanContext())
+	}
+	consumer := findSpan(t, recorder, "im.receive room1", msg.MessageId)
+	if consumer.Parent().SpanID() != producer.SpanContext().SpanID() || consumer.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Fatal("consumer span 的父span不是发送方的 producer span")
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const (
+	defaultTranscriptPollInitial = 500 * time.Millisecond
+	defaultTranscriptPollMax     = 5 * time.Second
+	defaultTranscriptTimeout     = 5 * time.Minute
+)
+
+// GetTranscript 获取音频的转写结果
+func (c *Client) GetTranscript(audioID string) (*imv1.Transcription, error) {
+	return c.GetTranscriptContext(context.Background(), audioID)
+}
+
+// GetTranscriptContext 获取音频的转写结果
+func (c *Client) GetTranscriptContext(ctx context.Context, audioID string) (*imv1.Transcription, error) {
+	grpcClient, err := c.readyClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	ctx, finish := c.startRPC(ctx, "GetAudioTranscript", "")
+	resp, err := invokeWithCredentials(c, ctx, func(ctx context.Context) (*imv1.TranscriptResponse, error) {
+		return grpcClient.GetAudioTranscript(ctx, &imv1.TranscriptRequest{
+			AudioId: audioID,
+			UserId:  c.config.UserID,
+		})
+	})
+	finish(err)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Transcription, nil
+}
+
+// WaitForTranscript 轮询转写结果，直到状态变为 COMPLETED 或 FAILED
+//
+// 轮询间隔由 Config.TranscriptPollBackoff 决定，策略放弃时返回最后一次的结果和错误。
+// 轮询期间的可重试错误（如重连中）会继续轮询，其他错误直接返回；
+// 转写失败时返回转写结果和 ErrTranscriptFailed。
+func (c *Client) WaitForTranscript(ctx context.Context, audioID string) (*imv1.Transcription, error) {
+	policy := c.transcriptPollBackoff()
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		transcription, err := c.GetTranscriptContext(ctx, audioID)
+		if err == nil {
+			switch transcription.GetStatus() {
+			case imv1.TranscriptStatus_TRANSCRIPT_STATUS_COMPLETED:
+				return transcription, nil
+			case imv1.TranscriptStatus_TRANSCRIPT_STATUS_FAILED:
+				return transcription, fmt.Errorf("音频 %s: %w", audioID, ErrTranscriptFailed)
+			}
+		} else if ctx.Err() != nil || !IsRetryable(err) {
+			return nil, err
+		}
+
+		next, ok := policy.Next(attempt, delay)
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("音频 %s 转写未完成，当前状态 %s", audioID, transcription.GetStatus())
+			}
+			return transcription, fmt.Errorf("轮询 %d 次后放弃等待转写: %w", attempt, err)
+		}
+		delay = next
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-c.ctx.Done():
+			timer.Stop()
+			return nil, ErrClosed
+		}
+	}
+}
+
+// SubscribeTranscript 在后台等待转写完成，状态变为 COMPLETED 或 FAILED 时调用 callback
+//
+// 最长等待 Config.TranscriptTimeout，等待失败时通过 OnError 回调报告，客户端关闭时静默退出。
+func (c *Client) SubscribeTranscript(audioID string, callback func(*imv1.Transcription)) {
+	go func() {
+		ctx, cancel := context.WithTimeout(c.ctx, c.transcriptTimeout())
+		defer cancel()
+
+		transcription, err := c.WaitForTranscript(ctx, audioID)
+		if transcription.GetStatus() == imv1.TranscriptStatus_TRANSCRIPT_STATUS_COMPLETED ||
+			transcription.GetStatus() == imv1.TranscriptStatus_TRANSCRIPT_STATUS_FAILED {
+			callback(transcription)
+			return
+		}
+
+		if c.ctx.Err() != nil {
+			return
+		}
+		c.logger.Warn("等待转写结果失败", "audio_id", audioID, "error", err)
+		if c.config.OnError != nil {
+			c.config.OnError(fmt.Errorf("等待音频 %s 转写结果失败: %w", audioID, err))
+		}
+	}()
+}
+
+// transcriptPollBackoff 转写轮询间隔策略，默认从500ms开始指数增长，最长5s
+func (c *Client) transcriptPollBackoff() BackoffPolicy {
+	if c.config.TranscriptPollBackoff != nil {
+		return c.config.TranscriptPollBackoff
+	}
+	return NewExponentialBackoff(defaultTranscriptPollInitial, defaultTranscriptPollMax)
+}
+
+// transcriptTimeout 自动订阅转写结果时的最长等待时间
+func (c *Client) transcriptTimeout() time.Duration {
+	if c.config.TranscriptTimeout > 0 {
+		return c.config.TranscriptTimeout
+	}
+	return defaultTranscriptTimeout
+}
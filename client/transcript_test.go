@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+	"github.com/Dev-Umb/im-grpc-sdk/server"
+)
+
+// gatedTranscriber 在 release 关闭之前阻塞转写，之后返回 err 或固定的文本
+type gatedTranscriber struct {
+	release chan struct{}
+	err     error
+}
+
+// Transcribe 实现 server.Transcriber
+func (gt *gatedTranscriber) Transcribe(ctx context.Context, metadata *imv1.AudioMetadata, data []byte) (string, float64, error) {
+	<-gt.release
+	if gt.err != nil {
+		return "", 0, gt.err
+	}
+	return "你好", 0.9, nil
+}
+
+// newTranscriptTest 创建使用 transcriber 转写的服务端，上传一段音频，返回客户端、音频ID和 GetAudioTranscript 的调用次数
+func newTranscriptTest(t *testing.T, transcriber server.Transcriber, poll BackoffPolicy) (*Client, string, *atomic.Int32) {
+	t.Helper()
+
+	serverConfig := server.DefaultConfig()
+	serverConfig.Transcriber = transcriber
+	srv := imtest.NewServer(serverConfig)
+	t.Cleanup(srv.Close)
+
+	var polls atomic.Int32
+	config := newTestConfig(srv, "alice")
+	config.TranscriptPollBackoff = poll
+	config.UnaryInterceptors = []grpc.UnaryClientInterceptor{
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if method == imv1.IMService_GetAudioTranscript_FullMethodName {
+				polls.Add(1)
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		},
+	}
+	c := connectTestClient(t, config)
+
+	resp, err := c.UploadAudioFrom(context.Background(), bytes.NewReader(audioData(1024)), AudioUpload{
+		RoomID: "room1",
+		Format: "pcm",
+	})
+	if err != nil {
+		t.Fatalf("UploadAudioFrom: %v", err)
+	}
+	return c, resp.AudioId, &polls
+}
+
+// waitTranscript 在后台执行 WaitForTranscript
+func waitTranscript(ctx context.Context, c *Client, audioID string) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		transcription, err := c.WaitForTranscript(ctx, audioID)
+		if err == nil && transcription.Text != "你好" {
+			err = errors.New("转写结果不正确: " + transcription.Text)
+		}
+		done <- err
+	}()
+	return done
+}
+
+func TestWaitForTranscriptPollsUntilCompleted(t *testing.T) {
+	transcriber := &gatedTranscriber{release: make(chan struct{})}
+	c, audioID, polls := newTranscriptTest(t, transcriber, NewConstantBackoff(10*time.Millisecond, 0))
+
+	done := waitTranscript(context.Background(), c, audioID)
+
+	// 转写完成之前持续轮询
+	deadline := time.Now().Add(testTimeout)
+	for polls.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("轮询 %d 次, want 至少3次", polls.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("转写完成之前 WaitForTranscript 返回了 %v", err)
+	default:
+	}
+
+	close(transcriber.release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("WaitForTranscript: %v", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("转写完成后 WaitForTranscript 没有返回")
+	}
+}
+
+func TestWaitForTranscriptFailed(t *testing.T) {
+	transcriber := &gatedTranscriber{release: make(chan struct{}), err: errors.New("识别失败")}
+	close(transcriber.release)
+	c, audioID, _ := newTranscriptTest(t, transcriber, NewConstantBackoff(10*time.Millisecond, 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	transcription, err := c.WaitForTranscript(ctx, audioID)
+	if !errors.Is(err, ErrTranscriptFailed) {
+		t.Fatalf("err = %v, want ErrTranscriptFailed", err)
+	}
+	if transcription.GetStatus() != imv1.TranscriptStatus_TRANSCRIPT_STATUS_FAILED {
+		t.Fatalf("Status = %s, want FAILED", transcription.GetStatus())
+	}
+}
+
+func TestWaitForTranscriptTimeout(t *testing.T) {
+	transcriber := &gatedTranscriber{release: make(chan struct{})}
+	t.Cleanup(func() { close(transcriber.release) })
+
+	t.Run("context 超时", func(t *testing.T) {
+		c, audioID, _ := newTranscriptTest(t, transcriber, NewConstantBackoff(10*time.Millisecond, 0))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := c.WaitForTranscript(ctx, audioID)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want DeadlineExceeded", err)
+		}
+		if elapsed := time.Since(start); elapsed > testTimeout/2 {
+			t.Fatalf("context 超时后 %v 才返回", elapsed)
+		}
+	})
+
+	t.Run("轮询次数用尽", func(t *testing.T) {
+		c, audioID, polls := newTranscriptTest(t, transcriber, NewConstantBackoff(10*time.Millisecond, 3))
+
+		ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+		defer cancel()
+		transcription, err := c.WaitForTranscript(ctx, audioID)
+		if err == nil {
+			t.Fatal("转写未完成时应返回错误")
+		}
+		// 放弃时返回最后一次查询到的结果
+		if status := transcription.GetStatus(); status != imv1.TranscriptStatus_TRANSCRIPT_STATUS_PROCESSING && status != imv1.TranscriptStatus_TRANSCRIPT_STATUS_PENDING {
+			t.Fatalf("Status = %s, want PENDING 或 PROCESSING", status)
+		}
+		if n := polls.Load(); n != 3 {
+			t.Fatalf("轮询 %d 次, want 3", n)
+		}
+	})
+}
+
+func TestWaitForTranscriptNotFound(t *testing.T) {
+	transcriber := &gatedTranscriber{release: make(chan struct{})}
+	t.Cleanup(func() { close(transcriber.release) })
+	c, _, polls := newTranscriptTest(t, transcriber, NewConstantBackoff(10*time.Millisecond, 0))
+
+	// 不可重试的错误直接返回
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	_, err := c.WaitForTranscript(ctx, "missing")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != codes.NotFound {
+		t.Fatalf("err = %v, want NotFound", err)
+	}
+	if n := polls.Load(); n != 1 {
+		t.Fatalf("轮询 %d 次, want 1", n)
+	}
+}
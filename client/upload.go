@@ -0,0 +1,332 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const (
+	defaultUploadChunkSize  = 32 * 1024
+	defaultUploadRetries    = 5
+	defaultUploadRetryStart = 500 * time.Millisecond
+	defaultUploadRetryMax   = 5 * time.Second
+)
+
+// AudioUpload 流式上传音频的参数
+type AudioUpload struct {
+	RoomID   string
+	Format   string
+	Duration float64
+
+	// Size 音频总字节数，未知时为0；reader 实现 io.Seeker 时自动计算
+	Size int64
+	// Checksum 完整音频的SHA-256（十六进制），为空且 reader 实现 io.Seeker 时自动计算
+	Checksum string
+	// ChunkSize 每个数据块的字节数，为0时使用 Config.UploadChunkSize
+	ChunkSize int
+	// Resumable 上传中断时从服务端已收到的偏移量续传，reader 必须实现 io.Seeker
+	Resumable bool
+	// OnProgress 每发送一个数据块后回调，sent 为服务端将收到的总字节数，total 为0表示总大小未知
+	OnProgress func(sent, total int64)
+}
+
+// UploadAudio 上传音频，配置了 OnTranscript 时自动订阅转写结果
+func (c *Client) UploadAudio(roomID string, audioData []byte, format string, duration float64) (*imv1.UploadAudioResponse, error) {
+	return c.UploadAudioContext(context.Background(), roomID, audioData, format, duration)
+}
+
+// UploadAudioContext 上传音频，ctx 未设置截止时间时整个上传使用 RequestTimeout
+func (c *Client) UploadAudioContext(ctx context.Context, roomID string, audioData []byte, format string, duration float64) (*imv1.UploadAudioResponse, error) {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	return c.uploadAudio(ctx, bytes.NewReader(audioData), AudioUpload{
+		RoomID:   roomID,
+		Format:   format,
+		Duration: duration,
+		Size:     int64(len(audioData)),
+	})
+}
+
+// UploadAudioFrom 从 reader 流式上传音频，内存占用只有一个数据块
+//
+// 上传不受 RequestTimeout 限制，只在 ctx 取消或客户端关闭时中止。
+// 服务端返回的校验和与本地计算的不一致时返回错误；开启 Resumable 后，
+// 可重试的错误（如连接断开）会按 Config.UploadBackoff 等待后从服务端已收到的偏移量续传。
+func (c *Client) UploadAudioFrom(ctx context.Context, r io.Reader, upload AudioUpload) (*imv1.UploadAudioResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer func() {
+		stop()
+		cancel()
+	}()
+
+	return c.uploadAudio(ctx, r, upload)
+}
+
+// uploadAudio 上传音频，按需计算大小和校验和并在失败后续传
+func (c *Client) uploadAudio(ctx context.Context, r io.Reader, upload AudioUpload) (resp *imv1.UploadAudioResponse, err error) {
+	ctx, finish := c.startRPC(ctx, "UploadAudio", upload.RoomID)
+	defer func() { finish(err) }()
+
+	seeker, _ := r.(io.Seeker)
+	if upload.Resumable && seeker == nil {
+		return nil, fmt.Errorf("续传上传需要实现 io.Seeker 的 reader")
+	}
+
+	var start int64
+	var digest hash.Hash
+	if seeker != nil {
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("读取音频位置失败: %w", err)
+		}
+		if upload.Size == 0 || upload.Checksum == "" {
+			size, checksum, err := measureReader(r, seeker, start)
+			if err != nil {
+				return nil, err
+			}
+			if upload.Size == 0 {
+				upload.Size = size
+			}
+			if upload.Checksum == "" {
+				upload.Checksum = checksum
+			}
+		}
+	} else if upload.Checksum == "" {
+		// 无法预先计算时边上传边计算，上传完成后与服务端返回的校验和比较
+		digest = sha256.New()
+	}
+
+	meta := &imv1.AudioMetadata{
+		UserId:   c.config.UserID,
+		RoomId:   upload.RoomID,
+		Format:   upload.Format,
+		Size:     upload.Size,
+		Duration: upload.Duration,
+		Checksum: upload.Checksum,
+	}
+	if upload.Resumable {
+		meta.UploadId = c.generateMessageID()
+	}
+
+	policy := c.uploadBackoff()
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		resp, err = c.uploadOnce(ctx, r, meta, upload, digest)
+		if err == nil {
+			break
+		}
+		if !upload.Resumable || ctx.Err() != nil || !IsRetryable(err) {
+			return nil, err
+		}
+
+		next, ok := policy.Next(attempt, delay)
+		if !ok {
+			return nil, fmt.Errorf("上传 %d 次后放弃: %w", attempt, err)
+		}
+		delay = next
+		c.logger.Warn("音频上传中断，准备续传", "upload_id", meta.UploadId, "attempt", attempt, "error", err)
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		offset, statusErr := c.uploadOffset(ctx, meta.UploadId)
+		if statusErr != nil {
+			if !IsRetryable(statusErr) {
+				return nil, fmt.Errorf("查询上传状态失败: %w", statusErr)
+			}
+			// 暂时无法查询时从头上传，服务端收到偏移量0后丢弃已有数据
+			offset = 0
+		}
+		if _, err := seeker.Seek(start+offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("定位音频数据失败: %w", err)
+		}
+		meta.Offset = offset
+	}
+
+	checksum := upload.Checksum
+	if digest != nil {
+		checksum = hex.EncodeToString(digest.Sum(nil))
+	}
+	if resp.Checksum != "" && !strings.EqualFold(resp.Checksum, checksum) {
+		return nil, fmt.Errorf("音频校验和不匹配: 本地 %s，服务端 %s", checksum, resp.Checksum)
+	}
+
+	if c.config.OnTranscript != nil {
+		c.SubscribeTranscript(resp.AudioId, c.config.OnTranscript)
+	}
+	return resp, nil
+}
+
+// uploadOnce 打开一次上传流，从 meta.Offset 开始发送 reader 中剩余的数据
+func (c *Client) uploadOnce(ctx context.Context, r io.Reader, meta *imv1.AudioMetadata, upload AudioUpload, digest hash.Hash) (*imv1.UploadAudioResponse, error) {
+	c.mu.RLock()
+	if c.State() != StateReady {
+		c.mu.RUnlock()
+		return nil, c.notReadyError()
+	}
+	grpcClient := c.client
+	c.mu.RUnlock()
+
+	ctx, _, err := c.authContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := grpcClient.UploadAudio(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("创建上传流失败: %w", responseError(nil, err))
+	}
+
+	err = stream.Send(&imv1.UploadAudioRequest{
+		Data: &imv1.UploadAudioRequest_Metadata{Metadata: meta},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("发送音频元数据失败: %w", uploadSendError(stream, err))
+	}
+
+	chunkSize := upload.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = c.uploadChunkSize()
+	}
+	err = sendChunks(r, chunkSize, meta.Offset, upload.Size, digest, upload.OnProgress, func(chunk []byte) error {
+		err := stream.Send(&imv1.UploadAudioRequest{
+			Data: &imv1.UploadAudioRequest_Chunk{Chunk: chunk},
+		})
+		if err != nil {
+			return fmt.Errorf("发送音频数据失败: %w", uploadSendError(stream, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err = responseError(resp, err); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// uploadOffset 查询服务端已收到的字节数
+func (c *Client) uploadOffset(ctx context.Context, uploadID string) (int64, error) {
+	grpcClient, err := c.readyClient()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	ctx, finish := c.startRPC(ctx, "GetUploadStatus", "")
+	resp, err := invokeWithCredentials(c, ctx, func(ctx context.Context) (*imv1.UploadStatusResponse, error) {
+		return grpcClient.GetUploadStatus(ctx, &imv1.UploadStatusRequest{
+			UploadId: uploadID,
+			UserId:   c.config.UserID,
+		})
+	})
+	finish(err)
+	if err != nil {
+		return 0, err
+	}
+	return resp.ReceivedBytes, nil
+}
+
+// sendChunks 按 chunkSize 读取 reader 并逐块发送，直到 reader 结束；
+// sent 为已发送的字节数，每发送一块后更新并回调 onProgress
+func sendChunks(r io.Reader, chunkSize int, sent, total int64, digest hash.Hash, onProgress func(sent, total int64), send func(chunk []byte) error) error {
+	buf := make([]byte, chunkSize)
+
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			if digest != nil {
+				digest.Write(buf[:n])
+			}
+			if err := send(buf[:n]); err != nil {
+				return err
+			}
+
+			sent += int64(n)
+			if onProgress != nil {
+				onProgress(sent, total)
+			}
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("读取上传数据失败: %w", readErr)
+		}
+	}
+}
+
+// uploadSendError 发送失败时从流中读取真实的错误，stream.Send 只返回 io.EOF
+func uploadSendError[Req, Resp any](stream grpc.ClientStreamingClient[Req, Resp], err error) error {
+	if err != io.EOF {
+		return responseError(nil, err)
+	}
+	resp, err := stream.CloseAndRecv()
+	if err = responseError(resp, err); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// measureReader 读取一遍数据计算大小和SHA-256校验和，然后回到起始位置
+func measureReader(r io.Reader, seeker io.Seeker, start int64) (int64, string, error) {
+	digest := sha256.New()
+	n, err := io.Copy(digest, r)
+	if err != nil {
+		return 0, "", fmt.Errorf("计算校验和失败: %w", err)
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return 0, "", fmt.Errorf("定位上传数据失败: %w", err)
+	}
+	return n, hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// uploadChunkSize 上传数据块大小
+func (c *Client) uploadChunkSize() int {
+	if c.config.UploadChunkSize > 0 {
+		return c.config.UploadChunkSize
+	}
+	return defaultUploadChunkSize
+}
+
+// uploadBackoff 续传上传的重试策略，默认从500ms指数增长到5s，最多重试5次
+func (c *Client) uploadBackoff() BackoffPolicy {
+	if c.config.UploadBackoff != nil {
+		return c.config.UploadBackoff
+	}
+	policy := NewExponentialBackoff(defaultUploadRetryStart, defaultUploadRetryMax)
+	policy.MaxRetries = defaultUploadRetries
+	return policy
+}
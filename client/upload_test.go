@@ -0,0 +1,243 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// uploadInterceptor 记录每次 UploadAudio 发送的元数据，可以在第一次上传发送 failAfter 个数据块后中断，
+// 或篡改服务端返回的校验和
+type uploadInterceptor struct {
+	srv            *imtest.Server
+	failAfter      int
+	tamperChecksum bool
+
+	metas []*imv1.AudioMetadata
+	mu    sync.Mutex
+}
+
+// intercept 实现 grpc.StreamClientInterceptor
+func (ui *uploadInterceptor) intercept(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if method != imv1.IMService_UploadAudio_FullMethodName {
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &interceptedUpload{ClientStream: cs, interceptor: ui, cancel: cancel}, nil
+}
+
+// attempts 返回每次上传发送的元数据
+func (ui *uploadInterceptor) attempts() []*imv1.AudioMetadata {
+	ui.mu.Lock()
+	defer ui.mu.Unlock()
+	return slices.Clone(ui.metas)
+}
+
+// interceptedUpload 被拦截的一次上传
+type interceptedUpload struct {
+	grpc.ClientStream
+	interceptor *uploadInterceptor
+	cancel      context.CancelFunc
+	meta        *imv1.AudioMetadata
+	attempt     int
+	sent        int64
+	chunks      int
+}
+
+// SendMsg 记录元数据，第一次上传发送 failAfter 个数据块后等待服务端收到这些数据再中断
+func (u *interceptedUpload) SendMsg(m interface{}) error {
+	req := m.(*imv1.UploadAudioRequest)
+	ui := u.interceptor
+	if meta := req.GetMetadata(); meta != nil {
+		u.meta = proto.Clone(meta).(*imv1.AudioMetadata)
+		ui.mu.Lock()
+		ui.metas = append(ui.metas, u.meta)
+		u.attempt = len(ui.metas)
+		ui.mu.Unlock()
+		return u.ClientStream.SendMsg(m)
+	}
+
+	if u.attempt == 1 && ui.failAfter > 0 && u.chunks == ui.failAfter {
+		deadline := time.Now().Add(testTimeout)
+		for {
+			received, _ := ui.srv.Audio().UploadStatus(u.meta.UploadId, u.meta.UserId)
+			if received >= u.meta.Offset+u.sent {
+				break
+			}
+			if time.Now().After(deadline) {
+				return errors.New("服务端没有收到已发送的数据")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		u.cancel()
+		return status.Error(codes.Unavailable, "upload interrupted")
+	}
+	u.chunks++
+	u.sent += int64(len(req.GetChunk()))
+	return u.ClientStream.SendMsg(m)
+}
+
+// RecvMsg 按需篡改服务端返回的校验和
+func (u *interceptedUpload) RecvMsg(m interface{}) error {
+	err := u.ClientStream.RecvMsg(m)
+	if resp, ok := m.(*imv1.UploadAudioResponse); ok && err == nil && u.interceptor.tamperChecksum {
+		resp.Checksum = strings.Repeat("0", 64)
+	}
+	return err
+}
+
+// newUploadTest 创建通过 ui 拦截上传的客户端，数据块为1KB
+func newUploadTest(t *testing.T, ui *uploadInterceptor) *Client {
+	t.Helper()
+
+	ui.srv = newTestServer(t)
+	config := newTestConfig(ui.srv, "alice")
+	config.UploadChunkSize = 1024
+	config.UploadBackoff = NewConstantBackoff(10*time.Millisecond, 3)
+	config.StreamInterceptors = []grpc.StreamClientInterceptor{ui.intercept}
+	return connectTestClient(t, config)
+}
+
+// audioData 返回测试用的音频数据
+func audioData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+// expectStoredAudio 检查服务端保存的音频数据
+func expectStoredAudio(t *testing.T, srv *imtest.Server, audioID string, want []byte) {
+	t.Helper()
+
+	_, data, err := srv.Audio().Data(audioID)
+	if err != nil {
+		t.Fatalf("Data: %v", err)
+	}
+	if !bytes.Equal(data, want) {
+		t.Fatalf("服务端保存了 %d 字节，与上传的 %d 字节不一致", len(data), len(want))
+	}
+}
+
+func TestUploadAudioResumesFromServerOffset(t *testing.T) {
+	ui := &uploadInterceptor{failAfter: 4}
+	c := newUploadTest(t, ui)
+	data := audioData(10 * 1024)
+
+	var progress []int64
+	resp, err := c.UploadAudioFrom(context.Background(), bytes.NewReader(data), AudioUpload{
+		RoomID:     "room1",
+		Format:     "pcm",
+		Resumable:  true,
+		OnProgress: func(sent, total int64) { progress = append(progress, sent) },
+	})
+	if err != nil {
+		t.Fatalf("UploadAudioFrom: %v", err)
+	}
+	expectStoredAudio(t, ui.srv, resp.AudioId, data)
+
+	sum := sha256.Sum256(data)
+	attempts := ui.attempts()
+	if len(attempts) != 2 {
+		t.Fatalf("上传 %d 次, want 2", len(attempts))
+	}
+	first, second := attempts[0], attempts[1]
+	if first.UploadId == "" || second.UploadId != first.UploadId {
+		t.Fatalf("UploadId = %q, %q, want 相同且不为空", first.UploadId, second.UploadId)
+	}
+	if first.Size != int64(len(data)) || first.Checksum != hex.EncodeToString(sum[:]) {
+		t.Fatalf("元数据 Size = %d, Checksum = %s, want %d, %x", first.Size, first.Checksum, len(data), sum)
+	}
+	// 续传从服务端已收到的4个数据块之后开始
+	if first.Offset != 0 || second.Offset != 4*1024 {
+		t.Fatalf("Offset = %d, %d, want 0, 4096", first.Offset, second.Offset)
+	}
+	if last := progress[len(progress)-1]; last != int64(len(data)) {
+		t.Fatalf("最后一次进度 = %d, want %d", last, len(data))
+	}
+}
+
+func TestUploadAudioChecksumMismatch(t *testing.T) {
+	ui := &uploadInterceptor{}
+	c := newUploadTest(t, ui)
+
+	// 声明的校验和与数据不一致，服务端拒绝保存
+	_, err := c.UploadAudioFrom(context.Background(), bytes.NewReader(audioData(2048)), AudioUpload{
+		RoomID:   "room1",
+		Format:   "pcm",
+		Checksum: strings.Repeat("f", 64),
+	})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != codes.DataLoss {
+		t.Fatalf("err = %v, want DataLoss", err)
+	}
+}
+
+func TestUploadAudioStreamingDigest(t *testing.T) {
+	ui := &uploadInterceptor{}
+	c := newUploadTest(t, ui)
+	data := audioData(3*1024 + 100)
+
+	// 不可 Seek 的 reader 无法预先计算大小和校验和，边上传边计算
+	resp, err := c.UploadAudioFrom(context.Background(), struct{ io.Reader }{bytes.NewReader(data)}, AudioUpload{
+		RoomID: "room1",
+		Format: "pcm",
+	})
+	if err != nil {
+		t.Fatalf("UploadAudioFrom: %v", err)
+	}
+	expectStoredAudio(t, ui.srv, resp.AudioId, data)
+	if meta := ui.attempts()[0]; meta.Size != 0 || meta.Checksum != "" || meta.UploadId != "" {
+		t.Fatalf("元数据 Size = %d, Checksum = %q, UploadId = %q, want 均为空", meta.Size, meta.Checksum, meta.UploadId)
+	}
+
+	// 本地计算的校验和与服务端返回的不一致
+	ui.tamperChecksum = true
+	_, err = c.UploadAudioFrom(context.Background(), struct{ io.Reader }{bytes.NewReader(data)}, AudioUpload{
+		RoomID: "room1",
+		Format: "pcm",
+	})
+	if err == nil || !strings.Contains(err.Error(), "校验和不匹配") {
+		t.Fatalf("err = %v, want 校验和不匹配", err)
+	}
+}
+
+func TestUploadAudioResumableRequiresSeeker(t *testing.T) {
+	ui := &uploadInterceptor{}
+	c := newUploadTest(t, ui)
+
+	_, err := c.UploadAudioFrom(context.Background(), struct{ io.Reader }{bytes.NewReader(audioData(1024))}, AudioUpload{
+		RoomID:    "room1",
+		Format:    "pcm",
+		Resumable: true,
+	})
+	if err == nil {
+		t.Fatal("不可 Seek 的 reader 不应支持续传")
+	}
+	if n := len(ui.attempts()); n != 0 {
+		t.Fatalf("上传 %d 次, want 0", n)
+	}
+}
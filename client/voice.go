@@ -0,0 +1,411 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const (
+	defaultVoiceJitterDelay = 60 * time.Millisecond
+	voiceTickInterval       = 10 * time.Millisecond
+	voiceFrameBuffer        = 256
+	voiceSpeakerTimeout     = 5 * time.Second
+	voiceCloseTimeout       = time.Second
+)
+
+// VoiceStream 房间内的实时语音会话，可以同时发送本端语音和接收其他成员的语音
+//
+// 收到的语音帧按发送方会话经过抖动缓冲：同一发送方的帧按 sequence 排序，
+// 按采集时间加上 Config.VoiceJitterDelay 的节奏交给 Recv，迟到的帧被丢弃，
+// 超过播放时间仍未到达的帧被跳过，语音流结束时缓冲中剩余的帧立即按序交给 Recv。
+// 连接断开后语音流随之结束，需要重新调用 StartVoiceStream。
+type VoiceStream struct {
+	client    *Client
+	stream    grpc.BidiStreamingClient[imv1.VoiceFrame, imv1.VoiceFrame]
+	sessionID string
+	roomID    string
+	format    string
+	start     time.Time
+
+	sendMu   sync.Mutex
+	sequence uint64
+	closed   atomic.Bool
+
+	jitter    *jitterBuffer
+	frames    chan *imv1.VoiceFrame
+	recvDone  chan struct{}
+	done      chan struct{}
+	err       error // 接收结束的原因，recvDone 关闭后可读
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// StartVoiceStream 加入房间的实时语音会话，用户必须已经加入该房间
+func (c *Client) StartVoiceStream(roomID, format string) (*VoiceStream, error) {
+	return c.StartVoiceStreamContext(context.Background(), roomID, format)
+}
+
+// StartVoiceStreamContext 加入房间的实时语音会话，ctx 只约束建立语音流的过程
+func (c *Client) StartVoiceStreamContext(ctx context.Context, roomID, format string) (*VoiceStream, error) {
+	c.mu.RLock()
+	if c.State() != StateReady {
+		c.mu.RUnlock()
+		return nil, c.notReadyError()
+	}
+	grpcClient := c.client
+	c.mu.RUnlock()
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, streamCancel := context.WithCancel(c.ctx)
+	streamCtx = withToken(metadata.AppendToOutgoingContext(streamCtx, "user-id", c.config.UserID), token)
+
+	stream, err := grpcClient.StreamVoice(streamCtx)
+	if err != nil {
+		streamCancel()
+		return nil, fmt.Errorf("创建语音流失败: %w", responseError(nil, err))
+	}
+
+	vs := &VoiceStream{
+		client:    c,
+		stream:    stream,
+		sessionID: c.generateMessageID(),
+		roomID:    roomID,
+		format:    format,
+		start:     time.Now(),
+		jitter:    newJitterBuffer(c.voiceJitterDelay()),
+		frames:    make(chan *imv1.VoiceFrame, voiceFrameBuffer),
+		recvDone:  make(chan struct{}),
+		done:      make(chan struct{}),
+		cancel:    streamCancel,
+	}
+
+	// 第一帧用于加入语音会话
+	err = stream.Send(&imv1.VoiceFrame{
+		SessionId: vs.sessionID,
+		UserId:    c.config.UserID,
+		RoomId:    roomID,
+		Format:    format,
+	})
+	if err == nil {
+		// 等待服务端的确认帧，ctx 结束时取消语音流
+		stop := context.AfterFunc(ctx, streamCancel)
+		_, err = stream.Recv()
+		stop()
+	}
+	if err != nil {
+		streamCancel()
+		return nil, fmt.Errorf("加入语音会话失败: %w", responseError(nil, err))
+	}
+
+	go vs.receive()
+	go vs.playout()
+
+	c.logger.Info("加入语音会话", "room_id", roomID, "session_id", vs.sessionID, "format", format)
+	return vs, nil
+}
+
+// SessionID 返回本端的语音会话ID
+func (vs *VoiceStream) SessionID() string {
+	return vs.sessionID
+}
+
+// Send 发送一帧编码后的语音，采集时间为语音流开始到现在的时长
+func (vs *VoiceStream) Send(payload []byte) error {
+	return vs.SendAt(payload, time.Since(vs.start))
+}
+
+// SendAt 发送一帧编码后的语音，timestamp 为相对语音流开始的采集时间，
+// 使用编码器的帧时长计算 timestamp 时接收方的播放节奏更平稳
+func (vs *VoiceStream) SendAt(payload []byte, timestamp time.Duration) error {
+	vs.sendMu.Lock()
+	defer vs.sendMu.Unlock()
+
+	if vs.closed.Load() {
+		return ErrVoiceStreamClosed
+	}
+
+	vs.sequence++
+	err := vs.stream.Send(&imv1.VoiceFrame{
+		SessionId:   vs.sessionID,
+		RoomId:      vs.roomID,
+		Format:      vs.format,
+		Sequence:    vs.sequence,
+		TimestampMs: timestamp.Milliseconds(),
+		Payload:     payload,
+	})
+	if err != nil {
+		return fmt.Errorf("发送语音帧失败: %w", vs.sendError(err))
+	}
+	return nil
+}
+
+// Recv 按播放顺序返回房间内其他成员的下一帧语音，end 为 true 的帧表示对应发送方结束了语音；
+// 语音流关闭后返回 io.EOF，连接断开时返回对应的错误
+func (vs *VoiceStream) Recv(ctx context.Context) (*imv1.VoiceFrame, error) {
+	select {
+	case frame, ok := <-vs.frames:
+		if !ok {
+			return nil, vs.err
+		}
+		return frame, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close 发送结束帧并关闭语音流，可以重复调用
+func (vs *VoiceStream) Close() error {
+	var err error
+	vs.closeOnce.Do(func() {
+		vs.sendMu.Lock()
+		if !vs.closed.Swap(true) {
+			vs.sequence++
+			err = vs.stream.Send(&imv1.VoiceFrame{
+				SessionId:   vs.sessionID,
+				RoomId:      vs.roomID,
+				Format:      vs.format,
+				Sequence:    vs.sequence,
+				TimestampMs: time.Since(vs.start).Milliseconds(),
+				End:         true,
+			})
+			vs.stream.CloseSend()
+		}
+		vs.sendMu.Unlock()
+
+		// 等待服务端收到结束帧后关闭流，超时则直接取消
+		select {
+		case <-vs.recvDone:
+		case <-time.After(voiceCloseTimeout):
+		}
+		vs.cancel()
+		<-vs.done
+
+		vs.client.logger.Info("离开语音会话", "room_id", vs.roomID, "session_id", vs.sessionID)
+	})
+	if err == io.EOF {
+		err = nil
+	}
+	return err
+}
+
+// receive 接收语音帧写入抖动缓冲
+func (vs *VoiceStream) receive() {
+	defer close(vs.recvDone)
+
+	for {
+		frame, err := vs.stream.Recv()
+		if err != nil {
+			switch {
+			case err == io.EOF:
+				vs.err = io.EOF
+			case vs.client.ctx.Err() != nil:
+				vs.err = ErrClosed
+			case vs.closed.Load():
+				vs.err = io.EOF
+			default:
+				vs.err = responseError(nil, err)
+				vs.client.logger.Warn("语音流中断", "room_id", vs.roomID, "session_id", vs.sessionID, "error", err)
+			}
+			return
+		}
+		vs.jitter.push(frame, time.Now())
+	}
+}
+
+// playout 按播放时间从抖动缓冲取出语音帧
+func (vs *VoiceStream) playout() {
+	defer close(vs.done)
+	defer close(vs.frames)
+
+	ticker := time.NewTicker(voiceTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-vs.recvDone:
+			// 接收结束后不再等待播放时间，缓冲中剩余的帧（包括结束帧）按序交给 Recv
+			vs.deliverFrames(vs.jitter.flush())
+			return
+		case now := <-ticker.C:
+			vs.deliverFrames(vs.jitter.pop(now))
+		}
+	}
+}
+
+// deliverFrames 把语音帧交给 Recv，消费过慢时丢弃
+func (vs *VoiceStream) deliverFrames(frames []*imv1.VoiceFrame) {
+	for _, frame := range frames {
+		select {
+		case vs.frames <- frame:
+		default:
+			vs.client.logger.Debug("语音帧消费过慢，丢弃", "session_id", frame.SessionId, "sequence", frame.Sequence)
+		}
+	}
+}
+
+// sendError 发送失败时返回流的真实错误，stream.Send 只返回 io.EOF
+func (vs *VoiceStream) sendError(err error) error {
+	if err != io.EOF {
+		return responseError(nil, err)
+	}
+	<-vs.recvDone
+	if vs.err != nil && vs.err != io.EOF {
+		return vs.err
+	}
+	return ErrVoiceStreamClosed
+}
+
+// voiceJitterDelay 抖动缓冲的播放延迟
+func (c *Client) voiceJitterDelay() time.Duration {
+	if c.config.VoiceJitterDelay > 0 {
+		return c.config.VoiceJitterDelay
+	}
+	return defaultVoiceJitterDelay
+}
+
+// jitterBuffer 语音抖动缓冲，按发送方会话分别排序
+type jitterBuffer struct {
+	delay    time.Duration
+	speakers map[string]*speakerBuffer
+	mu       sync.Mutex
+}
+
+// speakerBuffer 一个发送方会话的缓冲状态
+type speakerBuffer struct {
+	origin   time.Time // 采集时间0对应的本地时间，取各帧“到达时间-采集时间”的最小值
+	next     uint64    // 下一帧应播放的序号
+	played   bool
+	pending  map[uint64]*imv1.VoiceFrame
+	lastSeen time.Time
+}
+
+// newJitterBuffer 创建抖动缓冲，delay 为采集时间之后的播放延迟
+func newJitterBuffer(delay time.Duration) *jitterBuffer {
+	return &jitterBuffer{
+		delay:    delay,
+		speakers: make(map[string]*speakerBuffer),
+	}
+}
+
+// push 缓冲收到的语音帧，已经播放过的序号直接丢弃
+func (b *jitterBuffer) push(frame *imv1.VoiceFrame, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	origin := now.Add(-time.Duration(frame.TimestampMs) * time.Millisecond)
+	sp, exists := b.speakers[frame.SessionId]
+	if !exists {
+		sp = &speakerBuffer{
+			origin:  origin,
+			next:    frame.Sequence,
+			pending: make(map[uint64]*imv1.VoiceFrame),
+		}
+		b.speakers[frame.SessionId] = sp
+	}
+
+	if frame.Sequence < sp.next {
+		if sp.played {
+			return
+		}
+		// 开始播放前到达的更早的帧
+		sp.next = frame.Sequence
+	}
+	if origin.Before(sp.origin) {
+		sp.origin = origin
+	}
+	sp.pending[frame.Sequence] = frame
+	sp.lastSeen = now
+}
+
+// pop 取出所有到达播放时间的语音帧，同一发送方的帧按序号排列
+func (b *jitterBuffer) pop(now time.Time) []*imv1.VoiceFrame {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var frames []*imv1.VoiceFrame
+	for id, sp := range b.speakers {
+		ended := false
+		for len(sp.pending) > 0 {
+			frame, ok := sp.pending[sp.next]
+			if !ok {
+				// 缺失的帧在后续帧的播放时间到达前仍未到达时跳过
+				first := sp.earliest()
+				if now.Before(b.playoutTime(sp, first)) {
+					break
+				}
+				sp.next = first.Sequence
+				continue
+			}
+			if now.Before(b.playoutTime(sp, frame)) {
+				break
+			}
+
+			frames = append(frames, frame)
+			delete(sp.pending, sp.next)
+			sp.next++
+			sp.played = true
+			if frame.End {
+				ended = true
+				break
+			}
+		}
+
+		if ended || (len(sp.pending) == 0 && now.Sub(sp.lastSeen) > voiceSpeakerTimeout) {
+			delete(b.speakers, id)
+		}
+	}
+	return frames
+}
+
+// flush 取出缓冲中的所有语音帧并清空缓冲，同一发送方的帧按序号排列
+func (b *jitterBuffer) flush() []*imv1.VoiceFrame {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var frames []*imv1.VoiceFrame
+	for id, sp := range b.speakers {
+		sequences := make([]uint64, 0, len(sp.pending))
+		for seq := range sp.pending {
+			sequences = append(sequences, seq)
+		}
+		slices.Sort(sequences)
+		for _, seq := range sequences {
+			frames = append(frames, sp.pending[seq])
+		}
+		delete(b.speakers, id)
+	}
+	return frames
+}
+
+// playoutTime 语音帧的播放时间
+func (b *jitterBuffer) playoutTime(sp *speakerBuffer, frame *imv1.VoiceFrame) time.Time {
+	return sp.origin.Add(time.Duration(frame.TimestampMs)*time.Millisecond + b.delay)
+}
+
+// earliest 返回缓冲中序号最小的帧
+func (sp *speakerBuffer) earliest() *imv1.VoiceFrame {
+	var first *imv1.VoiceFrame
+	for seq, frame := range sp.pending {
+		if first == nil || seq < first.Sequence {
+			first = frame
+		}
+	}
+	return first
+}
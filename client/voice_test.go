@@ -0,0 +1,49 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+func TestJitterBufferReordersFrames(t *testing.T) {
+	b := newJitterBuffer(50 * time.Millisecond)
+	now := time.Now()
+	for _, seq := range []uint64{1, 3, 2} {
+		b.push(&imv1.VoiceFrame{SessionId: "s1", Sequence: seq, TimestampMs: int64(seq) * 20}, now)
+	}
+
+	if frames := b.pop(now); len(frames) != 0 {
+		t.Fatalf("播放时间前取出了 %d 帧", len(frames))
+	}
+	frames := b.pop(now.Add(time.Second))
+	if len(frames) != 3 || frames[0].Sequence != 1 || frames[1].Sequence != 2 || frames[2].Sequence != 3 {
+		t.Fatalf("frames = %v, want 1,2,3", frames)
+	}
+}
+
+func TestJitterBufferFlush(t *testing.T) {
+	b := newJitterBuffer(time.Hour)
+	now := time.Now()
+	b.push(&imv1.VoiceFrame{SessionId: "s1", Sequence: 2, TimestampMs: 20, End: true}, now)
+	b.push(&imv1.VoiceFrame{SessionId: "s1", Sequence: 1}, now)
+	b.push(&imv1.VoiceFrame{SessionId: "s2", Sequence: 7}, now)
+
+	frames := b.flush()
+	if len(frames) != 3 {
+		t.Fatalf("flush 返回 %d 帧, want 3", len(frames))
+	}
+	var s1 []*imv1.VoiceFrame
+	for _, frame := range frames {
+		if frame.SessionId == "s1" {
+			s1 = append(s1, frame)
+		}
+	}
+	if len(s1) != 2 || s1[0].Sequence != 1 || !s1[1].End {
+		t.Fatalf("s1 的帧 = %v, want 1 然后结束帧", s1)
+	}
+	if frames := b.flush(); len(frames) != 0 {
+		t.Fatalf("再次 flush 返回 %d 帧, want 0", len(frames))
+	}
+}
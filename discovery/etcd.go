@@ -0,0 +1,433 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// defaultEtcdLeaseTTL 默认租约TTL（秒）
+	defaultEtcdLeaseTTL int64 = 10
+	// defaultEtcdDialTimeout 默认连接超时时间
+	defaultEtcdDialTimeout = 5 * time.Second
+	// etcdRetryInterval 租约丢失或监听中断后的重试间隔
+	etcdRetryInterval = time.Second
+)
+
+// etcdRegistration 已注册服务的租约信息
+type etcdRegistration struct {
+	key       string
+	serviceID string
+	leaseID   clientv3.LeaseID
+	cancel    context.CancelFunc
+}
+
+// EtcdDiscovery ETCD服务发现实现
+//
+// 服务以 JSON 形式存储在 {prefix}/{serviceName}/{serviceID} 下，
+// 并绑定到带自动续约的租约上，进程退出后注册信息会随租约过期自动删除。
+type EtcdDiscovery struct {
+	client     *clientv3.Client
+	prefix     string
+	leaseTTL   int64
+	ownsClient bool
+
+	registrations map[string]*etcdRegistration // 以服务实例的key为索引，同一ID可以注册在不同服务名下
+	watchers      map[string]context.CancelFunc
+	mu            sync.RWMutex
+}
+
+// NewEtcdDiscovery 创建ETCD服务发现
+func NewEtcdDiscovery(endpoints []string, prefix string) (*EtcdDiscovery, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("ETCD地址不能为空")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: defaultEtcdDialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建ETCD客户端失败: %v", err)
+	}
+
+	ed := newEtcdDiscovery(client, prefix)
+	ed.ownsClient = true
+	return ed, nil
+}
+
+// NewEtcdDiscoveryWithClient 使用已有的ETCD客户端创建服务发现
+// （例如连接到内嵌ETCD服务器的客户端），Close 时不会关闭传入的客户端
+func NewEtcdDiscoveryWithClient(client *clientv3.Client, prefix string) (*EtcdDiscovery, error) {
+	if client == nil {
+		return nil, fmt.Errorf("ETCD客户端不能为空")
+	}
+
+	return newEtcdDiscovery(client, prefix), nil
+}
+
+// newEtcdDiscovery 初始化ETCD服务发现
+func newEtcdDiscovery(client *clientv3.Client, prefix string) *EtcdDiscovery {
+	prefix = "/" + strings.Trim(prefix, "/")
+
+	return &EtcdDiscovery{
+		client:        client,
+		prefix:        prefix,
+		leaseTTL:      defaultEtcdLeaseTTL,
+		registrations: make(map[string]*etcdRegistration),
+		watchers:      make(map[string]context.CancelFunc),
+	}
+}
+
+// SetLeaseTTL 设置注册租约的TTL（秒），仅对之后的注册生效
+func (ed *EtcdDiscovery) SetLeaseTTL(ttl int64) {
+	ed.mu.Lock()
+	defer ed.mu.Unlock()
+	if ttl > 0 {
+		ed.leaseTTL = ttl
+	}
+}
+
+// Register 注册服务
+func (ed *EtcdDiscovery) Register(ctx context.Context, service *ServiceInfo) error {
+	if service == nil || service.ID == "" || service.Name == "" {
+		return fmt.Errorf("服务ID和服务名不能为空")
+	}
+
+	value, err := json.Marshal(service)
+	if err != nil {
+		return fmt.Errorf("序列化服务信息失败: %v", err)
+	}
+
+	key := ed.serviceKey(service.Name, service.ID)
+
+	ed.mu.Lock()
+	ttl := ed.leaseTTL
+	old := ed.registrations[key]
+	delete(ed.registrations, key)
+	ed.mu.Unlock()
+
+	// 重复注册时先撤销旧租约
+	if old != nil {
+		old.cancel()
+		ed.client.Revoke(ctx, ed.leaseOf(old))
+	}
+
+	leaseID, err := ed.grantAndPut(ctx, key, string(value), ttl)
+	if err != nil {
+		return err
+	}
+
+	// 续约不能跟随请求ctx，否则请求结束后注册信息就会过期
+	keepCtx, cancel := context.WithCancel(context.Background())
+	reg := &etcdRegistration{
+		key:       key,
+		serviceID: service.ID,
+		leaseID:   leaseID,
+		cancel:    cancel,
+	}
+
+	ed.mu.Lock()
+	ed.registrations[key] = reg
+	ed.mu.Unlock()
+
+	go ed.keepAlive(keepCtx, reg, string(value), ttl)
+
+	return nil
+}
+
+// grantAndPut 申请租约并写入服务信息
+func (ed *EtcdDiscovery) grantAndPut(ctx context.Context, key, value string, ttl int64) (clientv3.LeaseID, error) {
+	lease, err := ed.client.Grant(ctx, ttl)
+	if err != nil {
+		return 0, fmt.Errorf("申请ETCD租约失败: %v", err)
+	}
+
+	if _, err := ed.client.Put(ctx, key, value, clientv3.WithLease(lease.ID)); err != nil {
+		ed.client.Revoke(context.Background(), lease.ID)
+		return 0, fmt.Errorf("写入服务信息失败: %v", err)
+	}
+
+	return lease.ID, nil
+}
+
+// keepAlive 维持租约，租约丢失时（如ETCD重启或网络长时间中断）自动重新注册
+func (ed *EtcdDiscovery) keepAlive(ctx context.Context, reg *etcdRegistration, value string, ttl int64) {
+	leaseID := reg.leaseID
+
+	for {
+		ch, err := ed.client.KeepAlive(ctx, leaseID)
+		if err == nil {
+			// 消费续约响应，channel 关闭说明租约已失效或ctx已取消
+			for range ch {
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(etcdRetryInterval):
+		}
+
+		newLeaseID, err := ed.grantAndPut(ctx, reg.key, value, ttl)
+		if err != nil {
+			continue
+		}
+
+		ed.mu.Lock()
+		reg.leaseID = newLeaseID
+		ed.mu.Unlock()
+		leaseID = newLeaseID
+	}
+}
+
+// Deregister 注销服务，serviceID 不是本实例注册的服务、或同一ID注册在多个服务名下时
+// 需要以 "{serviceName}/{serviceID}" 的形式指定
+func (ed *EtcdDiscovery) Deregister(ctx context.Context, serviceID string) error {
+	serviceName, id, qualified := strings.Cut(serviceID, "/")
+
+	ed.mu.Lock()
+	var reg *etcdRegistration
+	if qualified {
+		reg = ed.registrations[ed.serviceKey(serviceName, id)]
+	} else {
+		for _, r := range ed.registrations {
+			if r.serviceID != serviceID {
+				continue
+			}
+			if reg != nil {
+				ed.mu.Unlock()
+				return fmt.Errorf("服务 %s 注册在多个服务名下，请以 服务名/服务ID 的形式注销", serviceID)
+			}
+			reg = r
+		}
+	}
+	if reg != nil {
+		delete(ed.registrations, reg.key)
+	}
+	ed.mu.Unlock()
+
+	if reg != nil {
+		reg.cancel()
+
+		// 撤销租约会同时删除绑定在其上的key
+		if _, err := ed.client.Revoke(ctx, ed.leaseOf(reg)); err != nil {
+			if _, err := ed.client.Delete(ctx, reg.key); err != nil {
+				return fmt.Errorf("注销服务失败: %v", err)
+			}
+		}
+		return nil
+	}
+
+	// 非本实例注册的服务没有租约信息，必须带上服务名，只删除对应的key
+	if !qualified || serviceName == "" || id == "" {
+		return fmt.Errorf("服务 %s 不是本实例注册的，请以 服务名/服务ID 的形式注销", serviceID)
+	}
+	if _, err := ed.client.Delete(ctx, ed.serviceKey(serviceName, id)); err != nil {
+		return fmt.Errorf("注销服务失败: %v", err)
+	}
+
+	return nil
+}
+
+// Discover 发现服务
+func (ed *EtcdDiscovery) Discover(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	resp, err := ed.client.Get(ctx, ed.servicePrefix(serviceName), clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("发现服务失败: %v", err)
+	}
+
+	var result []*ServiceInfo
+	for _, kv := range resp.Kvs {
+		if info := decodeEtcdService(kv.Value); info != nil {
+			result = append(result, info)
+		}
+	}
+
+	return result, nil
+}
+
+// Watch 监听服务变化
+//
+// 首先推送一次当前的服务列表，之后基于ETCD的watch revision增量更新，
+// 每次变化都会推送完整的服务列表。
+func (ed *EtcdDiscovery) Watch(ctx context.Context, serviceName string) (<-chan []*ServiceInfo, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+
+	ed.mu.Lock()
+	id := fmt.Sprintf("%s#%d", serviceName, time.Now().UnixNano())
+	ed.watchers[id] = cancel
+	ed.mu.Unlock()
+
+	ch := make(chan []*ServiceInfo, 10)
+	go ed.watchService(watchCtx, id, serviceName, ch)
+
+	return ch, nil
+}
+
+// watchService 监听服务变化的goroutine
+func (ed *EtcdDiscovery) watchService(ctx context.Context, id, serviceName string, ch chan []*ServiceInfo) {
+	defer func() {
+		ed.mu.Lock()
+		if cancel, exists := ed.watchers[id]; exists {
+			cancel()
+			delete(ed.watchers, id)
+		}
+		ed.mu.Unlock()
+		close(ch)
+	}()
+
+	prefix := ed.servicePrefix(serviceName)
+
+	for {
+		// 获取全量快照及其revision，之后从下一个revision开始监听
+		services, revision, err := ed.snapshot(ctx, prefix)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(etcdRetryInterval):
+				continue
+			}
+		}
+
+		if !ed.publish(ctx, ch, services) {
+			return
+		}
+
+		if !ed.watchFrom(ctx, prefix, revision+1, services, ch) {
+			return
+		}
+	}
+}
+
+// snapshot 获取前缀下的全量服务及当前revision
+func (ed *EtcdDiscovery) snapshot(ctx context.Context, prefix string) (map[string]*ServiceInfo, int64, error) {
+	resp, err := ed.client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	services := make(map[string]*ServiceInfo, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if info := decodeEtcdService(kv.Value); info != nil {
+			services[string(kv.Key)] = info
+		}
+	}
+
+	return services, resp.Header.Revision, nil
+}
+
+// watchFrom 从指定revision开始监听增量事件，返回false表示应停止监听，
+// 返回true表示需要重新获取快照（如revision已被压缩）
+func (ed *EtcdDiscovery) watchFrom(ctx context.Context, prefix string, revision int64, services map[string]*ServiceInfo, ch chan []*ServiceInfo) bool {
+	watchCtx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+
+	watchCh := ed.client.Watch(watchCtx, prefix, clientv3.WithPrefix(), clientv3.WithRev(revision))
+	for resp := range watchCh {
+		if err := resp.Err(); err != nil {
+			// revision被压缩或leader丢失，重新获取快照
+			return ctx.Err() == nil
+		}
+
+		for _, ev := range resp.Events {
+			key := string(ev.Kv.Key)
+			switch ev.Type {
+			case clientv3.EventTypePut:
+				if info := decodeEtcdService(ev.Kv.Value); info != nil {
+					services[key] = info
+				}
+			case clientv3.EventTypeDelete:
+				delete(services, key)
+			}
+		}
+
+		if len(resp.Events) > 0 && !ed.publish(ctx, ch, services) {
+			return false
+		}
+	}
+
+	return ctx.Err() == nil
+}
+
+// publish 推送服务列表
+func (ed *EtcdDiscovery) publish(ctx context.Context, ch chan []*ServiceInfo, services map[string]*ServiceInfo) bool {
+	result := make([]*ServiceInfo, 0, len(services))
+	for _, info := range services {
+		result = append(result, info)
+	}
+
+	select {
+	case ch <- result:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close 关闭服务发现
+func (ed *EtcdDiscovery) Close() error {
+	ed.mu.Lock()
+	leases := make([]clientv3.LeaseID, 0, len(ed.registrations))
+	for _, reg := range ed.registrations {
+		reg.cancel()
+		leases = append(leases, reg.leaseID)
+	}
+	ed.registrations = make(map[string]*etcdRegistration)
+	for id, cancel := range ed.watchers {
+		cancel()
+		delete(ed.watchers, id)
+	}
+	ed.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultEtcdDialTimeout)
+	defer cancel()
+
+	for _, leaseID := range leases {
+		ed.client.Revoke(ctx, leaseID)
+	}
+
+	// 只关闭自己创建的客户端
+	if ed.ownsClient {
+		return ed.client.Close()
+	}
+
+	return nil
+}
+
+// leaseOf 获取注册信息当前的租约ID（续约goroutine可能已替换租约）
+func (ed *EtcdDiscovery) leaseOf(reg *etcdRegistration) clientv3.LeaseID {
+	ed.mu.RLock()
+	defer ed.mu.RUnlock()
+	return reg.leaseID
+}
+
+// servicePrefix 服务的key前缀
+func (ed *EtcdDiscovery) servicePrefix(serviceName string) string {
+	return path.Join(ed.prefix, serviceName) + "/"
+}
+
+// serviceKey 服务实例的key
+func (ed *EtcdDiscovery) serviceKey(serviceName, serviceID string) string {
+	return path.Join(ed.prefix, serviceName, serviceID)
+}
+
+// decodeEtcdService 解析服务信息
+func decodeEtcdService(value []byte) *ServiceInfo {
+	var info ServiceInfo
+	if err := json.Unmarshal(value, &info); err != nil {
+		return nil
+	}
+	if info.Health == "" {
+		info.Health = "healthy" // 租约有效即视为健康
+	}
+	return &info
+}
@@ -0,0 +1,296 @@
+package discovery
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// startEtcd 启动内嵌ETCD服务器并返回连接到它的客户端
+func startEtcd(t *testing.T) *clientv3.Client {
+	t.Helper()
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	clientURL, _ := url.Parse("http://127.0.0.1:0")
+	peerURL, _ := url.Parse("http://127.0.0.1:0")
+	cfg.ListenClientUrls = []url.URL{*clientURL}
+	cfg.AdvertiseClientUrls = []url.URL{*clientURL}
+	cfg.ListenPeerUrls = []url.URL{*peerURL}
+	cfg.AdvertisePeerUrls = []url.URL{*peerURL}
+	cfg.InitialCluster = cfg.Name + "=" + peerURL.String()
+
+	etcd, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatalf("启动内嵌ETCD失败: %v", err)
+	}
+	t.Cleanup(etcd.Close)
+
+	select {
+	case <-etcd.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("内嵌ETCD启动超时")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{etcd.Clients[0].Addr().String()},
+		DialTimeout: defaultEtcdDialTimeout,
+	})
+	if err != nil {
+		t.Fatalf("创建ETCD客户端失败: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// newTestEtcdDiscovery 创建使用内嵌ETCD的服务发现
+func newTestEtcdDiscovery(t *testing.T, client *clientv3.Client) *EtcdDiscovery {
+	t.Helper()
+
+	ed, err := NewEtcdDiscoveryWithClient(client, "/im-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ed.Close() })
+	return ed
+}
+
+// nextServices 等待 Watch 推送的下一个服务列表
+func nextServices(t *testing.T, ch <-chan []*ServiceInfo) []*ServiceInfo {
+	t.Helper()
+
+	select {
+	case services, ok := <-ch:
+		if !ok {
+			t.Fatal("Watch 通道已关闭")
+		}
+		return services
+	case <-time.After(5 * time.Second):
+		t.Fatal("等待服务列表超时")
+		return nil
+	}
+}
+
+func testService(id string) *ServiceInfo {
+	return &ServiceInfo{ID: id, Name: "im-service", Address: "127.0.0.1", Port: 8083}
+}
+
+func TestEtcdRegisterKeepsLeaseAlive(t *testing.T) {
+	client := startEtcd(t)
+	ed := newTestEtcdDiscovery(t, client)
+	ed.SetLeaseTTL(1)
+
+	ctx := context.Background()
+	if err := ed.Register(ctx, testService("im-1")); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// 超过租约TTL后仍能发现，说明续约在工作
+	time.Sleep(3 * time.Second)
+	services, err := ed.Discover(ctx, "im-service")
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if len(services) != 1 || services[0].ID != "im-1" || services[0].Health != "healthy" {
+		t.Fatalf("Discover = %+v, want im-1", services)
+	}
+
+	// 租约被外部撤销后自动重新注册
+	resp, err := client.Get(ctx, ed.serviceKey("im-service", "im-1"))
+	if err != nil || len(resp.Kvs) != 1 {
+		t.Fatalf("Get = %v, %v", resp, err)
+	}
+	if _, err := client.Revoke(ctx, clientv3.LeaseID(resp.Kvs[0].Lease)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		services, _ := ed.Discover(ctx, "im-service")
+		if len(services) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("租约丢失后没有重新注册")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestEtcdDeregister(t *testing.T) {
+	client := startEtcd(t)
+	ed := newTestEtcdDiscovery(t, client)
+	other := newTestEtcdDiscovery(t, client)
+	third := newTestEtcdDiscovery(t, client)
+	ctx := context.Background()
+
+	for _, id := range []string{"im-1", "im-2"} {
+		if err := ed.Register(ctx, testService(id)); err != nil {
+			t.Fatalf("Register %s: %v", id, err)
+		}
+	}
+	// 其他服务名下的同名ID不受影响
+	if err := third.Register(ctx, &ServiceInfo{ID: "im-2", Name: "other-service", Address: "127.0.0.1", Port: 9000}); err != nil {
+		t.Fatalf("Register other-service: %v", err)
+	}
+
+	if err := ed.Deregister(ctx, "im-1"); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	services, _ := ed.Discover(ctx, "im-service")
+	if len(services) != 1 || services[0].ID != "im-2" {
+		t.Fatalf("Discover after Deregister = %+v, want im-2", services)
+	}
+
+	// 非本实例注册的服务必须带上服务名
+	if err := other.Deregister(ctx, "im-2"); err == nil {
+		t.Fatal("Deregister 未注册的服务ID应返回错误")
+	}
+	if err := other.Deregister(ctx, "im-service/im-2"); err != nil {
+		t.Fatalf("Deregister im-service/im-2: %v", err)
+	}
+	if services, _ := ed.Discover(ctx, "im-service"); len(services) != 0 {
+		t.Fatalf("Discover im-service = %+v, want none", services)
+	}
+	if services, _ := ed.Discover(ctx, "other-service"); len(services) != 1 {
+		t.Fatalf("Discover other-service = %+v, want im-2", services)
+	}
+}
+
+func TestEtcdRegisterSameIDUnderDifferentNames(t *testing.T) {
+	client := startEtcd(t)
+	ed := newTestEtcdDiscovery(t, client)
+	ctx := context.Background()
+
+	// 同一ID注册在不同服务名下时各自持有租约
+	for _, name := range []string{"im-service", "other-service"} {
+		if err := ed.Register(ctx, &ServiceInfo{ID: "im-1", Name: name, Address: "127.0.0.1", Port: 9000}); err != nil {
+			t.Fatalf("Register %s: %v", name, err)
+		}
+	}
+	leaseOf := func(name string) clientv3.LeaseID {
+		t.Helper()
+		resp, err := client.Get(ctx, ed.serviceKey(name, "im-1"))
+		if err != nil || len(resp.Kvs) != 1 {
+			t.Fatalf("Get %s = %v, %v", name, resp, err)
+		}
+		return clientv3.LeaseID(resp.Kvs[0].Lease)
+	}
+	lease := leaseOf("im-service")
+	if other := leaseOf("other-service"); other == lease {
+		t.Fatal("不同服务名下的注册不应共用租约")
+	}
+
+	// 只给出ID时无法确定注销哪一个
+	if err := ed.Deregister(ctx, "im-1"); err == nil {
+		t.Fatal("同一ID注册在多个服务名下时 Deregister 应要求带上服务名")
+	}
+	if err := ed.Deregister(ctx, "other-service/im-1"); err != nil {
+		t.Fatalf("Deregister other-service/im-1: %v", err)
+	}
+	if services, _ := ed.Discover(ctx, "other-service"); len(services) != 0 {
+		t.Fatalf("Discover other-service = %+v, want none", services)
+	}
+
+	// 另一个服务名下的注册和租约不受影响
+	ttl, err := client.TimeToLive(ctx, lease)
+	if err != nil || ttl.TTL <= 0 {
+		t.Fatalf("im-service 的租约 TTL = %v, %v, want 仍然有效", ttl, err)
+	}
+	if services, _ := ed.Discover(ctx, "im-service"); len(services) != 1 {
+		t.Fatalf("Discover im-service = %+v, want im-1", services)
+	}
+	if err := ed.Deregister(ctx, "im-1"); err != nil {
+		t.Fatalf("Deregister im-1: %v", err)
+	}
+	if services, _ := ed.Discover(ctx, "im-service"); len(services) != 0 {
+		t.Fatalf("Discover im-service = %+v, want none", services)
+	}
+}
+
+func TestEtcdWatch(t *testing.T) {
+	client := startEtcd(t)
+	ed := newTestEtcdDiscovery(t, client)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := ed.Register(ctx, testService("im-1")); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	ch, err := ed.Watch(ctx, "im-service")
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if services := nextServices(t, ch); len(services) != 1 {
+		t.Fatalf("初始服务列表 = %+v, want im-1", services)
+	}
+
+	// put
+	if err := ed.Register(ctx, testService("im-2")); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if services := nextServices(t, ch); len(services) != 2 {
+		t.Fatalf("put 后的服务列表 = %+v, want 2", services)
+	}
+
+	// delete
+	if err := ed.Deregister(ctx, "im-1"); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if services := nextServices(t, ch); len(services) != 1 || services[0].ID != "im-2" {
+		t.Fatalf("delete 后的服务列表 = %+v, want im-2", services)
+	}
+}
+
+func TestEtcdWatchAfterCompaction(t *testing.T) {
+	client := startEtcd(t)
+	ed := newTestEtcdDiscovery(t, client)
+	ctx := context.Background()
+
+	if err := ed.Register(ctx, testService("im-1")); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// 从已被压缩的revision开始监听，应重新获取快照而不是停止
+	services, revision, err := ed.snapshot(ctx, ed.servicePrefix("im-service"))
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if err := ed.Register(ctx, testService("im-2")); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	resp, err := client.Put(ctx, "/unrelated", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Compact(ctx, resp.Header.Revision); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+
+	ch := make(chan []*ServiceInfo, 10)
+	if again := ed.watchFrom(ctx, ed.servicePrefix("im-service"), revision+1, services, ch); !again {
+		t.Fatal("revision 被压缩后 watchFrom 应要求重新获取快照")
+	}
+
+	// 完整的 Watch 在压缩后仍然推送最新的服务列表
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watchCh, err := ed.Watch(watchCtx, "im-service")
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if services := nextServices(t, watchCh); len(services) != 2 {
+		t.Fatalf("压缩后的服务列表 = %+v, want 2", services)
+	}
+	if err := ed.Register(ctx, testService("im-3")); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if services := nextServices(t, watchCh); len(services) != 3 {
+		t.Fatalf("压缩后 put 的服务列表 = %+v, want 3", services)
+	}
+}
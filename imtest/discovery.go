@@ -0,0 +1,172 @@
+package imtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Dev-Umb/im-grpc-sdk/discovery"
+)
+
+// Discovery 可编程的内存服务发现，SetServices 会立即推送给所有 Watch 的订阅者
+type Discovery struct {
+	services    map[string][]*discovery.ServiceInfo
+	watchers    map[string][]chan []*discovery.ServiceInfo
+	discoverErr error
+	discovers   int
+	closed      bool
+	mu          sync.Mutex
+}
+
+// NewDiscovery 创建内存服务发现
+func NewDiscovery() *Discovery {
+	return &Discovery{
+		services: make(map[string][]*discovery.ServiceInfo),
+		watchers: make(map[string][]chan []*discovery.ServiceInfo),
+	}
+}
+
+// SetServices 设置服务列表并推送给订阅者
+func (d *Discovery) SetServices(serviceName string, services ...*discovery.ServiceInfo) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.services[serviceName] = services
+	d.publishLocked(serviceName)
+}
+
+// FailDiscover 设置 Discover 返回的错误，传入nil恢复正常
+func (d *Discovery) FailDiscover(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.discoverErr = err
+}
+
+// DiscoverCalls 返回 Discover 被调用的次数
+func (d *Discovery) DiscoverCalls() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.discovers
+}
+
+// Register 注册服务
+func (d *Discovery) Register(ctx context.Context, service *discovery.ServiceInfo) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	services := d.services[service.Name]
+	for i, existing := range services {
+		if existing.ID == service.ID {
+			services[i] = service
+			d.publishLocked(service.Name)
+			return nil
+		}
+	}
+
+	d.services[service.Name] = append(services, service)
+	d.publishLocked(service.Name)
+	return nil
+}
+
+// Deregister 注销服务
+func (d *Discovery) Deregister(ctx context.Context, serviceID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name, services := range d.services {
+		for i, service := range services {
+			if service.ID == serviceID {
+				d.services[name] = append(services[:i:i], services[i+1:]...)
+				d.publishLocked(name)
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Discover 发现服务
+func (d *Discovery) Discover(ctx context.Context, serviceName string) ([]*discovery.ServiceInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.discovers++
+	if d.discoverErr != nil {
+		return nil, d.discoverErr
+	}
+	return d.snapshotLocked(serviceName), nil
+}
+
+// Watch 监听服务变化，订阅后立即推送一次当前服务列表
+func (d *Discovery) Watch(ctx context.Context, serviceName string) (<-chan []*discovery.ServiceInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ch := make(chan []*discovery.ServiceInfo, 16)
+	if d.closed {
+		close(ch)
+		return ch, nil
+	}
+
+	ch <- d.snapshotLocked(serviceName)
+	d.watchers[serviceName] = append(d.watchers[serviceName], ch)
+
+	go func() {
+		<-ctx.Done()
+		d.removeWatcher(serviceName, ch)
+	}()
+
+	return ch, nil
+}
+
+// Close 关闭服务发现
+func (d *Discovery) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.closed = true
+	for name, watchers := range d.watchers {
+		for _, ch := range watchers {
+			close(ch)
+		}
+		delete(d.watchers, name)
+	}
+	return nil
+}
+
+// removeWatcher 移除订阅者
+func (d *Discovery) removeWatcher(serviceName string, ch chan []*discovery.ServiceInfo) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	watchers := d.watchers[serviceName]
+	for i, w := range watchers {
+		if w == ch {
+			d.watchers[serviceName] = append(watchers[:i:i], watchers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// publishLocked 推送服务列表，订阅者缓冲已满时丢弃最旧的一次推送，调用时需持有锁
+func (d *Discovery) publishLocked(serviceName string) {
+	for _, ch := range d.watchers[serviceName] {
+		services := d.snapshotLocked(serviceName)
+		select {
+		case ch <- services:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- services
+		}
+	}
+}
+
+// snapshotLocked 返回服务列表的副本，调用时需持有锁
+func (d *Discovery) snapshotLocked(serviceName string) []*discovery.ServiceInfo {
+	services := make([]*discovery.ServiceInfo, len(d.services[serviceName]))
+	copy(services, d.services[serviceName])
+	return services
+}
@@ -0,0 +1,171 @@
+package imtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// Recorder 线程安全的消息记录器，支持等待满足条件的消息
+//
+// 客户端侧可直接用作消息回调：config.OnMessage = recorder.Record
+type Recorder[T any] struct {
+	items   []T
+	changed chan struct{}
+	mu      sync.Mutex
+}
+
+// NewRecorder 创建消息记录器
+func NewRecorder[T any]() *Recorder[T] {
+	return &Recorder[T]{
+		changed: make(chan struct{}),
+	}
+}
+
+// Record 记录一条消息
+func (r *Recorder[T]) Record(item T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items = append(r.items, item)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// All 返回已记录的所有消息
+func (r *Recorder[T]) All() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]T, len(r.items))
+	copy(result, r.items)
+	return result
+}
+
+// Len 返回已记录的消息数量
+func (r *Recorder[T]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.items)
+}
+
+// Reset 清空记录
+func (r *Recorder[T]) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = nil
+}
+
+// Filter 返回满足条件的消息
+func (r *Recorder[T]) Filter(match func(T) bool) []T {
+	var result []T
+	for _, item := range r.All() {
+		if match(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// WaitFor 等待第一条满足条件的消息（包括已记录的消息）
+func (r *Recorder[T]) WaitFor(ctx context.Context, match func(T) bool) (T, error) {
+	var zero T
+	seen := 0
+
+	for {
+		r.mu.Lock()
+		if seen > len(r.items) {
+			seen = 0 // 期间调用了 Reset
+		}
+		items := r.items[seen:]
+		changed := r.changed
+		seen = len(r.items)
+		r.mu.Unlock()
+
+		for _, item := range items {
+			if match(item) {
+				return item, nil
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return zero, fmt.Errorf("imtest: 等待消息超时: %v", ctx.Err())
+		}
+	}
+}
+
+// WaitForCount 等待至少 n 条满足条件的消息，返回前 n 条
+func (r *Recorder[T]) WaitForCount(ctx context.Context, n int, match func(T) bool) ([]T, error) {
+	for {
+		r.mu.Lock()
+		changed := r.changed
+		r.mu.Unlock()
+
+		if matched := r.Filter(match); len(matched) >= n {
+			return matched[:n], nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("imtest: 等待 %d 条消息超时: %v", n, ctx.Err())
+		}
+	}
+}
+
+// Any 匹配任意消息
+func Any[T any](T) bool {
+	return true
+}
+
+// MessageMatcher 服务端下发消息的匹配条件
+type MessageMatcher func(*imv1.MessageResponse) bool
+
+// RequestMatcher 客户端发送消息的匹配条件
+type RequestMatcher func(*imv1.MessageRequest) bool
+
+// MessageOfType 匹配指定类型的下发消息
+func MessageOfType(msgType imv1.MessageType) MessageMatcher {
+	return func(msg *imv1.MessageResponse) bool {
+		return msg.Type == msgType
+	}
+}
+
+// MessageFrom 匹配指定用户在指定房间发送的消息，roomID 为空时匹配任意房间
+func MessageFrom(userID, roomID string) MessageMatcher {
+	return func(msg *imv1.MessageResponse) bool {
+		return msg.FromUserId == userID && (roomID == "" || msg.RoomId == roomID)
+	}
+}
+
+// TextMessage 匹配内容为指定文本的下发文本消息（raw编码）
+func TextMessage(text string) MessageMatcher {
+	return func(msg *imv1.MessageResponse) bool {
+		return msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT && string(msg.Content) == text
+	}
+}
+
+// RequestOfType 匹配指定类型的客户端消息
+func RequestOfType(msgType imv1.MessageType) RequestMatcher {
+	return func(msg *imv1.MessageRequest) bool {
+		return msg.Type == msgType
+	}
+}
+
+// RequestInRoom 匹配发往指定房间的客户端消息
+func RequestInRoom(roomID string) RequestMatcher {
+	return func(msg *imv1.MessageRequest) bool {
+		return msg.RoomId == roomID
+	}
+}
+
+// TextRequest 匹配内容为指定文本的客户端文本消息（raw编码）
+func TextRequest(text string) RequestMatcher {
+	return func(msg *imv1.MessageRequest) bool {
+		return msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT && string(msg.Content) == text
+	}
+}
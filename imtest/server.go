@@ -0,0 +1,212 @@
+// Package imtest 提供进程内测试工具：基于 bufconn 的 IMService 服务端、
+// 可编程的服务发现以及消息断言辅助函数，无需真实网络即可测试客户端和机器人。
+package imtest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Dev-Umb/im-grpc-sdk/discovery"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+	"github.com/Dev-Umb/im-grpc-sdk/server"
+)
+
+const bufSize = 1024 * 1024
+
+var serverSeq atomic.Uint64
+
+// Server 运行在 bufconn 上的 IMService 测试服务端
+//
+// 业务逻辑由 server 包的参考实现提供，额外记录客户端通过流发送的所有消息，
+// 并支持主动断开所有流以测试客户端重连。
+type Server struct {
+	*server.Server
+
+	address    string
+	listener   *bufconn.Listener
+	grpcServer *grpc.Server
+
+	received *Recorder[*imv1.MessageRequest]
+
+	streams    map[uint64]context.CancelCauseFunc
+	nextStream uint64
+	mu         sync.Mutex
+}
+
+// NewServer 创建并启动测试服务端，config 为nil时使用 server.DefaultConfig
+func NewServer(config *server.Config) *Server {
+	s := &Server{
+		Server:   server.NewServer(config),
+		address:  fmt.Sprintf("imtest-%d", serverSeq.Add(1)),
+		listener: bufconn.Listen(bufSize),
+		received: NewRecorder[*imv1.MessageRequest](),
+		streams:  make(map[uint64]context.CancelCauseFunc),
+	}
+
+	s.grpcServer = grpc.NewServer(grpc.ChainStreamInterceptor(s.streamInterceptor))
+	s.Register(s.grpcServer)
+	go s.grpcServer.Serve(s.listener)
+
+	return s
+}
+
+// Address 返回服务端的虚拟地址
+func (s *Server) Address() string {
+	return s.address
+}
+
+// ServiceInfo 返回可交给负载均衡器或 Discovery 的服务信息
+func (s *Server) ServiceInfo() *discovery.ServiceInfo {
+	return &discovery.ServiceInfo{
+		ID:      s.address,
+		Name:    "im-service",
+		Address: s.address,
+		Port:    0,
+		Health:  "healthy",
+	}
+}
+
+// Dialer 返回连接到该服务端的拨号函数，可设置到 client.Config.Dialer
+func (s *Server) Dialer() func(ctx context.Context, address string) (net.Conn, error) {
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		return s.listener.DialContext(ctx)
+	}
+}
+
+// Dial 创建连接到该服务端的gRPC连接
+func (s *Server) Dial() (*grpc.ClientConn, error) {
+	return grpc.NewClient("passthrough:///"+s.address,
+		grpc.WithContextDialer(s.Dialer()),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+// NewIMServiceClient 创建连接到该服务端的gRPC客户端，可用于 client.NewClientWithGRPC
+func (s *Server) NewIMServiceClient() (imv1.IMServiceClient, *grpc.ClientConn, error) {
+	conn, err := s.Dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	return imv1.NewIMServiceClient(conn), conn, nil
+}
+
+// Received 返回客户端通过流发送的消息记录
+func (s *Server) Received() *Recorder[*imv1.MessageRequest] {
+	return s.received
+}
+
+// Push 直接向用户的所有消息流推送消息，返回投递的流数量
+func (s *Server) Push(userID string, msg *imv1.MessageResponse) int {
+	return s.Rooms().SendToUser(userID, msg)
+}
+
+// DropStreams 以 Unavailable 错误断开所有活跃的消息流，用于测试客户端重连
+func (s *Server) DropStreams() int {
+	return s.FailStreams(status.Error(codes.Unavailable, "imtest: 流已被断开"))
+}
+
+// FailStreams 以指定错误结束所有活跃的消息流，例如 codes.Unauthenticated 用于测试客户端重新认证
+func (s *Server) FailStreams(err error) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.streams)
+	for id, cancel := range s.streams {
+		cancel(err)
+		delete(s.streams, id)
+	}
+	return n
+}
+
+// ActiveStreams 返回活跃的消息流数量
+func (s *Server) ActiveStreams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.streams)
+}
+
+// Close 停止服务端
+func (s *Server) Close() {
+	s.DropStreams()
+	s.grpcServer.Stop()
+	s.listener.Close()
+}
+
+// streamInterceptor 记录流上收到的消息，并允许主动断开流
+func (s *Server) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if info.FullMethod != imv1.IMService_StreamMessages_FullMethodName {
+		return handler(srv, ss)
+	}
+
+	ctx, cancel := context.WithCancelCause(ss.Context())
+	defer cancel(nil)
+
+	s.mu.Lock()
+	s.nextStream++
+	id := s.nextStream
+	s.streams[id] = cancel
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, id)
+		s.mu.Unlock()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- handler(srv, &recordingStream{ServerStream: ss, received: s.received})
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// 返回后gRPC会结束该流，处理goroutine随之退出
+		return context.Cause(ctx)
+	}
+}
+
+// recordingStream 记录收到的消息
+type recordingStream struct {
+	grpc.ServerStream
+	received *Recorder[*imv1.MessageRequest]
+}
+
+// RecvMsg 接收并记录消息
+func (rs *recordingStream) RecvMsg(m interface{}) error {
+	if err := rs.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(*imv1.MessageRequest); ok {
+		rs.received.Record(msg)
+	}
+	return nil
+}
+
+// Dialer 返回按地址路由到多个测试服务端的拨号函数，用于测试负载均衡和切换实例
+func Dialer(servers ...*Server) func(ctx context.Context, address string) (net.Conn, error) {
+	return func(ctx context.Context, address string) (net.Conn, error) {
+		host := address
+		if i := strings.LastIndex(address, ":"); i >= 0 {
+			host = address[:i]
+		}
+
+		for _, s := range servers {
+			if s.address == host {
+				return s.listener.DialContext(ctx)
+			}
+		}
+		return nil, fmt.Errorf("imtest: 未知的服务地址 %s", address)
+	}
+}
@@ -0,0 +1,129 @@
+package imtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dev-Umb/im-grpc-sdk/client"
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const testTimeout = 5 * time.Second
+
+// waitFor 轮询等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitState 等待客户端进入指定状态
+func waitState(t *testing.T, c *client.Client, state client.State) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err := c.WaitForState(ctx, state); err != nil {
+		t.Fatalf("等待状态 %s 失败，当前状态 %s: %v", state, c.State(), err)
+	}
+}
+
+func TestDropStreamsFailsOverToNewInstance(t *testing.T) {
+	srv1 := imtest.NewServer(nil)
+	defer srv1.Close()
+	srv2 := imtest.NewServer(nil)
+	defer srv2.Close()
+
+	disc := imtest.NewDiscovery()
+	disc.SetServices("im-service", srv1.ServiceInfo())
+
+	config := client.DefaultConfig()
+	config.UserID = "alice"
+	config.Discovery = disc
+	config.Dialer = imtest.Dialer(srv1, srv2)
+	config.Backoff = client.NewConstantBackoff(50*time.Millisecond, 0)
+	outbox := client.NewMemoryOutbox()
+	config.Outbox = outbox
+
+	c, err := client.NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	waitFor(t, "消息流建立", func() bool { return srv1.ActiveStreams() == 1 })
+
+	// 实例下线：服务发现切换到 srv2 后断开 srv1 上的消息流
+	disc.FailDiscover(errors.New("discovery down"))
+	disc.SetServices("im-service", srv2.ServiceInfo())
+	if n := srv1.DropStreams(); n != 1 {
+		t.Fatalf("DropStreams = %d, want 1", n)
+	}
+	waitState(t, c, client.StateReconnecting)
+
+	if err := c.SendTextMessage("room1", "offline"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+
+	disc.FailDiscover(nil)
+	waitState(t, c, client.StateReady)
+
+	// 新实例上自动重新加入房间，并补发断开期间发件箱中的消息
+	waitFor(t, "重新加入房间", func() bool { return srv2.Rooms().IsMember("room1", "alice") })
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	msg, err := srv2.Received().WaitFor(ctx, imtest.TextRequest("offline"))
+	if err != nil {
+		t.Fatalf("srv2 没有收到发件箱中的消息: %v", err)
+	}
+	if msg.RoomId != "room1" {
+		t.Fatalf("RoomId = %s, want room1", msg.RoomId)
+	}
+	waitFor(t, "发件箱清空", func() bool { return outbox.Len() == 0 })
+
+	if n := srv1.ActiveStreams(); n != 0 {
+		t.Fatalf("srv1.ActiveStreams = %d, want 0", n)
+	}
+	if got := srv1.Received().Filter(imtest.TextRequest("offline")); len(got) != 0 {
+		t.Fatalf("srv1 不应收到断开后发送的消息: %v", got)
+	}
+}
+
+func TestRecorderWaitForCount(t *testing.T) {
+	r := imtest.NewRecorder[*imv1.MessageRequest]()
+	go func() {
+		for _, text := range []string{"a", "b", "c"} {
+			r.Record(&imv1.MessageRequest{Type: imv1.MessageType_MESSAGE_TYPE_TEXT, Content: []byte(text)})
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	got, err := r.WaitForCount(ctx, 3, imtest.RequestOfType(imv1.MessageType_MESSAGE_TYPE_TEXT))
+	if err != nil {
+		t.Fatalf("WaitForCount: %v", err)
+	}
+	if len(got) != 3 || string(got[2].Content) != "c" {
+		t.Fatalf("WaitForCount = %v", got)
+	}
+
+	r.Reset()
+	if r.Len() != 0 {
+		t.Fatalf("Reset 后 Len = %d, want 0", r.Len())
+	}
+}
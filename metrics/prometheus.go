@@ -0,0 +1,174 @@
+// Package metrics 提供 client.Metrics 的 Prometheus 实现
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+
+	"github.com/Dev-Umb/im-grpc-sdk/client"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const namespace = "im_client"
+
+// Prometheus 基于 Prometheus 的客户端指标收集器
+//
+// 同一个 Registerer 上创建多个收集器时复用已注册的指标，多个客户端的数据会汇总在一起。
+type Prometheus struct {
+	messagesSent     *prometheus.CounterVec
+	messagesReceived *prometheus.CounterVec
+	sendLatency      prometheus.Histogram
+	queueDepth       prometheus.Gauge
+	rpcDuration      *prometheus.HistogramVec
+	reconnects       *prometheus.CounterVec
+	heartbeatRTT     prometheus.Histogram
+	discoveryUpdates prometheus.Counter
+	services         prometheus.Gauge
+}
+
+var _ client.Metrics = (*Prometheus)(nil)
+
+// NewPrometheus 创建并注册 Prometheus 指标，registerer 为nil时使用 prometheus.DefaultRegisterer
+func NewPrometheus(registerer prometheus.Registerer) (*Prometheus, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+
+	p := &Prometheus{
+		messagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_sent_total",
+			Help:      "写入消息流的消息数量",
+		}, []string{"type"}),
+		messagesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_received_total",
+			Help:      "从消息流收到的消息数量",
+		}, []string{"type"}),
+		sendLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "send_latency_seconds",
+			Help:      "消息从创建到写入消息流的耗时",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "send_queue_depth",
+			Help:      "发送队列中等待发送的消息数量",
+		}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "RPC调用耗时",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		reconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reconnect_attempts_total",
+			Help:      "重连尝试次数",
+		}, []string{"outcome"}),
+		heartbeatRTT: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "heartbeat_rtt_seconds",
+			Help:      "心跳往返时间",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		discoveryUpdates: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "discovery_updates_total",
+			Help:      "服务发现推送服务列表的次数",
+		}),
+		services: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "discovered_services",
+			Help:      "服务发现最近一次推送的服务数量",
+		}),
+	}
+
+	var err error
+	if p.messagesSent, err = register(registerer, p.messagesSent); err != nil {
+		return nil, err
+	}
+	if p.messagesReceived, err = register(registerer, p.messagesReceived); err != nil {
+		return nil, err
+	}
+	if p.sendLatency, err = register(registerer, p.sendLatency); err != nil {
+		return nil, err
+	}
+	if p.queueDepth, err = register(registerer, p.queueDepth); err != nil {
+		return nil, err
+	}
+	if p.rpcDuration, err = register(registerer, p.rpcDuration); err != nil {
+		return nil, err
+	}
+	if p.reconnects, err = register(registerer, p.reconnects); err != nil {
+		return nil, err
+	}
+	if p.heartbeatRTT, err = register(registerer, p.heartbeatRTT); err != nil {
+		return nil, err
+	}
+	if p.discoveryUpdates, err = register(registerer, p.discoveryUpdates); err != nil {
+		return nil, err
+	}
+	if p.services, err = register(registerer, p.services); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// MessageSent 记录写入流的消息
+func (p *Prometheus) MessageSent(msgType imv1.MessageType, latency time.Duration) {
+	p.messagesSent.WithLabelValues(msgType.String()).Inc()
+	if latency > 0 {
+		p.sendLatency.Observe(latency.Seconds())
+	}
+}
+
+// MessageReceived 记录收到的消息
+func (p *Prometheus) MessageReceived(msgType imv1.MessageType) {
+	p.messagesReceived.WithLabelValues(msgType.String()).Inc()
+}
+
+// QueueDepth 记录发送队列深度
+func (p *Prometheus) QueueDepth(depth int) {
+	p.queueDepth.Set(float64(depth))
+}
+
+// RPCCompleted 记录RPC调用耗时
+func (p *Prometheus) RPCCompleted(method string, latency time.Duration, err error) {
+	p.rpcDuration.WithLabelValues(method, status.Code(err).String()).Observe(latency.Seconds())
+}
+
+// ReconnectAttempt 记录重连结果
+func (p *Prometheus) ReconnectAttempt(outcome client.ReconnectOutcome) {
+	p.reconnects.WithLabelValues(string(outcome)).Inc()
+}
+
+// HeartbeatRTT 记录心跳往返时间
+func (p *Prometheus) HeartbeatRTT(rtt time.Duration) {
+	p.heartbeatRTT.Observe(rtt.Seconds())
+}
+
+// DiscoveryUpdate 记录服务发现推送
+func (p *Prometheus) DiscoveryUpdate(services int) {
+	p.discoveryUpdates.Inc()
+	p.services.Set(float64(services))
+}
+
+// register 注册指标，已注册过同名指标时返回已有的指标
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	if err := registerer.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return collector, err
+	}
+	return collector, nil
+}
@@ -0,0 +1,152 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Dev-Umb/im-grpc-sdk/client"
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	"github.com/Dev-Umb/im-grpc-sdk/metrics"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+const testTimeout = 5 * time.Second
+
+// counterValue 返回带有 label 的计数器的当前值，没有该指标时为0
+func counterValue(t *testing.T, gatherer prometheus.Gatherer, name, label, value string) float64 {
+	t.Helper()
+
+	families, err := gatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == label && pair.GetValue() == value {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// waitCounter 等待计数器达到 want
+func waitCounter(t *testing.T, gatherer prometheus.Gatherer, name, label, value string, want float64) {
+	t.Helper()
+
+	deadline := time.Now().Add(testTimeout)
+	for {
+		got := counterValue(t, gatherer, name, label, value)
+		if got >= want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s{%s=%q} = %v, want %v", name, label, value, got, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPrometheusCountsMessages(t *testing.T) {
+	srv := imtest.NewServer(nil)
+	defer srv.Close()
+	registry := prometheus.NewRegistry()
+	collector, err := metrics.NewPrometheus(registry)
+	if err != nil {
+		t.Fatalf("NewPrometheus: %v", err)
+	}
+
+	disc := imtest.NewDiscovery()
+	disc.SetServices("im-service", srv.ServiceInfo())
+	config := client.DefaultConfig()
+	config.UserID = "alice"
+	config.Discovery = disc
+	config.Dialer = srv.Dialer()
+	config.Metrics = collector
+	c, err := client.NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer c.Disconnect()
+
+	if _, err := c.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	text := imv1.MessageType_MESSAGE_TYPE_TEXT.String()
+	for i := 0; i < 3; i++ {
+		if err := c.SendTextMessage("room1", "hello"); err != nil {
+			t.Fatalf("SendTextMessage: %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if _, err := srv.Received().WaitForCount(ctx, 3, imtest.TextRequest("hello")); err != nil {
+		t.Fatalf("服务端没有收到消息: %v", err)
+	}
+	waitCounter(t, registry, "im_client_messages_sent_total", "type", text, 3)
+
+	// 推送给客户端的消息计入接收计数
+	deadline := time.Now().Add(testTimeout)
+	for srv.Push("alice", &imv1.MessageResponse{
+		MessageId:  "pushed",
+		Type:       imv1.MessageType_MESSAGE_TYPE_TEXT,
+		RoomId:     "room1",
+		FromUserId: "bob",
+		Content:    []byte("hi"),
+	}) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("服务端没有登记 alice 的消息流")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	waitCounter(t, registry, "im_client_messages_received_total", "type", text, 1)
+
+	// 一元调用按方法和状态码记录耗时
+	if n := testutil.CollectAndCount(registry, "im_client_rpc_duration_seconds"); n == 0 {
+		t.Fatal("JoinRoom 没有记录RPC耗时")
+	}
+}
+
+func TestNewPrometheusReusesRegisteredCollectors(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	first, err := metrics.NewPrometheus(registry)
+	if err != nil {
+		t.Fatalf("NewPrometheus: %v", err)
+	}
+	second, err := metrics.NewPrometheus(registry)
+	if err != nil {
+		t.Fatalf("同一个 Registerer 上再次创建: %v", err)
+	}
+
+	// 两个收集器的数据汇总在同一组指标中
+	text := imv1.MessageType_MESSAGE_TYPE_TEXT
+	first.MessageReceived(text)
+	second.MessageReceived(text)
+	second.ReconnectAttempt(client.ReconnectFailure)
+	second.RPCCompleted("JoinRoom", time.Millisecond, nil)
+	first.RPCCompleted("JoinRoom", time.Millisecond, status.Error(codes.NotFound, "房间不存在"))
+
+	if got := counterValue(t, registry, "im_client_messages_received_total", "type", text.String()); got != 2 {
+		t.Fatalf("messages_received_total = %v, want 2", got)
+	}
+	if got := counterValue(t, registry, "im_client_reconnect_attempts_total", "outcome", "failure"); got != 1 {
+		t.Fatalf("reconnect_attempts_total = %v, want 1", got)
+	}
+	if n := testutil.CollectAndCount(registry, "im_client_rpc_duration_seconds"); n != 2 {
+		t.Fatalf("rpc_duration_seconds 有 %d 组标签, want OK 和 NotFound 两组", n)
+	}
+}
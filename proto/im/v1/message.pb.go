@@ -35,6 +35,7 @@ const (
 	MessageType_MESSAGE_TYPE_JOIN_ROOM   MessageType = 6
 	MessageType_MESSAGE_TYPE_LEAVE_ROOM  MessageType = 7
 	MessageType_MESSAGE_TYPE_HEARTBEAT   MessageType = 8
+	MessageType_MESSAGE_TYPE_ATTACHMENT  MessageType = 9
 )
 
 // Enum value maps for MessageType.
@@ -49,6 +50,7 @@ var (
 		6: "MESSAGE_TYPE_JOIN_ROOM",
 		7: "MESSAGE_TYPE_LEAVE_ROOM",
 		8: "MESSAGE_TYPE_HEARTBEAT",
+		9: "MESSAGE_TYPE_ATTACHMENT",
 	}
 	MessageType_value = map[string]int32{
 		"MESSAGE_TYPE_UNSPECIFIED": 0,
@@ -60,6 +62,7 @@ var (
 		"MESSAGE_TYPE_JOIN_ROOM":   6,
 		"MESSAGE_TYPE_LEAVE_ROOM":  7,
 		"MESSAGE_TYPE_HEARTBEAT":   8,
+		"MESSAGE_TYPE_ATTACHMENT":  9,
 	}
 )
 
@@ -90,6 +93,56 @@ func (MessageType) EnumDescriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{0}
 }
 
+// 历史消息翻页方向
+type HistoryDirection int32
+
+const (
+	HistoryDirection_HISTORY_DIRECTION_UNSPECIFIED HistoryDirection = 0 // 同 BACKWARD
+	HistoryDirection_HISTORY_DIRECTION_BACKWARD    HistoryDirection = 1 // 从新到旧
+	HistoryDirection_HISTORY_DIRECTION_FORWARD     HistoryDirection = 2 // 从旧到新
+)
+
+// Enum value maps for HistoryDirection.
+var (
+	HistoryDirection_name = map[int32]string{
+		0: "HISTORY_DIRECTION_UNSPECIFIED",
+		1: "HISTORY_DIRECTION_BACKWARD",
+		2: "HISTORY_DIRECTION_FORWARD",
+	}
+	HistoryDirection_value = map[string]int32{
+		"HISTORY_DIRECTION_UNSPECIFIED": 0,
+		"HISTORY_DIRECTION_BACKWARD":    1,
+		"HISTORY_DIRECTION_FORWARD":     2,
+	}
+)
+
+func (x HistoryDirection) Enum() *HistoryDirection {
+	p := new(HistoryDirection)
+	*p = x
+	return p
+}
+
+func (x HistoryDirection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HistoryDirection) Descriptor() protoreflect.EnumDescriptor {
+	return file_message_proto_enumTypes[1].Descriptor()
+}
+
+func (HistoryDirection) Type() protoreflect.EnumType {
+	return &file_message_proto_enumTypes[1]
+}
+
+func (x HistoryDirection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HistoryDirection.Descriptor instead.
+func (HistoryDirection) EnumDescriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{1}
+}
+
 // 用户角色枚举
 type UserRole int32
 
@@ -127,11 +180,11 @@ func (x UserRole) String() string {
 }
 
 func (UserRole) Descriptor() protoreflect.EnumDescriptor {
-	return file_message_proto_enumTypes[1].Descriptor()
+	return file_message_proto_enumTypes[2].Descriptor()
 }
 
 func (UserRole) Type() protoreflect.EnumType {
-	return &file_message_proto_enumTypes[1]
+	return &file_message_proto_enumTypes[2]
 }
 
 func (x UserRole) Number() protoreflect.EnumNumber {
@@ -140,7 +193,7 @@ func (x UserRole) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use UserRole.Descriptor instead.
 func (UserRole) EnumDescriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{1}
+	return file_message_proto_rawDescGZIP(), []int{2}
 }
 
 // 转写状态枚举
@@ -183,11 +236,11 @@ func (x TranscriptStatus) String() string {
 }
 
 func (TranscriptStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_message_proto_enumTypes[2].Descriptor()
+	return file_message_proto_enumTypes[3].Descriptor()
 }
 
 func (TranscriptStatus) Type() protoreflect.EnumType {
-	return &file_message_proto_enumTypes[2]
+	return &file_message_proto_enumTypes[3]
 }
 
 func (x TranscriptStatus) Number() protoreflect.EnumNumber {
@@ -196,7 +249,7 @@ func (x TranscriptStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use TranscriptStatus.Descriptor instead.
 func (TranscriptStatus) EnumDescriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{2}
+	return file_message_proto_rawDescGZIP(), []int{3}
 }
 
 // 健康状态枚举
@@ -236,11 +289,11 @@ func (x HealthStatus) String() string {
 }
 
 func (HealthStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_message_proto_enumTypes[3].Descriptor()
+	return file_message_proto_enumTypes[4].Descriptor()
 }
 
 func (HealthStatus) Type() protoreflect.EnumType {
-	return &file_message_proto_enumTypes[3]
+	return &file_message_proto_enumTypes[4]
 }
 
 func (x HealthStatus) Number() protoreflect.EnumNumber {
@@ -249,7 +302,7 @@ func (x HealthStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthStatus.Descriptor instead.
 func (HealthStatus) EnumDescriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{3}
+	return file_message_proto_rawDescGZIP(), []int{4}
 }
 
 // 消息请求
@@ -356,6 +409,7 @@ type MessageResponse struct {
 	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	AckRequired   bool                   `protobuf:"varint,8,opt,name=ack_required,json=ackRequired,proto3" json:"ack_required,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,9,opt,name=sequence,proto3" json:"sequence,omitempty"` // 服务端分配的房间内序号，从1开始连续递增；不属于房间消息序列的消息（如ACK、心跳）为0
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -446,6 +500,13 @@ func (x *MessageResponse) GetAckRequired() bool {
 	return false
 }
 
+func (x *MessageResponse) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
 // 发送消息请求
 type SendMessageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -455,6 +516,7 @@ type SendMessageRequest struct {
 	Content       []byte                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
 	Metadata      map[string]string      `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	AckRequired   bool                   `protobuf:"varint,6,opt,name=ack_required,json=ackRequired,proto3" json:"ack_required,omitempty"`
+	MessageId     string                 `protobuf:"bytes,7,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"` // 客户端生成的消息ID，重试时保持不变，服务端据此去重；为空时由服务端分配
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -531,12 +593,20 @@ func (x *SendMessageRequest) GetAckRequired() bool {
 	return false
 }
 
+func (x *SendMessageRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
 // 发送消息响应
 type SendMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
 	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	Status        *ResponseStatus        `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Sequence      uint64                 `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"` // 消息在房间内的序号
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -592,6 +662,13 @@ func (x *SendMessageResponse) GetStatus() *ResponseStatus {
 	return nil
 }
 
+func (x *SendMessageResponse) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
 // 加入房间请求
 type JoinRoomRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -926,6 +1003,172 @@ func (x *GetRoomInfoResponse) GetUsers() []*RoomUser {
 	return nil
 }
 
+// 获取历史消息请求
+//
+// cursor 为空时从 start_time 开始翻页：向后翻页返回 start_time 之前的消息（start_time 为空时从最新的消息开始），
+// 向前翻页返回 start_time 及之后的消息（start_time 为空时从最早的消息开始）；
+// cursor 不为空时从上一页的末尾继续，忽略 start_time。
+type GetHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RoomId        string                 `protobuf:"bytes,1,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"` // 上一页返回的 next_cursor，也可以是消息 sequence 的十进制形式，从该消息之后继续
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	Direction     HistoryDirection       `protobuf:"varint,5,opt,name=direction,proto3,enum=im.v1.HistoryDirection" json:"direction,omitempty"`
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`                               // 每页的消息数，为0时由服务端决定
+	Types         []MessageType          `protobuf:"varint,7,rep,packed,name=types,proto3,enum=im.v1.MessageType" json:"types,omitempty"` // 只返回这些类型的消息，为空时返回所有类型
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_message_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetHistoryRequest) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetHistoryRequest) GetDirection() HistoryDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return HistoryDirection_HISTORY_DIRECTION_UNSPECIFIED
+}
+
+func (x *GetHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetHistoryRequest) GetTypes() []MessageType {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+// 获取历史消息响应
+type GetHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *ResponseStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Messages      []*MessageResponse     `protobuf:"bytes,2,rep,name=messages,proto3" json:"messages,omitempty"` // 按翻页方向排列
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	HasMore       bool                   `protobuf:"varint,4,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	mi := &file_message_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetHistoryResponse) GetStatus() *ResponseStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *GetHistoryResponse) GetMessages() []*MessageResponse {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *GetHistoryResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *GetHistoryResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
 // 房间信息
 type RoomInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -937,13 +1180,14 @@ type RoomInfo struct {
 	LastActive    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=last_active,json=lastActive,proto3" json:"last_active,omitempty"`
 	MessageCount  int64                  `protobuf:"varint,7,opt,name=message_count,json=messageCount,proto3" json:"message_count,omitempty"`
 	UserCount     int32                  `protobuf:"varint,8,opt,name=user_count,json=userCount,proto3" json:"user_count,omitempty"`
+	LastSequence  uint64                 `protobuf:"varint,9,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"` // 房间内最新一条消息的序号
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RoomInfo) Reset() {
 	*x = RoomInfo{}
-	mi := &file_message_proto_msgTypes[10]
+	mi := &file_message_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -955,7 +1199,7 @@ func (x *RoomInfo) String() string {
 func (*RoomInfo) ProtoMessage() {}
 
 func (x *RoomInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[10]
+	mi := &file_message_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -968,7 +1212,7 @@ func (x *RoomInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RoomInfo.ProtoReflect.Descriptor instead.
 func (*RoomInfo) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{10}
+	return file_message_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *RoomInfo) GetRoomId() string {
@@ -1027,6 +1271,13 @@ func (x *RoomInfo) GetUserCount() int32 {
 	return 0
 }
 
+func (x *RoomInfo) GetLastSequence() uint64 {
+	if x != nil {
+		return x.LastSequence
+	}
+	return 0
+}
+
 // 房间配置
 type RoomConfig struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
@@ -1043,7 +1294,7 @@ type RoomConfig struct {
 
 func (x *RoomConfig) Reset() {
 	*x = RoomConfig{}
-	mi := &file_message_proto_msgTypes[11]
+	mi := &file_message_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1055,7 +1306,7 @@ func (x *RoomConfig) String() string {
 func (*RoomConfig) ProtoMessage() {}
 
 func (x *RoomConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[11]
+	mi := &file_message_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1068,7 +1319,7 @@ func (x *RoomConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RoomConfig.ProtoReflect.Descriptor instead.
 func (*RoomConfig) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{11}
+	return file_message_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *RoomConfig) GetMaxUsers() int32 {
@@ -1134,7 +1385,7 @@ type RoomUser struct {
 
 func (x *RoomUser) Reset() {
 	*x = RoomUser{}
-	mi := &file_message_proto_msgTypes[12]
+	mi := &file_message_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1146,7 +1397,7 @@ func (x *RoomUser) String() string {
 func (*RoomUser) ProtoMessage() {}
 
 func (x *RoomUser) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[12]
+	mi := &file_message_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1159,7 +1410,7 @@ func (x *RoomUser) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RoomUser.ProtoReflect.Descriptor instead.
 func (*RoomUser) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{12}
+	return file_message_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *RoomUser) GetUserId() string {
@@ -1208,7 +1459,7 @@ type TranscriptRequest struct {
 
 func (x *TranscriptRequest) Reset() {
 	*x = TranscriptRequest{}
-	mi := &file_message_proto_msgTypes[13]
+	mi := &file_message_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1220,7 +1471,7 @@ func (x *TranscriptRequest) String() string {
 func (*TranscriptRequest) ProtoMessage() {}
 
 func (x *TranscriptRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[13]
+	mi := &file_message_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1233,7 +1484,7 @@ func (x *TranscriptRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranscriptRequest.ProtoReflect.Descriptor instead.
 func (*TranscriptRequest) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{13}
+	return file_message_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *TranscriptRequest) GetAudioId() string {
@@ -1261,7 +1512,7 @@ type TranscriptResponse struct {
 
 func (x *TranscriptResponse) Reset() {
 	*x = TranscriptResponse{}
-	mi := &file_message_proto_msgTypes[14]
+	mi := &file_message_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1273,7 +1524,7 @@ func (x *TranscriptResponse) String() string {
 func (*TranscriptResponse) ProtoMessage() {}
 
 func (x *TranscriptResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[14]
+	mi := &file_message_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1286,7 +1537,7 @@ func (x *TranscriptResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TranscriptResponse.ProtoReflect.Descriptor instead.
 func (*TranscriptResponse) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{14}
+	return file_message_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *TranscriptResponse) GetStatus() *ResponseStatus {
@@ -1318,7 +1569,7 @@ type Transcription struct {
 
 func (x *Transcription) Reset() {
 	*x = Transcription{}
-	mi := &file_message_proto_msgTypes[15]
+	mi := &file_message_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1330,7 +1581,7 @@ func (x *Transcription) String() string {
 func (*Transcription) ProtoMessage() {}
 
 func (x *Transcription) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[15]
+	mi := &file_message_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1343,7 +1594,7 @@ func (x *Transcription) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Transcription.ProtoReflect.Descriptor instead.
 func (*Transcription) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{15}
+	return file_message_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *Transcription) GetAudioId() string {
@@ -1402,7 +1653,7 @@ type UploadAudioRequest struct {
 
 func (x *UploadAudioRequest) Reset() {
 	*x = UploadAudioRequest{}
-	mi := &file_message_proto_msgTypes[16]
+	mi := &file_message_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1414,7 +1665,7 @@ func (x *UploadAudioRequest) String() string {
 func (*UploadAudioRequest) ProtoMessage() {}
 
 func (x *UploadAudioRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[16]
+	mi := &file_message_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1427,7 +1678,7 @@ func (x *UploadAudioRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UploadAudioRequest.ProtoReflect.Descriptor instead.
 func (*UploadAudioRequest) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{16}
+	return file_message_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *UploadAudioRequest) GetData() isUploadAudioRequest_Data {
@@ -1479,13 +1730,16 @@ type AudioMetadata struct {
 	Format        string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`
 	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
 	Duration      float64                `protobuf:"fixed64,5,opt,name=duration,proto3" json:"duration,omitempty"`
+	Checksum      string                 `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"`                 // 完整音频的SHA-256（十六进制），为空时不校验
+	UploadId      string                 `protobuf:"bytes,7,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"` // 客户端生成的上传ID，用于断点续传
+	Offset        int64                  `protobuf:"varint,8,opt,name=offset,proto3" json:"offset,omitempty"`                    // 本次上传的起始偏移量，续传时为服务端已确认的字节数
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AudioMetadata) Reset() {
 	*x = AudioMetadata{}
-	mi := &file_message_proto_msgTypes[17]
+	mi := &file_message_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1497,7 +1751,7 @@ func (x *AudioMetadata) String() string {
 func (*AudioMetadata) ProtoMessage() {}
 
 func (x *AudioMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[17]
+	mi := &file_message_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1510,7 +1764,7 @@ func (x *AudioMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AudioMetadata.ProtoReflect.Descriptor instead.
 func (*AudioMetadata) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{17}
+	return file_message_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *AudioMetadata) GetUserId() string {
@@ -1548,19 +1802,41 @@ func (x *AudioMetadata) GetDuration() float64 {
 	return 0
 }
 
+func (x *AudioMetadata) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *AudioMetadata) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *AudioMetadata) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
 // 音频上传响应
 type UploadAudioResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        *ResponseStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	AudioId       string                 `protobuf:"bytes,2,opt,name=audio_id,json=audioId,proto3" json:"audio_id,omitempty"`
 	AudioUrl      string                 `protobuf:"bytes,3,opt,name=audio_url,json=audioUrl,proto3" json:"audio_url,omitempty"`
+	Checksum      string                 `protobuf:"bytes,4,opt,name=checksum,proto3" json:"checksum,omitempty"` // 服务端收到的音频的SHA-256（十六进制）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UploadAudioResponse) Reset() {
 	*x = UploadAudioResponse{}
-	mi := &file_message_proto_msgTypes[18]
+	mi := &file_message_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1572,7 +1848,7 @@ func (x *UploadAudioResponse) String() string {
 func (*UploadAudioResponse) ProtoMessage() {}
 
 func (x *UploadAudioResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[18]
+	mi := &file_message_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1585,7 +1861,7 @@ func (x *UploadAudioResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UploadAudioResponse.ProtoReflect.Descriptor instead.
 func (*UploadAudioResponse) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{18}
+	return file_message_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *UploadAudioResponse) GetStatus() *ResponseStatus {
@@ -1609,17 +1885,504 @@ func (x *UploadAudioResponse) GetAudioUrl() string {
 	return ""
 }
 
-// 健康检查请求
-type HealthCheckRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *UploadAudioResponse) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+// 上传状态请求
+type UploadStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UploadId      string                 `protobuf:"bytes,1,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadStatusRequest) Reset() {
+	*x = UploadStatusRequest{}
+	mi := &file_message_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadStatusRequest) ProtoMessage() {}
+
+func (x *UploadStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadStatusRequest.ProtoReflect.Descriptor instead.
+func (*UploadStatusRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UploadStatusRequest) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *UploadStatusRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// 上传状态响应
+type UploadStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *ResponseStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	UploadId      string                 `protobuf:"bytes,2,opt,name=upload_id,json=uploadId,proto3" json:"upload_id,omitempty"`
+	ReceivedBytes int64                  `protobuf:"varint,3,opt,name=received_bytes,json=receivedBytes,proto3" json:"received_bytes,omitempty"` // 服务端已确认的字节数，续传从该偏移量开始
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadStatusResponse) Reset() {
+	*x = UploadStatusResponse{}
+	mi := &file_message_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadStatusResponse) ProtoMessage() {}
+
+func (x *UploadStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadStatusResponse.ProtoReflect.Descriptor instead.
+func (*UploadStatusResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *UploadStatusResponse) GetStatus() *ResponseStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *UploadStatusResponse) GetUploadId() string {
+	if x != nil {
+		return x.UploadId
+	}
+	return ""
+}
+
+func (x *UploadStatusResponse) GetReceivedBytes() int64 {
+	if x != nil {
+		return x.ReceivedBytes
+	}
+	return 0
+}
+
+// 附件上传请求
+type UploadAttachmentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*UploadAttachmentRequest_Metadata
+	//	*UploadAttachmentRequest_Chunk
+	Data          isUploadAttachmentRequest_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadAttachmentRequest) Reset() {
+	*x = UploadAttachmentRequest{}
+	mi := &file_message_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadAttachmentRequest) ProtoMessage() {}
+
+func (x *UploadAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadAttachmentRequest.ProtoReflect.Descriptor instead.
+func (*UploadAttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UploadAttachmentRequest) GetData() isUploadAttachmentRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *UploadAttachmentRequest) GetMetadata() *AttachmentMetadata {
+	if x != nil {
+		if x, ok := x.Data.(*UploadAttachmentRequest_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *UploadAttachmentRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*UploadAttachmentRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadAttachmentRequest_Data interface {
+	isUploadAttachmentRequest_Data()
+}
+
+type UploadAttachmentRequest_Metadata struct {
+	Metadata *AttachmentMetadata `protobuf:"bytes,1,opt,name=metadata,proto3,oneof"`
+}
+
+type UploadAttachmentRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadAttachmentRequest_Metadata) isUploadAttachmentRequest_Data() {}
+
+func (*UploadAttachmentRequest_Chunk) isUploadAttachmentRequest_Data() {}
+
+// 附件元数据
+type AttachmentMetadata struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	UserId            string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	RoomId            string                 `protobuf:"bytes,2,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	MimeType          string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Filename          string                 `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
+	Size              int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	Checksum          string                 `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"`   // 完整文件的SHA-256（十六进制），为空时不校验
+	Thumbnail         []byte                 `protobuf:"bytes,7,opt,name=thumbnail,proto3" json:"thumbnail,omitempty"` // 可选的缩略图（如图片或视频封面）
+	ThumbnailMimeType string                 `protobuf:"bytes,8,opt,name=thumbnail_mime_type,json=thumbnailMimeType,proto3" json:"thumbnail_mime_type,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AttachmentMetadata) Reset() {
+	*x = AttachmentMetadata{}
+	mi := &file_message_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentMetadata) ProtoMessage() {}
+
+func (x *AttachmentMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentMetadata.ProtoReflect.Descriptor instead.
+func (*AttachmentMetadata) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AttachmentMetadata) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AttachmentMetadata) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *AttachmentMetadata) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *AttachmentMetadata) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *AttachmentMetadata) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *AttachmentMetadata) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *AttachmentMetadata) GetThumbnail() []byte {
+	if x != nil {
+		return x.Thumbnail
+	}
+	return nil
+}
+
+func (x *AttachmentMetadata) GetThumbnailMimeType() string {
+	if x != nil {
+		return x.ThumbnailMimeType
+	}
+	return ""
+}
+
+// 附件上传响应
+type UploadAttachmentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *ResponseStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	AttachmentId  string                 `protobuf:"bytes,2,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	ThumbnailUrl  string                 `protobuf:"bytes,4,opt,name=thumbnail_url,json=thumbnailUrl,proto3" json:"thumbnail_url,omitempty"`
+	Checksum      string                 `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"` // 服务端收到的文件的SHA-256（十六进制）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadAttachmentResponse) Reset() {
+	*x = UploadAttachmentResponse{}
+	mi := &file_message_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadAttachmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadAttachmentResponse) ProtoMessage() {}
+
+func (x *UploadAttachmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadAttachmentResponse.ProtoReflect.Descriptor instead.
+func (*UploadAttachmentResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *UploadAttachmentResponse) GetStatus() *ResponseStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *UploadAttachmentResponse) GetAttachmentId() string {
+	if x != nil {
+		return x.AttachmentId
+	}
+	return ""
+}
+
+func (x *UploadAttachmentResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *UploadAttachmentResponse) GetThumbnailUrl() string {
+	if x != nil {
+		return x.ThumbnailUrl
+	}
+	return ""
+}
+
+func (x *UploadAttachmentResponse) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+// 实时语音帧
+//
+// 每条语音流发送的第一帧用于加入房间的语音会话（sequence 为0，不携带音频数据），
+// 服务端加入成功后回复一帧 sequence 为0的确认帧；之后的帧 sequence 从1开始递增，
+// end 为 true 的帧表示发送方结束本次语音。
+type VoiceFrame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // 发送方语音会话ID
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	RoomId        string                 `protobuf:"bytes,3,opt,name=room_id,json=roomId,proto3" json:"room_id,omitempty"`
+	Format        string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"` // 编码格式，如 opus、pcm
+	Sequence      uint64                 `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	TimestampMs   int64                  `protobuf:"varint,6,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"` // 相对语音会话开始的采集时间（毫秒）
+	Payload       []byte                 `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`
+	End           bool                   `protobuf:"varint,8,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VoiceFrame) Reset() {
+	*x = VoiceFrame{}
+	mi := &file_message_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VoiceFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoiceFrame) ProtoMessage() {}
+
+func (x *VoiceFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoiceFrame.ProtoReflect.Descriptor instead.
+func (*VoiceFrame) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *VoiceFrame) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *VoiceFrame) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *VoiceFrame) GetRoomId() string {
+	if x != nil {
+		return x.RoomId
+	}
+	return ""
+}
+
+func (x *VoiceFrame) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *VoiceFrame) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *VoiceFrame) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *VoiceFrame) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *VoiceFrame) GetEnd() bool {
+	if x != nil {
+		return x.End
+	}
+	return false
+}
+
+// 健康检查请求
+type HealthCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Service       string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *HealthCheckRequest) Reset() {
 	*x = HealthCheckRequest{}
-	mi := &file_message_proto_msgTypes[19]
+	mi := &file_message_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1631,7 +2394,7 @@ func (x *HealthCheckRequest) String() string {
 func (*HealthCheckRequest) ProtoMessage() {}
 
 func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[19]
+	mi := &file_message_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1644,7 +2407,7 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
 func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{19}
+	return file_message_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *HealthCheckRequest) GetService() string {
@@ -1665,7 +2428,7 @@ type HealthCheckResponse struct {
 
 func (x *HealthCheckResponse) Reset() {
 	*x = HealthCheckResponse{}
-	mi := &file_message_proto_msgTypes[20]
+	mi := &file_message_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1677,7 +2440,7 @@ func (x *HealthCheckResponse) String() string {
 func (*HealthCheckResponse) ProtoMessage() {}
 
 func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[20]
+	mi := &file_message_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1690,7 +2453,7 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
 func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{20}
+	return file_message_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *HealthCheckResponse) GetStatus() HealthStatus {
@@ -1719,7 +2482,7 @@ type ResponseStatus struct {
 
 func (x *ResponseStatus) Reset() {
 	*x = ResponseStatus{}
-	mi := &file_message_proto_msgTypes[21]
+	mi := &file_message_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1731,7 +2494,7 @@ func (x *ResponseStatus) String() string {
 func (*ResponseStatus) ProtoMessage() {}
 
 func (x *ResponseStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[21]
+	mi := &file_message_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1744,7 +2507,7 @@ func (x *ResponseStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResponseStatus.ProtoReflect.Descriptor instead.
 func (*ResponseStatus) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{21}
+	return file_message_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *ResponseStatus) GetCode() int32 {
@@ -1778,7 +2541,7 @@ type TextContent struct {
 
 func (x *TextContent) Reset() {
 	*x = TextContent{}
-	mi := &file_message_proto_msgTypes[22]
+	mi := &file_message_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1790,7 +2553,7 @@ func (x *TextContent) String() string {
 func (*TextContent) ProtoMessage() {}
 
 func (x *TextContent) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[22]
+	mi := &file_message_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1803,7 +2566,7 @@ func (x *TextContent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TextContent.ProtoReflect.Descriptor instead.
 func (*TextContent) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{22}
+	return file_message_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *TextContent) GetText() string {
@@ -1828,7 +2591,7 @@ type AudioContent struct {
 
 func (x *AudioContent) Reset() {
 	*x = AudioContent{}
-	mi := &file_message_proto_msgTypes[23]
+	mi := &file_message_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1840,7 +2603,7 @@ func (x *AudioContent) String() string {
 func (*AudioContent) ProtoMessage() {}
 
 func (x *AudioContent) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[23]
+	mi := &file_message_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1853,7 +2616,7 @@ func (x *AudioContent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AudioContent.ProtoReflect.Descriptor instead.
 func (*AudioContent) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{23}
+	return file_message_proto_rawDescGZIP(), []int{31}
 }
 
 func (x *AudioContent) GetAudioId() string {
@@ -1898,6 +2661,99 @@ func (x *AudioContent) GetTranscription() string {
 	return ""
 }
 
+// 附件消息内容
+type AttachmentContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AttachmentId  string                 `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	MimeType      string                 `protobuf:"bytes,3,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	Filename      string                 `protobuf:"bytes,4,opt,name=filename,proto3" json:"filename,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	Checksum      string                 `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	ThumbnailUrl  string                 `protobuf:"bytes,7,opt,name=thumbnail_url,json=thumbnailUrl,proto3" json:"thumbnail_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachmentContent) Reset() {
+	*x = AttachmentContent{}
+	mi := &file_message_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentContent) ProtoMessage() {}
+
+func (x *AttachmentContent) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentContent.ProtoReflect.Descriptor instead.
+func (*AttachmentContent) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *AttachmentContent) GetAttachmentId() string {
+	if x != nil {
+		return x.AttachmentId
+	}
+	return ""
+}
+
+func (x *AttachmentContent) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *AttachmentContent) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *AttachmentContent) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *AttachmentContent) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *AttachmentContent) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
+func (x *AttachmentContent) GetThumbnailUrl() string {
+	if x != nil {
+		return x.ThumbnailUrl
+	}
+	return ""
+}
+
 // 富文本消息内容
 type RichTextContent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1910,7 +2766,7 @@ type RichTextContent struct {
 
 func (x *RichTextContent) Reset() {
 	*x = RichTextContent{}
-	mi := &file_message_proto_msgTypes[24]
+	mi := &file_message_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1922,7 +2778,7 @@ func (x *RichTextContent) String() string {
 func (*RichTextContent) ProtoMessage() {}
 
 func (x *RichTextContent) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[24]
+	mi := &file_message_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1935,7 +2791,7 @@ func (x *RichTextContent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RichTextContent.ProtoReflect.Descriptor instead.
 func (*RichTextContent) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{24}
+	return file_message_proto_rawDescGZIP(), []int{33}
 }
 
 func (x *RichTextContent) GetContentType() string {
@@ -1970,7 +2826,7 @@ type SystemContent struct {
 
 func (x *SystemContent) Reset() {
 	*x = SystemContent{}
-	mi := &file_message_proto_msgTypes[25]
+	mi := &file_message_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1982,7 +2838,7 @@ func (x *SystemContent) String() string {
 func (*SystemContent) ProtoMessage() {}
 
 func (x *SystemContent) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[25]
+	mi := &file_message_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1995,7 +2851,7 @@ func (x *SystemContent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SystemContent.ProtoReflect.Descriptor instead.
 func (*SystemContent) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{25}
+	return file_message_proto_rawDescGZIP(), []int{34}
 }
 
 func (x *SystemContent) GetEventType() string {
@@ -2018,13 +2874,14 @@ type AckContent struct {
 	OriginalMessageId string                 `protobuf:"bytes,1,opt,name=original_message_id,json=originalMessageId,proto3" json:"original_message_id,omitempty"`
 	Success           bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
 	ErrorMessage      string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Sequence          uint64                 `protobuf:"varint,4,opt,name=sequence,proto3" json:"sequence,omitempty"` // 原消息在房间内的序号，发送失败时为0
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
 
 func (x *AckContent) Reset() {
 	*x = AckContent{}
-	mi := &file_message_proto_msgTypes[26]
+	mi := &file_message_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2036,7 +2893,7 @@ func (x *AckContent) String() string {
 func (*AckContent) ProtoMessage() {}
 
 func (x *AckContent) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[26]
+	mi := &file_message_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2049,7 +2906,7 @@ func (x *AckContent) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AckContent.ProtoReflect.Descriptor instead.
 func (*AckContent) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{26}
+	return file_message_proto_rawDescGZIP(), []int{35}
 }
 
 func (x *AckContent) GetOriginalMessageId() string {
@@ -2073,6 +2930,13 @@ func (x *AckContent) GetErrorMessage() string {
 	return ""
 }
 
+func (x *AckContent) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
 var File_message_proto protoreflect.FileDescriptor
 
 const file_message_proto_rawDesc = "" +
@@ -2089,7 +2953,7 @@ const file_message_proto_rawDesc = "" +
 	"\ttimestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x89\x03\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa5\x03\n" +
 	"\x0fMessageResponse\x12\x1d\n" +
 	"\n" +
 	"message_id\x18\x01 \x01(\tR\tmessageId\x12 \n" +
@@ -2100,25 +2964,29 @@ const file_message_proto_rawDesc = "" +
 	"\acontent\x18\x05 \x01(\fR\acontent\x128\n" +
 	"\ttimestamp\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12@\n" +
 	"\bmetadata\x18\a \x03(\v2$.im.v1.MessageResponse.MetadataEntryR\bmetadata\x12!\n" +
-	"\fack_required\x18\b \x01(\bR\vackRequired\x1a;\n" +
+	"\fack_required\x18\b \x01(\bR\vackRequired\x12\x1a\n" +
+	"\bsequence\x18\t \x01(\x04R\bsequence\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xad\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xcc\x02\n" +
 	"\x12SendMessageRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
 	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12&\n" +
 	"\x04type\x18\x03 \x01(\x0e2\x12.im.v1.MessageTypeR\x04type\x12\x18\n" +
 	"\acontent\x18\x04 \x01(\fR\acontent\x12C\n" +
 	"\bmetadata\x18\x05 \x03(\v2'.im.v1.SendMessageRequest.MetadataEntryR\bmetadata\x12!\n" +
-	"\fack_required\x18\x06 \x01(\bR\vackRequired\x1a;\n" +
+	"\fack_required\x18\x06 \x01(\bR\vackRequired\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\a \x01(\tR\tmessageId\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9d\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb9\x01\n" +
 	"\x13SendMessageResponse\x12\x1d\n" +
 	"\n" +
 	"message_id\x18\x01 \x01(\tR\tmessageId\x128\n" +
 	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12-\n" +
-	"\x06status\x18\x03 \x01(\v2\x15.im.v1.ResponseStatusR\x06status\"\xc2\x01\n" +
+	"\x06status\x18\x03 \x01(\v2\x15.im.v1.ResponseStatusR\x06status\x12\x1a\n" +
+	"\bsequence\x18\x04 \x01(\x04R\bsequence\"\xc2\x01\n" +
 	"\x0fJoinRoomRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
 	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12@\n" +
@@ -2141,7 +3009,22 @@ const file_message_proto_rawDesc = "" +
 	"\x13GetRoomInfoResponse\x12-\n" +
 	"\x06status\x18\x01 \x01(\v2\x15.im.v1.ResponseStatusR\x06status\x12,\n" +
 	"\troom_info\x18\x02 \x01(\v2\x0f.im.v1.RoomInfoR\broomInfo\x12%\n" +
-	"\x05users\x18\x03 \x03(\v2\x0f.im.v1.RoomUserR\x05users\"\xc0\x02\n" +
+	"\x05users\x18\x03 \x03(\v2\x0f.im.v1.RoomUserR\x05users\"\x8f\x02\n" +
+	"\x11GetHistoryRequest\x12\x17\n" +
+	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x129\n" +
+	"\n" +
+	"start_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\tdirection\x18\x05 \x01(\x0e2\x17.im.v1.HistoryDirectionR\tdirection\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12(\n" +
+	"\x05types\x18\a \x03(\x0e2\x12.im.v1.MessageTypeR\x05types\"\xb3\x01\n" +
+	"\x12GetHistoryResponse\x12-\n" +
+	"\x06status\x18\x01 \x01(\v2\x15.im.v1.ResponseStatusR\x06status\x122\n" +
+	"\bmessages\x18\x02 \x03(\v2\x16.im.v1.MessageResponseR\bmessages\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x04 \x01(\bR\ahasMore\"\xe5\x02\n" +
 	"\bRoomInfo\x12\x17\n" +
 	"\aroom_id\x18\x01 \x01(\tR\x06roomId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
@@ -2153,7 +3036,8 @@ const file_message_proto_rawDesc = "" +
 	"lastActive\x12#\n" +
 	"\rmessage_count\x18\a \x01(\x03R\fmessageCount\x12\x1d\n" +
 	"\n" +
-	"user_count\x18\b \x01(\x05R\tuserCount\"\x9d\x02\n" +
+	"user_count\x18\b \x01(\x05R\tuserCount\x12#\n" +
+	"\rlast_sequence\x18\t \x01(\x04R\flastSequence\"\x9d\x02\n" +
 	"\n" +
 	"RoomConfig\x12\x1b\n" +
 	"\tmax_users\x18\x01 \x01(\x05R\bmaxUsers\x12\x1e\n" +
@@ -2192,17 +3076,58 @@ const file_message_proto_rawDesc = "" +
 	"\x12UploadAudioRequest\x122\n" +
 	"\bmetadata\x18\x01 \x01(\v2\x14.im.v1.AudioMetadataH\x00R\bmetadata\x12\x16\n" +
 	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
-	"\x04data\"\x89\x01\n" +
+	"\x04data\"\xda\x01\n" +
 	"\rAudioMetadata\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
 	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12\x16\n" +
 	"\x06format\x18\x03 \x01(\tR\x06format\x12\x12\n" +
 	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x1a\n" +
-	"\bduration\x18\x05 \x01(\x01R\bduration\"|\n" +
+	"\bduration\x18\x05 \x01(\x01R\bduration\x12\x1a\n" +
+	"\bchecksum\x18\x06 \x01(\tR\bchecksum\x12\x1b\n" +
+	"\tupload_id\x18\a \x01(\tR\buploadId\x12\x16\n" +
+	"\x06offset\x18\b \x01(\x03R\x06offset\"\x98\x01\n" +
 	"\x13UploadAudioResponse\x12-\n" +
 	"\x06status\x18\x01 \x01(\v2\x15.im.v1.ResponseStatusR\x06status\x12\x19\n" +
 	"\baudio_id\x18\x02 \x01(\tR\aaudioId\x12\x1b\n" +
-	"\taudio_url\x18\x03 \x01(\tR\baudioUrl\".\n" +
+	"\taudio_url\x18\x03 \x01(\tR\baudioUrl\x12\x1a\n" +
+	"\bchecksum\x18\x04 \x01(\tR\bchecksum\"K\n" +
+	"\x13UploadStatusRequest\x12\x1b\n" +
+	"\tupload_id\x18\x01 \x01(\tR\buploadId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x89\x01\n" +
+	"\x14UploadStatusResponse\x12-\n" +
+	"\x06status\x18\x01 \x01(\v2\x15.im.v1.ResponseStatusR\x06status\x12\x1b\n" +
+	"\tupload_id\x18\x02 \x01(\tR\buploadId\x12%\n" +
+	"\x0ereceived_bytes\x18\x03 \x01(\x03R\rreceivedBytes\"r\n" +
+	"\x17UploadAttachmentRequest\x127\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x19.im.v1.AttachmentMetadataH\x00R\bmetadata\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"\xfd\x01\n" +
+	"\x12AttachmentMetadata\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x17\n" +
+	"\aroom_id\x18\x02 \x01(\tR\x06roomId\x12\x1b\n" +
+	"\tmime_type\x18\x03 \x01(\tR\bmimeType\x12\x1a\n" +
+	"\bfilename\x18\x04 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x1a\n" +
+	"\bchecksum\x18\x06 \x01(\tR\bchecksum\x12\x1c\n" +
+	"\tthumbnail\x18\a \x01(\fR\tthumbnail\x12.\n" +
+	"\x13thumbnail_mime_type\x18\b \x01(\tR\x11thumbnailMimeType\"\xc1\x01\n" +
+	"\x18UploadAttachmentResponse\x12-\n" +
+	"\x06status\x18\x01 \x01(\v2\x15.im.v1.ResponseStatusR\x06status\x12#\n" +
+	"\rattachment_id\x18\x02 \x01(\tR\fattachmentId\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12#\n" +
+	"\rthumbnail_url\x18\x04 \x01(\tR\fthumbnailUrl\x12\x1a\n" +
+	"\bchecksum\x18\x05 \x01(\tR\bchecksum\"\xe0\x01\n" +
+	"\n" +
+	"VoiceFrame\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x17\n" +
+	"\aroom_id\x18\x03 \x01(\tR\x06roomId\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x1a\n" +
+	"\bsequence\x18\x05 \x01(\x04R\bsequence\x12!\n" +
+	"\ftimestamp_ms\x18\x06 \x01(\x03R\vtimestampMs\x12\x18\n" +
+	"\apayload\x18\a \x01(\fR\apayload\x12\x10\n" +
+	"\x03end\x18\b \x01(\bR\x03end\".\n" +
 	"\x12HealthCheckRequest\x12\x18\n" +
 	"\aservice\x18\x01 \x01(\tR\aservice\"\\\n" +
 	"\x13HealthCheckResponse\x12+\n" +
@@ -2223,7 +3148,15 @@ const file_message_proto_rawDesc = "" +
 	"\bduration\x18\x03 \x01(\x01R\bduration\x12\x16\n" +
 	"\x06format\x18\x04 \x01(\tR\x06format\x12\x12\n" +
 	"\x04size\x18\x05 \x01(\x03R\x04size\x12$\n" +
-	"\rtranscription\x18\x06 \x01(\tR\rtranscription\"x\n" +
+	"\rtranscription\x18\x06 \x01(\tR\rtranscription\"\xd8\x01\n" +
+	"\x11AttachmentContent\x12#\n" +
+	"\rattachment_id\x18\x01 \x01(\tR\fattachmentId\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x1b\n" +
+	"\tmime_type\x18\x03 \x01(\tR\bmimeType\x12\x1a\n" +
+	"\bfilename\x18\x04 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x1a\n" +
+	"\bchecksum\x18\x06 \x01(\tR\bchecksum\x12#\n" +
+	"\rthumbnail_url\x18\a \x01(\tR\fthumbnailUrl\"x\n" +
 	"\x0fRichTextContent\x12!\n" +
 	"\fcontent_type\x18\x01 \x01(\tR\vcontentType\x12\x1f\n" +
 	"\vraw_content\x18\x02 \x01(\tR\n" +
@@ -2236,12 +3169,13 @@ const file_message_proto_rawDesc = "" +
 	"event_data\x18\x02 \x03(\v2#.im.v1.SystemContent.EventDataEntryR\teventData\x1a<\n" +
 	"\x0eEventDataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"{\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x97\x01\n" +
 	"\n" +
 	"AckContent\x12.\n" +
 	"\x13original_message_id\x18\x01 \x01(\tR\x11originalMessageId\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12#\n" +
-	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage*\xfa\x01\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\x12\x1a\n" +
+	"\bsequence\x18\x04 \x01(\x04R\bsequence*\x97\x02\n" +
 	"\vMessageType\x12\x1c\n" +
 	"\x18MESSAGE_TYPE_UNSPECIFIED\x10\x00\x12\x15\n" +
 	"\x11MESSAGE_TYPE_TEXT\x10\x01\x12\x16\n" +
@@ -2251,7 +3185,12 @@ const file_message_proto_rawDesc = "" +
 	"\x10MESSAGE_TYPE_ACK\x10\x05\x12\x1a\n" +
 	"\x16MESSAGE_TYPE_JOIN_ROOM\x10\x06\x12\x1b\n" +
 	"\x17MESSAGE_TYPE_LEAVE_ROOM\x10\a\x12\x1a\n" +
-	"\x16MESSAGE_TYPE_HEARTBEAT\x10\b*g\n" +
+	"\x16MESSAGE_TYPE_HEARTBEAT\x10\b\x12\x1b\n" +
+	"\x17MESSAGE_TYPE_ATTACHMENT\x10\t*t\n" +
+	"\x10HistoryDirection\x12!\n" +
+	"\x1dHISTORY_DIRECTION_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aHISTORY_DIRECTION_BACKWARD\x10\x01\x12\x1d\n" +
+	"\x19HISTORY_DIRECTION_FORWARD\x10\x02*g\n" +
 	"\bUserRole\x12\x19\n" +
 	"\x15USER_ROLE_UNSPECIFIED\x10\x00\x12\x12\n" +
 	"\x0eUSER_ROLE_USER\x10\x01\x12\x17\n" +
@@ -2267,15 +3206,20 @@ const file_message_proto_rawDesc = "" +
 	"\x19HEALTH_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
 	"\x15HEALTH_STATUS_SERVING\x10\x01\x12\x1d\n" +
 	"\x19HEALTH_STATUS_NOT_SERVING\x10\x02\x12!\n" +
-	"\x1dHEALTH_STATUS_SERVICE_UNKNOWN\x10\x032\xb2\x04\n" +
+	"\x1dHEALTH_STATUS_SERVICE_UNKNOWN\x10\x032\xd1\x06\n" +
 	"\tIMService\x12C\n" +
 	"\x0eStreamMessages\x12\x15.im.v1.MessageRequest\x1a\x16.im.v1.MessageResponse(\x010\x01\x12D\n" +
 	"\vSendMessage\x12\x19.im.v1.SendMessageRequest\x1a\x1a.im.v1.SendMessageResponse\x12;\n" +
 	"\bJoinRoom\x12\x16.im.v1.JoinRoomRequest\x1a\x17.im.v1.JoinRoomResponse\x12>\n" +
 	"\tLeaveRoom\x12\x17.im.v1.LeaveRoomRequest\x1a\x18.im.v1.LeaveRoomResponse\x12D\n" +
-	"\vGetRoomInfo\x12\x19.im.v1.GetRoomInfoRequest\x1a\x1a.im.v1.GetRoomInfoResponse\x12I\n" +
+	"\vGetRoomInfo\x12\x19.im.v1.GetRoomInfoRequest\x1a\x1a.im.v1.GetRoomInfoResponse\x12A\n" +
+	"\n" +
+	"GetHistory\x12\x18.im.v1.GetHistoryRequest\x1a\x19.im.v1.GetHistoryResponse\x12I\n" +
 	"\x12GetAudioTranscript\x12\x18.im.v1.TranscriptRequest\x1a\x19.im.v1.TranscriptResponse\x12F\n" +
-	"\vUploadAudio\x12\x19.im.v1.UploadAudioRequest\x1a\x1a.im.v1.UploadAudioResponse(\x01\x12D\n" +
+	"\vUploadAudio\x12\x19.im.v1.UploadAudioRequest\x1a\x1a.im.v1.UploadAudioResponse(\x01\x12J\n" +
+	"\x0fGetUploadStatus\x12\x1a.im.v1.UploadStatusRequest\x1a\x1b.im.v1.UploadStatusResponse\x12U\n" +
+	"\x10UploadAttachment\x12\x1e.im.v1.UploadAttachmentRequest\x1a\x1f.im.v1.UploadAttachmentResponse(\x01\x127\n" +
+	"\vStreamVoice\x12\x11.im.v1.VoiceFrame\x1a\x11.im.v1.VoiceFrame(\x010\x01\x12D\n" +
 	"\vHealthCheck\x12\x19.im.v1.HealthCheckRequest\x1a\x1a.im.v1.HealthCheckResponseB1Z/github.com/Dev-Umb/im-grpc-sdk/proto/im/v1;imv1b\x06proto3"
 
 var (
@@ -2290,102 +3234,128 @@ func file_message_proto_rawDescGZIP() []byte {
 	return file_message_proto_rawDescData
 }
 
-var file_message_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
-var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_message_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_message_proto_goTypes = []any{
-	(MessageType)(0),              // 0: im.v1.MessageType
-	(UserRole)(0),                 // 1: im.v1.UserRole
-	(TranscriptStatus)(0),         // 2: im.v1.TranscriptStatus
-	(HealthStatus)(0),             // 3: im.v1.HealthStatus
-	(*MessageRequest)(nil),        // 4: im.v1.MessageRequest
-	(*MessageResponse)(nil),       // 5: im.v1.MessageResponse
-	(*SendMessageRequest)(nil),    // 6: im.v1.SendMessageRequest
-	(*SendMessageResponse)(nil),   // 7: im.v1.SendMessageResponse
-	(*JoinRoomRequest)(nil),       // 8: im.v1.JoinRoomRequest
-	(*JoinRoomResponse)(nil),      // 9: im.v1.JoinRoomResponse
-	(*LeaveRoomRequest)(nil),      // 10: im.v1.LeaveRoomRequest
-	(*LeaveRoomResponse)(nil),     // 11: im.v1.LeaveRoomResponse
-	(*GetRoomInfoRequest)(nil),    // 12: im.v1.GetRoomInfoRequest
-	(*GetRoomInfoResponse)(nil),   // 13: im.v1.GetRoomInfoResponse
-	(*RoomInfo)(nil),              // 14: im.v1.RoomInfo
-	(*RoomConfig)(nil),            // 15: im.v1.RoomConfig
-	(*RoomUser)(nil),              // 16: im.v1.RoomUser
-	(*TranscriptRequest)(nil),     // 17: im.v1.TranscriptRequest
-	(*TranscriptResponse)(nil),    // 18: im.v1.TranscriptResponse
-	(*Transcription)(nil),         // 19: im.v1.Transcription
-	(*UploadAudioRequest)(nil),    // 20: im.v1.UploadAudioRequest
-	(*AudioMetadata)(nil),         // 21: im.v1.AudioMetadata
-	(*UploadAudioResponse)(nil),   // 22: im.v1.UploadAudioResponse
-	(*HealthCheckRequest)(nil),    // 23: im.v1.HealthCheckRequest
-	(*HealthCheckResponse)(nil),   // 24: im.v1.HealthCheckResponse
-	(*ResponseStatus)(nil),        // 25: im.v1.ResponseStatus
-	(*TextContent)(nil),           // 26: im.v1.TextContent
-	(*AudioContent)(nil),          // 27: im.v1.AudioContent
-	(*RichTextContent)(nil),       // 28: im.v1.RichTextContent
-	(*SystemContent)(nil),         // 29: im.v1.SystemContent
-	(*AckContent)(nil),            // 30: im.v1.AckContent
-	nil,                           // 31: im.v1.MessageRequest.MetadataEntry
-	nil,                           // 32: im.v1.MessageResponse.MetadataEntry
-	nil,                           // 33: im.v1.SendMessageRequest.MetadataEntry
-	nil,                           // 34: im.v1.JoinRoomRequest.MetadataEntry
-	nil,                           // 35: im.v1.ResponseStatus.DetailsEntry
-	nil,                           // 36: im.v1.SystemContent.EventDataEntry
-	(*timestamppb.Timestamp)(nil), // 37: google.protobuf.Timestamp
+	(MessageType)(0),                 // 0: im.v1.MessageType
+	(HistoryDirection)(0),            // 1: im.v1.HistoryDirection
+	(UserRole)(0),                    // 2: im.v1.UserRole
+	(TranscriptStatus)(0),            // 3: im.v1.TranscriptStatus
+	(HealthStatus)(0),                // 4: im.v1.HealthStatus
+	(*MessageRequest)(nil),           // 5: im.v1.MessageRequest
+	(*MessageResponse)(nil),          // 6: im.v1.MessageResponse
+	(*SendMessageRequest)(nil),       // 7: im.v1.SendMessageRequest
+	(*SendMessageResponse)(nil),      // 8: im.v1.SendMessageResponse
+	(*JoinRoomRequest)(nil),          // 9: im.v1.JoinRoomRequest
+	(*JoinRoomResponse)(nil),         // 10: im.v1.JoinRoomResponse
+	(*LeaveRoomRequest)(nil),         // 11: im.v1.LeaveRoomRequest
+	(*LeaveRoomResponse)(nil),        // 12: im.v1.LeaveRoomResponse
+	(*GetRoomInfoRequest)(nil),       // 13: im.v1.GetRoomInfoRequest
+	(*GetRoomInfoResponse)(nil),      // 14: im.v1.GetRoomInfoResponse
+	(*GetHistoryRequest)(nil),        // 15: im.v1.GetHistoryRequest
+	(*GetHistoryResponse)(nil),       // 16: im.v1.GetHistoryResponse
+	(*RoomInfo)(nil),                 // 17: im.v1.RoomInfo
+	(*RoomConfig)(nil),               // 18: im.v1.RoomConfig
+	(*RoomUser)(nil),                 // 19: im.v1.RoomUser
+	(*TranscriptRequest)(nil),        // 20: im.v1.TranscriptRequest
+	(*TranscriptResponse)(nil),       // 21: im.v1.TranscriptResponse
+	(*Transcription)(nil),            // 22: im.v1.Transcription
+	(*UploadAudioRequest)(nil),       // 23: im.v1.UploadAudioRequest
+	(*AudioMetadata)(nil),            // 24: im.v1.AudioMetadata
+	(*UploadAudioResponse)(nil),      // 25: im.v1.UploadAudioResponse
+	(*UploadStatusRequest)(nil),      // 26: im.v1.UploadStatusRequest
+	(*UploadStatusResponse)(nil),     // 27: im.v1.UploadStatusResponse
+	(*UploadAttachmentRequest)(nil),  // 28: im.v1.UploadAttachmentRequest
+	(*AttachmentMetadata)(nil),       // 29: im.v1.AttachmentMetadata
+	(*UploadAttachmentResponse)(nil), // 30: im.v1.UploadAttachmentResponse
+	(*VoiceFrame)(nil),               // 31: im.v1.VoiceFrame
+	(*HealthCheckRequest)(nil),       // 32: im.v1.HealthCheckRequest
+	(*HealthCheckResponse)(nil),      // 33: im.v1.HealthCheckResponse
+	(*ResponseStatus)(nil),           // 34: im.v1.ResponseStatus
+	(*TextContent)(nil),              // 35: im.v1.TextContent
+	(*AudioContent)(nil),             // 36: im.v1.AudioContent
+	(*AttachmentContent)(nil),        // 37: im.v1.AttachmentContent
+	(*RichTextContent)(nil),          // 38: im.v1.RichTextContent
+	(*SystemContent)(nil),            // 39: im.v1.SystemContent
+	(*AckContent)(nil),               // 40: im.v1.AckContent
+	nil,                              // 41: im.v1.MessageRequest.MetadataEntry
+	nil,                              // 42: im.v1.MessageResponse.MetadataEntry
+	nil,                              // 43: im.v1.SendMessageRequest.MetadataEntry
+	nil,                              // 44: im.v1.JoinRoomRequest.MetadataEntry
+	nil,                              // 45: im.v1.ResponseStatus.DetailsEntry
+	nil,                              // 46: im.v1.SystemContent.EventDataEntry
+	(*timestamppb.Timestamp)(nil),    // 47: google.protobuf.Timestamp
 }
 var file_message_proto_depIdxs = []int32{
 	0,  // 0: im.v1.MessageRequest.type:type_name -> im.v1.MessageType
-	31, // 1: im.v1.MessageRequest.metadata:type_name -> im.v1.MessageRequest.MetadataEntry
-	37, // 2: im.v1.MessageRequest.timestamp:type_name -> google.protobuf.Timestamp
+	41, // 1: im.v1.MessageRequest.metadata:type_name -> im.v1.MessageRequest.MetadataEntry
+	47, // 2: im.v1.MessageRequest.timestamp:type_name -> google.protobuf.Timestamp
 	0,  // 3: im.v1.MessageResponse.type:type_name -> im.v1.MessageType
-	37, // 4: im.v1.MessageResponse.timestamp:type_name -> google.protobuf.Timestamp
-	32, // 5: im.v1.MessageResponse.metadata:type_name -> im.v1.MessageResponse.MetadataEntry
+	47, // 4: im.v1.MessageResponse.timestamp:type_name -> google.protobuf.Timestamp
+	42, // 5: im.v1.MessageResponse.metadata:type_name -> im.v1.MessageResponse.MetadataEntry
 	0,  // 6: im.v1.SendMessageRequest.type:type_name -> im.v1.MessageType
-	33, // 7: im.v1.SendMessageRequest.metadata:type_name -> im.v1.SendMessageRequest.MetadataEntry
-	37, // 8: im.v1.SendMessageResponse.timestamp:type_name -> google.protobuf.Timestamp
-	25, // 9: im.v1.SendMessageResponse.status:type_name -> im.v1.ResponseStatus
-	34, // 10: im.v1.JoinRoomRequest.metadata:type_name -> im.v1.JoinRoomRequest.MetadataEntry
-	25, // 11: im.v1.JoinRoomResponse.status:type_name -> im.v1.ResponseStatus
-	14, // 12: im.v1.JoinRoomResponse.room_info:type_name -> im.v1.RoomInfo
-	25, // 13: im.v1.LeaveRoomResponse.status:type_name -> im.v1.ResponseStatus
-	25, // 14: im.v1.GetRoomInfoResponse.status:type_name -> im.v1.ResponseStatus
-	14, // 15: im.v1.GetRoomInfoResponse.room_info:type_name -> im.v1.RoomInfo
-	16, // 16: im.v1.GetRoomInfoResponse.users:type_name -> im.v1.RoomUser
-	15, // 17: im.v1.RoomInfo.config:type_name -> im.v1.RoomConfig
-	37, // 18: im.v1.RoomInfo.created_at:type_name -> google.protobuf.Timestamp
-	37, // 19: im.v1.RoomInfo.last_active:type_name -> google.protobuf.Timestamp
-	1,  // 20: im.v1.RoomUser.role:type_name -> im.v1.UserRole
-	37, // 21: im.v1.RoomUser.joined_at:type_name -> google.protobuf.Timestamp
-	25, // 22: im.v1.TranscriptResponse.status:type_name -> im.v1.ResponseStatus
-	19, // 23: im.v1.TranscriptResponse.transcription:type_name -> im.v1.Transcription
-	2,  // 24: im.v1.Transcription.status:type_name -> im.v1.TranscriptStatus
-	37, // 25: im.v1.Transcription.created_at:type_name -> google.protobuf.Timestamp
-	37, // 26: im.v1.Transcription.updated_at:type_name -> google.protobuf.Timestamp
-	21, // 27: im.v1.UploadAudioRequest.metadata:type_name -> im.v1.AudioMetadata
-	25, // 28: im.v1.UploadAudioResponse.status:type_name -> im.v1.ResponseStatus
-	3,  // 29: im.v1.HealthCheckResponse.status:type_name -> im.v1.HealthStatus
-	35, // 30: im.v1.ResponseStatus.details:type_name -> im.v1.ResponseStatus.DetailsEntry
-	36, // 31: im.v1.SystemContent.event_data:type_name -> im.v1.SystemContent.EventDataEntry
-	4,  // 32: im.v1.IMService.StreamMessages:input_type -> im.v1.MessageRequest
-	6,  // 33: im.v1.IMService.SendMessage:input_type -> im.v1.SendMessageRequest
-	8,  // 34: im.v1.IMService.JoinRoom:input_type -> im.v1.JoinRoomRequest
-	10, // 35: im.v1.IMService.LeaveRoom:input_type -> im.v1.LeaveRoomRequest
-	12, // 36: im.v1.IMService.GetRoomInfo:input_type -> im.v1.GetRoomInfoRequest
-	17, // 37: im.v1.IMService.GetAudioTranscript:input_type -> im.v1.TranscriptRequest
-	20, // 38: im.v1.IMService.UploadAudio:input_type -> im.v1.UploadAudioRequest
-	23, // 39: im.v1.IMService.HealthCheck:input_type -> im.v1.HealthCheckRequest
-	5,  // 40: im.v1.IMService.StreamMessages:output_type -> im.v1.MessageResponse
-	7,  // 41: im.v1.IMService.SendMessage:output_type -> im.v1.SendMessageResponse
-	9,  // 42: im.v1.IMService.JoinRoom:output_type -> im.v1.JoinRoomResponse
-	11, // 43: im.v1.IMService.LeaveRoom:output_type -> im.v1.LeaveRoomResponse
-	13, // 44: im.v1.IMService.GetRoomInfo:output_type -> im.v1.GetRoomInfoResponse
-	18, // 45: im.v1.IMService.GetAudioTranscript:output_type -> im.v1.TranscriptResponse
-	22, // 46: im.v1.IMService.UploadAudio:output_type -> im.v1.UploadAudioResponse
-	24, // 47: im.v1.IMService.HealthCheck:output_type -> im.v1.HealthCheckResponse
-	40, // [40:48] is the sub-list for method output_type
-	32, // [32:40] is the sub-list for method input_type
-	32, // [32:32] is the sub-list for extension type_name
-	32, // [32:32] is the sub-list for extension extendee
-	0,  // [0:32] is the sub-list for field type_name
+	43, // 7: im.v1.SendMessageRequest.metadata:type_name -> im.v1.SendMessageRequest.MetadataEntry
+	47, // 8: im.v1.SendMessageResponse.timestamp:type_name -> google.protobuf.Timestamp
+	34, // 9: im.v1.SendMessageResponse.status:type_name -> im.v1.ResponseStatus
+	44, // 10: im.v1.JoinRoomRequest.metadata:type_name -> im.v1.JoinRoomRequest.MetadataEntry
+	34, // 11: im.v1.JoinRoomResponse.status:type_name -> im.v1.ResponseStatus
+	17, // 12: im.v1.JoinRoomResponse.room_info:type_name -> im.v1.RoomInfo
+	34, // 13: im.v1.LeaveRoomResponse.status:type_name -> im.v1.ResponseStatus
+	34, // 14: im.v1.GetRoomInfoResponse.status:type_name -> im.v1.ResponseStatus
+	17, // 15: im.v1.GetRoomInfoResponse.room_info:type_name -> im.v1.RoomInfo
+	19, // 16: im.v1.GetRoomInfoResponse.users:type_name -> im.v1.RoomUser
+	47, // 17: im.v1.GetHistoryRequest.start_time:type_name -> google.protobuf.Timestamp
+	1,  // 18: im.v1.GetHistoryRequest.direction:type_name -> im.v1.HistoryDirection
+	0,  // 19: im.v1.GetHistoryRequest.types:type_name -> im.v1.MessageType
+	34, // 20: im.v1.GetHistoryResponse.status:type_name -> im.v1.ResponseStatus
+	6,  // 21: im.v1.GetHistoryResponse.messages:type_name -> im.v1.MessageResponse
+	18, // 22: im.v1.RoomInfo.config:type_name -> im.v1.RoomConfig
+	47, // 23: im.v1.RoomInfo.created_at:type_name -> google.protobuf.Timestamp
+	47, // 24: im.v1.RoomInfo.last_active:type_name -> google.protobuf.Timestamp
+	2,  // 25: im.v1.RoomUser.role:type_name -> im.v1.UserRole
+	47, // 26: im.v1.RoomUser.joined_at:type_name -> google.protobuf.Timestamp
+	34, // 27: im.v1.TranscriptResponse.status:type_name -> im.v1.ResponseStatus
+	22, // 28: im.v1.TranscriptResponse.transcription:type_name -> im.v1.Transcription
+	3,  // 29: im.v1.Transcription.status:type_name -> im.v1.TranscriptStatus
+	47, // 30: im.v1.Transcription.created_at:type_name -> google.protobuf.Timestamp
+	47, // 31: im.v1.Transcription.updated_at:type_name -> google.protobuf.Timestamp
+	24, // 32: im.v1.UploadAudioRequest.metadata:type_name -> im.v1.AudioMetadata
+	34, // 33: im.v1.UploadAudioResponse.status:type_name -> im.v1.ResponseStatus
+	34, // 34: im.v1.UploadStatusResponse.status:type_name -> im.v1.ResponseStatus
+	29, // 35: im.v1.UploadAttachmentRequest.metadata:type_name -> im.v1.AttachmentMetadata
+	34, // 36: im.v1.UploadAttachmentResponse.status:type_name -> im.v1.ResponseStatus
+	4,  // 37: im.v1.HealthCheckResponse.status:type_name -> im.v1.HealthStatus
+	45, // 38: im.v1.ResponseStatus.details:type_name -> im.v1.ResponseStatus.DetailsEntry
+	46, // 39: im.v1.SystemContent.event_data:type_name -> im.v1.SystemContent.EventDataEntry
+	5,  // 40: im.v1.IMService.StreamMessages:input_type -> im.v1.MessageRequest
+	7,  // 41: im.v1.IMService.SendMessage:input_type -> im.v1.SendMessageRequest
+	9,  // 42: im.v1.IMService.JoinRoom:input_type -> im.v1.JoinRoomRequest
+	11, // 43: im.v1.IMService.LeaveRoom:input_type -> im.v1.LeaveRoomRequest
+	13, // 44: im.v1.IMService.GetRoomInfo:input_type -> im.v1.GetRoomInfoRequest
+	15, // 45: im.v1.IMService.GetHistory:input_type -> im.v1.GetHistoryRequest
+	20, // 46: im.v1.IMService.GetAudioTranscript:input_type -> im.v1.TranscriptRequest
+	23, // 47: im.v1.IMService.UploadAudio:input_type -> im.v1.UploadAudioRequest
+	26, // 48: im.v1.IMService.GetUploadStatus:input_type -> im.v1.UploadStatusRequest
+	28, // 49: im.v1.IMService.UploadAttachment:input_type -> im.v1.UploadAttachmentRequest
+	31, // 50: im.v1.IMService.StreamVoice:input_type -> im.v1.VoiceFrame
+	32, // 51: im.v1.IMService.HealthCheck:input_type -> im.v1.HealthCheckRequest
+	6,  // 52: im.v1.IMService.StreamMessages:output_type -> im.v1.MessageResponse
+	8,  // 53: im.v1.IMService.SendMessage:output_type -> im.v1.SendMessageResponse
+	10, // 54: im.v1.IMService.JoinRoom:output_type -> im.v1.JoinRoomResponse
+	12, // 55: im.v1.IMService.LeaveRoom:output_type -> im.v1.LeaveRoomResponse
+	14, // 56: im.v1.IMService.GetRoomInfo:output_type -> im.v1.GetRoomInfoResponse
+	16, // 57: im.v1.IMService.GetHistory:output_type -> im.v1.GetHistoryResponse
+	21, // 58: im.v1.IMService.GetAudioTranscript:output_type -> im.v1.TranscriptResponse
+	25, // 59: im.v1.IMService.UploadAudio:output_type -> im.v1.UploadAudioResponse
+	27, // 60: im.v1.IMService.GetUploadStatus:output_type -> im.v1.UploadStatusResponse
+	30, // 61: im.v1.IMService.UploadAttachment:output_type -> im.v1.UploadAttachmentResponse
+	31, // 62: im.v1.IMService.StreamVoice:output_type -> im.v1.VoiceFrame
+	33, // 63: im.v1.IMService.HealthCheck:output_type -> im.v1.HealthCheckResponse
+	52, // [52:64] is the sub-list for method output_type
+	40, // [40:52] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
 }
 
 func init() { file_message_proto_init() }
@@ -2393,17 +3363,21 @@ func file_message_proto_init() {
 	if File_message_proto != nil {
 		return
 	}
-	file_message_proto_msgTypes[16].OneofWrappers = []any{
+	file_message_proto_msgTypes[18].OneofWrappers = []any{
 		(*UploadAudioRequest_Metadata)(nil),
 		(*UploadAudioRequest_Chunk)(nil),
 	}
+	file_message_proto_msgTypes[23].OneofWrappers = []any{
+		(*UploadAttachmentRequest_Metadata)(nil),
+		(*UploadAttachmentRequest_Chunk)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_message_proto_rawDesc), len(file_message_proto_rawDesc)),
-			NumEnums:      4,
-			NumMessages:   33,
+			NumEnums:      5,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -24,8 +24,12 @@ const (
 	IMService_JoinRoom_FullMethodName           = "/im.v1.IMService/JoinRoom"
 	IMService_LeaveRoom_FullMethodName          = "/im.v1.IMService/LeaveRoom"
 	IMService_GetRoomInfo_FullMethodName        = "/im.v1.IMService/GetRoomInfo"
+	IMService_GetHistory_FullMethodName         = "/im.v1.IMService/GetHistory"
 	IMService_GetAudioTranscript_FullMethodName = "/im.v1.IMService/GetAudioTranscript"
 	IMService_UploadAudio_FullMethodName        = "/im.v1.IMService/UploadAudio"
+	IMService_GetUploadStatus_FullMethodName    = "/im.v1.IMService/GetUploadStatus"
+	IMService_UploadAttachment_FullMethodName   = "/im.v1.IMService/UploadAttachment"
+	IMService_StreamVoice_FullMethodName        = "/im.v1.IMService/StreamVoice"
 	IMService_HealthCheck_FullMethodName        = "/im.v1.IMService/HealthCheck"
 )
 
@@ -42,8 +46,13 @@ type IMServiceClient interface {
 	JoinRoom(ctx context.Context, in *JoinRoomRequest, opts ...grpc.CallOption) (*JoinRoomResponse, error)
 	LeaveRoom(ctx context.Context, in *LeaveRoomRequest, opts ...grpc.CallOption) (*LeaveRoomResponse, error)
 	GetRoomInfo(ctx context.Context, in *GetRoomInfoRequest, opts ...grpc.CallOption) (*GetRoomInfoResponse, error)
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
 	GetAudioTranscript(ctx context.Context, in *TranscriptRequest, opts ...grpc.CallOption) (*TranscriptResponse, error)
 	UploadAudio(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAudioRequest, UploadAudioResponse], error)
+	GetUploadStatus(ctx context.Context, in *UploadStatusRequest, opts ...grpc.CallOption) (*UploadStatusResponse, error)
+	UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAttachmentRequest, UploadAttachmentResponse], error)
+	// 实时语音流，发送本端的语音帧并接收房间内其他成员的语音帧
+	StreamVoice(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[VoiceFrame, VoiceFrame], error)
 	// 健康检查
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 }
@@ -109,6 +118,16 @@ func (c *iMServiceClient) GetRoomInfo(ctx context.Context, in *GetRoomInfoReques
 	return out, nil
 }
 
+func (c *iMServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, IMService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *iMServiceClient) GetAudioTranscript(ctx context.Context, in *TranscriptRequest, opts ...grpc.CallOption) (*TranscriptResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TranscriptResponse)
@@ -132,6 +151,42 @@ func (c *iMServiceClient) UploadAudio(ctx context.Context, opts ...grpc.CallOpti
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type IMService_UploadAudioClient = grpc.ClientStreamingClient[UploadAudioRequest, UploadAudioResponse]
 
+func (c *iMServiceClient) GetUploadStatus(ctx context.Context, in *UploadStatusRequest, opts ...grpc.CallOption) (*UploadStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadStatusResponse)
+	err := c.cc.Invoke(ctx, IMService_GetUploadStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *iMServiceClient) UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAttachmentRequest, UploadAttachmentResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IMService_ServiceDesc.Streams[2], IMService_UploadAttachment_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadAttachmentRequest, UploadAttachmentResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IMService_UploadAttachmentClient = grpc.ClientStreamingClient[UploadAttachmentRequest, UploadAttachmentResponse]
+
+func (c *iMServiceClient) StreamVoice(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[VoiceFrame, VoiceFrame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IMService_ServiceDesc.Streams[3], IMService_StreamVoice_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[VoiceFrame, VoiceFrame]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IMService_StreamVoiceClient = grpc.BidiStreamingClient[VoiceFrame, VoiceFrame]
+
 func (c *iMServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -155,8 +210,13 @@ type IMServiceServer interface {
 	JoinRoom(context.Context, *JoinRoomRequest) (*JoinRoomResponse, error)
 	LeaveRoom(context.Context, *LeaveRoomRequest) (*LeaveRoomResponse, error)
 	GetRoomInfo(context.Context, *GetRoomInfoRequest) (*GetRoomInfoResponse, error)
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
 	GetAudioTranscript(context.Context, *TranscriptRequest) (*TranscriptResponse, error)
 	UploadAudio(grpc.ClientStreamingServer[UploadAudioRequest, UploadAudioResponse]) error
+	GetUploadStatus(context.Context, *UploadStatusRequest) (*UploadStatusResponse, error)
+	UploadAttachment(grpc.ClientStreamingServer[UploadAttachmentRequest, UploadAttachmentResponse]) error
+	// 实时语音流，发送本端的语音帧并接收房间内其他成员的语音帧
+	StreamVoice(grpc.BidiStreamingServer[VoiceFrame, VoiceFrame]) error
 	// 健康检查
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	mustEmbedUnimplementedIMServiceServer()
@@ -184,12 +244,24 @@ func (UnimplementedIMServiceServer) LeaveRoom(context.Context, *LeaveRoomRequest
 func (UnimplementedIMServiceServer) GetRoomInfo(context.Context, *GetRoomInfoRequest) (*GetRoomInfoResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetRoomInfo not implemented")
 }
+func (UnimplementedIMServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
 func (UnimplementedIMServiceServer) GetAudioTranscript(context.Context, *TranscriptRequest) (*TranscriptResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAudioTranscript not implemented")
 }
 func (UnimplementedIMServiceServer) UploadAudio(grpc.ClientStreamingServer[UploadAudioRequest, UploadAudioResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method UploadAudio not implemented")
 }
+func (UnimplementedIMServiceServer) GetUploadStatus(context.Context, *UploadStatusRequest) (*UploadStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadStatus not implemented")
+}
+func (UnimplementedIMServiceServer) UploadAttachment(grpc.ClientStreamingServer[UploadAttachmentRequest, UploadAttachmentResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadAttachment not implemented")
+}
+func (UnimplementedIMServiceServer) StreamVoice(grpc.BidiStreamingServer[VoiceFrame, VoiceFrame]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamVoice not implemented")
+}
 func (UnimplementedIMServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
@@ -293,6 +365,24 @@ func _IMService_GetRoomInfo_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IMService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IMServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IMService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IMServiceServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _IMService_GetAudioTranscript_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TranscriptRequest)
 	if err := dec(in); err != nil {
@@ -318,6 +408,38 @@ func _IMService_UploadAudio_Handler(srv interface{}, stream grpc.ServerStream) e
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type IMService_UploadAudioServer = grpc.ClientStreamingServer[UploadAudioRequest, UploadAudioResponse]
 
+func _IMService_GetUploadStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IMServiceServer).GetUploadStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IMService_GetUploadStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IMServiceServer).GetUploadStatus(ctx, req.(*UploadStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IMService_UploadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IMServiceServer).UploadAttachment(&grpc.GenericServerStream[UploadAttachmentRequest, UploadAttachmentResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IMService_UploadAttachmentServer = grpc.ClientStreamingServer[UploadAttachmentRequest, UploadAttachmentResponse]
+
+func _IMService_StreamVoice_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IMServiceServer).StreamVoice(&grpc.GenericServerStream[VoiceFrame, VoiceFrame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IMService_StreamVoiceServer = grpc.BidiStreamingServer[VoiceFrame, VoiceFrame]
+
 func _IMService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -359,10 +481,18 @@ var IMService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetRoomInfo",
 			Handler:    _IMService_GetRoomInfo_Handler,
 		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _IMService_GetHistory_Handler,
+		},
 		{
 			MethodName: "GetAudioTranscript",
 			Handler:    _IMService_GetAudioTranscript_Handler,
 		},
+		{
+			MethodName: "GetUploadStatus",
+			Handler:    _IMService_GetUploadStatus_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _IMService_HealthCheck_Handler,
@@ -380,6 +510,17 @@ var IMService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _IMService_UploadAudio_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "UploadAttachment",
+			Handler:       _IMService_UploadAttachment_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamVoice",
+			Handler:       _IMService_StreamVoice_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "message.proto",
 }
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"errors"
+	"sync"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// ErrAttachmentNotFound 附件不存在
+var ErrAttachmentNotFound = errors.New("附件不存在")
+
+// attachmentRecord 已上传的附件
+type attachmentRecord struct {
+	metadata *imv1.AttachmentMetadata
+	data     []byte
+}
+
+// AttachmentStore 内存附件存储
+type AttachmentStore struct {
+	records map[string]*attachmentRecord
+	mu      sync.RWMutex
+}
+
+// NewAttachmentStore 创建内存附件存储
+func NewAttachmentStore() *AttachmentStore {
+	return &AttachmentStore{
+		records: make(map[string]*attachmentRecord),
+	}
+}
+
+// Save 保存附件，缩略图保存在元数据中
+func (as *AttachmentStore) Save(attachmentID string, metadata *imv1.AttachmentMetadata, data []byte) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	as.records[attachmentID] = &attachmentRecord{
+		metadata: metadata,
+		data:     data,
+	}
+}
+
+// Data 返回附件元数据和文件数据
+func (as *AttachmentStore) Data(attachmentID string) (*imv1.AttachmentMetadata, []byte, error) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	record, exists := as.records[attachmentID]
+	if !exists {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	return record.metadata, record.data, nil
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+var (
+	// ErrAudioNotFound 音频不存在
+	ErrAudioNotFound = errors.New("音频不存在")
+	// ErrUploadNotFound 上传不存在或已过期
+	ErrUploadNotFound = errors.New("上传不存在")
+	// ErrUploadOffset 续传偏移量超过已收到的字节数
+	ErrUploadOffset = errors.New("续传偏移量无效")
+)
+
+// uploadExpiry 未完成的上传在最后一次写入后保留的时间
+const uploadExpiry = time.Hour
+
+// Transcriber 语音转写接口
+type Transcriber interface {
+	// Transcribe 转写音频，返回文本和置信度
+	Transcribe(ctx context.Context, metadata *imv1.AudioMetadata, data []byte) (string, float64, error)
+}
+
+// audioRecord 已上传的音频
+type audioRecord struct {
+	metadata      *imv1.AudioMetadata
+	data          []byte
+	transcription *imv1.Transcription
+}
+
+// partialUpload 未完成的可续传上传
+type partialUpload struct {
+	userID  string
+	data    []byte
+	updated time.Time
+}
+
+// AudioStore 内存音频存储，上传后异步转写
+type AudioStore struct {
+	records     map[string]*audioRecord
+	uploads     map[string]*partialUpload
+	transcriber Transcriber
+	mu          sync.RWMutex
+}
+
+// NewAudioStore 创建内存音频存储，transcriber 为nil时转写直接标记为失败
+func NewAudioStore(transcriber Transcriber) *AudioStore {
+	return &AudioStore{
+		records:     make(map[string]*audioRecord),
+		uploads:     make(map[string]*partialUpload),
+		transcriber: transcriber,
+	}
+}
+
+// ResumeUpload 开始或继续一次可续传上传，返回从 offset 开始续传时已收到的数据
+//
+// offset 为0时丢弃之前收到的数据重新开始；offset 超过已收到的字节数时返回 ErrUploadOffset。
+func (as *AudioStore) ResumeUpload(uploadID, userID string, offset int64) ([]byte, error) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	as.expireUploadsLocked(time.Now())
+
+	upload, exists := as.uploads[uploadID]
+	if exists && upload.userID != userID {
+		return nil, ErrUploadNotFound
+	}
+	if offset == 0 {
+		as.uploads[uploadID] = &partialUpload{userID: userID, updated: time.Now()}
+		return nil, nil
+	}
+	if !exists {
+		return nil, ErrUploadNotFound
+	}
+	if offset > int64(len(upload.data)) {
+		return nil, ErrUploadOffset
+	}
+
+	// 复制一份，避免与可能仍在写入的旧上传共享底层数组
+	data := append([]byte(nil), upload.data[:offset]...)
+	upload.data = data
+	upload.updated = time.Now()
+	return data, nil
+}
+
+// UpdateUpload 记录上传已收到的数据
+func (as *AudioStore) UpdateUpload(uploadID string, data []byte) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	if upload, exists := as.uploads[uploadID]; exists {
+		upload.data = data
+		upload.updated = time.Now()
+	}
+}
+
+// UploadStatus 返回上传已收到的字节数
+func (as *AudioStore) UploadStatus(uploadID, userID string) (int64, error) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	upload, exists := as.uploads[uploadID]
+	if !exists || upload.userID != userID {
+		return 0, ErrUploadNotFound
+	}
+	return int64(len(upload.data)), nil
+}
+
+// FinishUpload 移除已完成的上传
+func (as *AudioStore) FinishUpload(uploadID string) {
+	as.mu.Lock()
+	delete(as.uploads, uploadID)
+	as.mu.Unlock()
+}
+
+// expireUploadsLocked 清理过期的未完成上传，调用方需持有写锁
+func (as *AudioStore) expireUploadsLocked(now time.Time) {
+	for id, upload := range as.uploads {
+		if now.Sub(upload.updated) > uploadExpiry {
+			delete(as.uploads, id)
+		}
+	}
+}
+
+// Save 保存音频并启动转写
+func (as *AudioStore) Save(audioID string, metadata *imv1.AudioMetadata, data []byte) {
+	now := timestamppb.New(time.Now())
+	record := &audioRecord{
+		metadata: metadata,
+		data:     data,
+		transcription: &imv1.Transcription{
+			AudioId:   audioID,
+			Status:    imv1.TranscriptStatus_TRANSCRIPT_STATUS_PENDING,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	as.mu.Lock()
+	as.records[audioID] = record
+	as.mu.Unlock()
+
+	go as.transcribe(audioID, record)
+}
+
+// Data 返回音频数据
+func (as *AudioStore) Data(audioID string) (*imv1.AudioMetadata, []byte, error) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	record, exists := as.records[audioID]
+	if !exists {
+		return nil, nil, ErrAudioNotFound
+	}
+	return record.metadata, record.data, nil
+}
+
+// Transcription 返回转写结果
+func (as *AudioStore) Transcription(audioID string) (*imv1.Transcription, error) {
+	as.mu.RLock()
+	defer as.mu.RUnlock()
+
+	record, exists := as.records[audioID]
+	if !exists {
+		return nil, ErrAudioNotFound
+	}
+	return proto.Clone(record.transcription).(*imv1.Transcription), nil
+}
+
+// transcribe 执行转写并更新状态
+func (as *AudioStore) transcribe(audioID string, record *audioRecord) {
+	if as.transcriber == nil {
+		as.updateTranscription(record, imv1.TranscriptStatus_TRANSCRIPT_STATUS_FAILED, "", 0)
+		return
+	}
+
+	as.updateTranscription(record, imv1.TranscriptStatus_TRANSCRIPT_STATUS_PROCESSING, "", 0)
+
+	text, confidence, err := as.transcriber.Transcribe(context.Background(), record.metadata, record.data)
+	if err != nil {
+		as.updateTranscription(record, imv1.TranscriptStatus_TRANSCRIPT_STATUS_FAILED, "", 0)
+		return
+	}
+
+	as.updateTranscription(record, imv1.TranscriptStatus_TRANSCRIPT_STATUS_COMPLETED, text, confidence)
+}
+
+// updateTranscription 更新转写状态
+func (as *AudioStore) updateTranscription(record *audioRecord, status imv1.TranscriptStatus, text string, confidence float64) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	record.transcription.Status = status
+	record.transcription.Text = text
+	record.transcription.Confidence = confidence
+	record.transcription.UpdatedAt = timestamppb.New(time.Now())
+}
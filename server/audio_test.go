@@ -0,0 +1,62 @@
+package server_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Dev-Umb/im-grpc-sdk/server"
+)
+
+func TestAudioStoreResumeUpload(t *testing.T) {
+	store := server.NewAudioStore(nil)
+
+	if _, err := store.ResumeUpload("upload1", "alice", 3); !errors.Is(err, server.ErrUploadNotFound) {
+		t.Fatalf("续传不存在的上传 err = %v, want ErrUploadNotFound", err)
+	}
+	if _, err := store.ResumeUpload("upload1", "alice", 0); err != nil {
+		t.Fatalf("ResumeUpload: %v", err)
+	}
+	store.UpdateUpload("upload1", []byte("hello"))
+
+	tests := []struct {
+		name     string
+		userID   string
+		offset   int64
+		want     string
+		wantErr  error
+		received int64
+	}{
+		{"从已收到的位置续传", "alice", 5, "hello", nil, 5},
+		{"从中间位置续传丢弃之后的数据", "alice", 3, "hel", nil, 3},
+		{"偏移量超过已收到的字节数", "alice", 4, "", server.ErrUploadOffset, 3},
+		{"其他用户不能续传", "bob", 2, "", server.ErrUploadNotFound, 3},
+		{"偏移量为0时重新开始", "alice", 0, "", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := store.ResumeUpload("upload1", tt.userID, tt.offset)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResumeUpload err = %v, want %v", err, tt.wantErr)
+			}
+			if string(data) != tt.want {
+				t.Fatalf("ResumeUpload = %q, want %q", data, tt.want)
+			}
+			received, err := store.UploadStatus("upload1", "alice")
+			if err != nil {
+				t.Fatalf("UploadStatus: %v", err)
+			}
+			if received != tt.received {
+				t.Fatalf("UploadStatus = %d, want %d", received, tt.received)
+			}
+		})
+	}
+
+	if _, err := store.UploadStatus("upload1", "bob"); !errors.Is(err, server.ErrUploadNotFound) {
+		t.Fatalf("其他用户查询上传 err = %v, want ErrUploadNotFound", err)
+	}
+	store.FinishUpload("upload1")
+	if _, err := store.UploadStatus("upload1", "alice"); !errors.Is(err, server.ErrUploadNotFound) {
+		t.Fatalf("完成后查询上传 err = %v, want ErrUploadNotFound", err)
+	}
+}
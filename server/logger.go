@@ -0,0 +1,19 @@
+package server
+
+// Logger 结构化日志接口，args 为交替出现的键值对
+//
+// *slog.Logger 和 client.Logger 的实现都可以直接使用。
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// nopLogger 丢弃所有日志，未配置 Logger 时使用
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
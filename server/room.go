@@ -0,0 +1,413 @@
+package server
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+var (
+	// ErrRoomNotFound 房间不存在
+	ErrRoomNotFound = errors.New("房间不存在")
+	// ErrRoomFull 房间人数已满
+	ErrRoomFull = errors.New("房间人数已满")
+	// ErrNotMember 用户不在房间中
+	ErrNotMember = errors.New("用户不在房间中")
+	// ErrInvalidCursor 历史消息游标无效
+	ErrInvalidCursor = errors.New("历史消息游标无效")
+)
+
+const (
+	defaultHistorySize  = 1000
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+// room 房间状态
+type room struct {
+	info  *imv1.RoomInfo
+	users map[string]*imv1.RoomUser
+
+	// history 最近的消息，history[i] 的序号为 historyBase+i+1
+	history     []*imv1.MessageResponse
+	historyBase uint64
+	// published history 中消息的ID，用于丢弃客户端重试产生的重复消息
+	published map[string]*imv1.MessageResponse
+}
+
+// session 一个用户的一条消息流
+type session struct {
+	id          uint64
+	userID      string
+	send        chan *imv1.MessageResponse
+	sequenceAck bool
+}
+
+// RoomManager 内存房间管理器，负责房间、成员关系和消息扇出
+type RoomManager struct {
+	rooms         map[string]*room
+	sequences     map[string]uint64              // 已删除房间的最后序号，房间重建后序号继续递增
+	sessions      map[string]map[uint64]*session // userID -> sessionID -> session
+	nextSessionID uint64
+	roomConfig    *imv1.RoomConfig
+	sessionBuffer int
+	historySize   int
+	mu            sync.RWMutex
+}
+
+// HistoryQuery 历史消息查询条件
+type HistoryQuery struct {
+	// Cursor 上一页最后一条消息的序号，为0时从 StartTime 开始
+	Cursor uint64
+	// StartTime 向后翻页时返回此时间之前的消息，向前翻页时返回此时间及之后的消息；为零值时从最新或最早的消息开始
+	StartTime time.Time
+	Forward   bool
+	Limit     int
+	Types     []imv1.MessageType
+}
+
+// NewRoomManager 创建内存房间管理器，roomConfig 为新建房间的默认配置，historySize 为每个房间保留的历史消息数
+func NewRoomManager(roomConfig *imv1.RoomConfig, sessionBuffer, historySize int) *RoomManager {
+	if roomConfig == nil {
+		roomConfig = &imv1.RoomConfig{}
+	}
+	if sessionBuffer <= 0 {
+		sessionBuffer = 256
+	}
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+
+	return &RoomManager{
+		rooms:         make(map[string]*room),
+		sequences:     make(map[string]uint64),
+		sessions:      make(map[string]map[uint64]*session),
+		roomConfig:    roomConfig,
+		sessionBuffer: sessionBuffer,
+		historySize:   historySize,
+	}
+}
+
+// Join 用户加入房间，房间不存在时自动创建；返回房间信息和在线用户
+func (rm *RoomManager) Join(roomID, userID string, metadata map[string]string) (*imv1.RoomInfo, []string, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	now := timestamppb.New(time.Now())
+
+	r, exists := rm.rooms[roomID]
+	if !exists {
+		r = &room{
+			info: &imv1.RoomInfo{
+				RoomId:     roomID,
+				Name:       roomID,
+				Config:     proto.Clone(rm.roomConfig).(*imv1.RoomConfig),
+				CreatedAt:  now,
+				LastActive: now,
+			},
+			users:       make(map[string]*imv1.RoomUser),
+			historyBase: rm.sequences[roomID],
+			published:   make(map[string]*imv1.MessageResponse),
+		}
+		r.info.LastSequence = r.historyBase
+		rm.rooms[roomID] = r
+		delete(rm.sequences, roomID)
+	}
+
+	if _, joined := r.users[userID]; !joined {
+		if maxUsers := r.info.Config.GetMaxUsers(); maxUsers > 0 && len(r.users) >= int(maxUsers) {
+			return nil, nil, ErrRoomFull
+		}
+
+		r.users[userID] = &imv1.RoomUser{
+			UserId:   userID,
+			Nickname: metadata["nickname"],
+			Role:     imv1.UserRole_USER_ROLE_USER,
+			JoinedAt: now,
+		}
+		r.info.UserCount = int32(len(r.users))
+	}
+	r.info.LastActive = now
+
+	return proto.Clone(r.info).(*imv1.RoomInfo), rm.onlineUsersLocked(r), nil
+}
+
+// Leave 用户离开房间，最后一个用户离开后删除非持久化的房间
+func (rm *RoomManager) Leave(roomID, userID string) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	r, exists := rm.rooms[roomID]
+	if !exists {
+		return ErrRoomNotFound
+	}
+	if _, joined := r.users[userID]; !joined {
+		return ErrNotMember
+	}
+
+	delete(r.users, userID)
+	r.info.UserCount = int32(len(r.users))
+	r.info.LastActive = timestamppb.New(time.Now())
+
+	if len(r.users) == 0 && !r.info.Config.GetPersistent() {
+		rm.sequences[roomID] = r.info.LastSequence
+		delete(rm.rooms, roomID)
+	}
+
+	return nil
+}
+
+// Room 返回房间信息和成员列表
+func (rm *RoomManager) Room(roomID string) (*imv1.RoomInfo, []*imv1.RoomUser, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	r, exists := rm.rooms[roomID]
+	if !exists {
+		return nil, nil, ErrRoomNotFound
+	}
+
+	users := make([]*imv1.RoomUser, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, proto.Clone(user).(*imv1.RoomUser))
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserId < users[j].UserId
+	})
+
+	return proto.Clone(r.info).(*imv1.RoomInfo), users, nil
+}
+
+// IsMember 判断用户是否在房间中
+func (rm *RoomManager) IsMember(roomID, userID string) bool {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	r, exists := rm.rooms[roomID]
+	if !exists {
+		return false
+	}
+	_, joined := r.users[userID]
+	return joined
+}
+
+// Publish 为消息分配房间内的序号并扇出给房间内所有成员的消息流（exclude 指定的流除外），返回投递的流数量；
+// 消费过慢的流会丢弃消息，之后可以按序号通过 History 补齐。
+//
+// 同一用户的 MessageId 与保留的历史消息重复时（客户端重试）不再发布，
+// msg 的序号和时间戳改为已发布消息的值，返回0。
+func (rm *RoomManager) Publish(msg *imv1.MessageResponse, exclude uint64) (int, error) {
+	rm.mu.Lock()
+	r, exists := rm.rooms[msg.RoomId]
+	if !exists {
+		rm.mu.Unlock()
+		return 0, ErrRoomNotFound
+	}
+
+	if original, dup := r.published[msg.MessageId]; dup && original.FromUserId == msg.FromUserId {
+		msg.Sequence = original.Sequence
+		msg.Timestamp = original.Timestamp
+		rm.mu.Unlock()
+		return 0, nil
+	}
+
+	if msg.Type != imv1.MessageType_MESSAGE_TYPE_SYSTEM {
+		r.info.MessageCount++
+	}
+	r.info.LastActive = timestamppb.New(time.Now())
+
+	r.info.LastSequence++
+	msg.Sequence = r.info.LastSequence
+	r.history = append(r.history, msg)
+	if msg.MessageId != "" {
+		r.published[msg.MessageId] = msg
+	}
+	if len(r.history) > rm.historySize {
+		dropped := len(r.history) - rm.historySize
+		for _, old := range r.history[:dropped] {
+			if r.published[old.MessageId] == old {
+				delete(r.published, old.MessageId)
+			}
+		}
+		r.history = append(r.history[:0:0], r.history[dropped:]...)
+		r.historyBase += uint64(dropped)
+	}
+
+	var targets []*session
+	for userID := range r.users {
+		for id, s := range rm.sessions[userID] {
+			if id != exclude {
+				targets = append(targets, s)
+			}
+		}
+	}
+	rm.mu.Unlock()
+
+	delivered := 0
+	for _, s := range targets {
+		if deliver(s, msg) {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// History 按查询条件翻页返回房间的历史消息，消息按翻页方向排列；
+// next 为本页最后一条消息的序号，作为下一页的 Cursor。超过房间 message_ttl_seconds 的消息不会返回
+func (rm *RoomManager) History(roomID string, q HistoryQuery) (msgs []*imv1.MessageResponse, next uint64, hasMore bool, err error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	r, exists := rm.rooms[roomID]
+	if !exists {
+		return nil, 0, false, ErrRoomNotFound
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+
+	var expiry time.Time
+	if ttl := r.info.Config.GetMessageTtlSeconds(); ttl > 0 {
+		expiry = time.Now().Add(-time.Duration(ttl) * time.Second)
+	}
+
+	// 游标为消息序号，history[i] 的序号为 historyBase+i+1
+	start, step := len(r.history)-1, -1
+	if q.Forward {
+		start, step = 0, 1
+	}
+	if q.Cursor > 0 {
+		offset := int(min(max(q.Cursor, r.historyBase), r.historyBase+uint64(len(r.history))) - r.historyBase)
+		if q.Forward {
+			start = offset
+		} else {
+			start = offset - 2
+			if q.Cursor > r.historyBase+uint64(len(r.history)) {
+				start = len(r.history) - 1
+			}
+		}
+	}
+
+	next = q.Cursor
+	for i := start; i >= 0 && i < len(r.history); i += step {
+		msg := r.history[i]
+		sentAt := msg.Timestamp.AsTime()
+		if !expiry.IsZero() && sentAt.Before(expiry) {
+			continue
+		}
+		// 向前翻页跳过 StartTime 之前的消息，向后翻页跳过 StartTime 及之后的消息
+		if q.Cursor == 0 && !q.StartTime.IsZero() && sentAt.Before(q.StartTime) == q.Forward {
+			continue
+		}
+		if len(q.Types) > 0 && !slices.Contains(q.Types, msg.Type) {
+			continue
+		}
+		if len(msgs) == limit {
+			hasMore = true
+			break
+		}
+		msgs = append(msgs, proto.Clone(msg).(*imv1.MessageResponse))
+		next = r.historyBase + uint64(i) + 1
+	}
+	if q.Forward && !hasMore {
+		// 已扫描到最新的消息，之后的新消息从这里继续
+		next = max(next, r.historyBase+uint64(len(r.history)))
+	}
+	return msgs, next, hasMore, nil
+}
+
+// SendToUser 将消息发送给用户的所有消息流，返回投递的流数量
+func (rm *RoomManager) SendToUser(userID string, msg *imv1.MessageResponse) int {
+	rm.mu.RLock()
+	targets := make([]*session, 0, len(rm.sessions[userID]))
+	for _, s := range rm.sessions[userID] {
+		targets = append(targets, s)
+	}
+	rm.mu.RUnlock()
+
+	delivered := 0
+	for _, s := range targets {
+		if deliver(s, msg) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// OnlineUsers 返回房间内有活跃消息流的用户
+func (rm *RoomManager) OnlineUsers(roomID string) []string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+
+	r, exists := rm.rooms[roomID]
+	if !exists {
+		return nil
+	}
+	return rm.onlineUsersLocked(r)
+}
+
+// onlineUsersLocked 返回房间内有活跃消息流的用户，调用时需持有锁
+func (rm *RoomManager) onlineUsersLocked(r *room) []string {
+	users := make([]string, 0, len(r.users))
+	for userID := range r.users {
+		if len(rm.sessions[userID]) > 0 {
+			users = append(users, userID)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// addSession 登记用户的消息流
+func (rm *RoomManager) addSession(userID string) *session {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.nextSessionID++
+	s := &session{
+		id:     rm.nextSessionID,
+		userID: userID,
+		send:   make(chan *imv1.MessageResponse, rm.sessionBuffer),
+	}
+
+	if rm.sessions[userID] == nil {
+		rm.sessions[userID] = make(map[uint64]*session)
+	}
+	rm.sessions[userID][s.id] = s
+
+	return s
+}
+
+// removeSession 移除用户的消息流
+func (rm *RoomManager) removeSession(s *session) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	delete(rm.sessions[s.userID], s.id)
+	if len(rm.sessions[s.userID]) == 0 {
+		delete(rm.sessions, s.userID)
+	}
+}
+
+// deliver 非阻塞投递消息到消息流
+func deliver(s *session, msg *imv1.MessageResponse) bool {
+	select {
+	case s.send <- msg:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package server_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+	"github.com/Dev-Umb/im-grpc-sdk/server"
+)
+
+func TestRoomManagerHistory(t *testing.T) {
+	// 保留5条历史消息，发布8条后序号1到3已被截断
+	rooms := server.NewRoomManager(&imv1.RoomConfig{}, 16, 5)
+	if _, _, err := rooms.Join("room1", "alice", nil); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	for i := 1; i <= 8; i++ {
+		msg := &imv1.MessageResponse{
+			MessageId:  fmt.Sprintf("msg-%d", i),
+			Type:       imv1.MessageType_MESSAGE_TYPE_TEXT,
+			RoomId:     "room1",
+			FromUserId: "alice",
+		}
+		if _, err := rooms.Publish(msg, 0); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		query       server.HistoryQuery
+		want        []uint64
+		wantNext    uint64
+		wantHasMore bool
+	}{
+		{"向前翻页第一页", server.HistoryQuery{Forward: true, Limit: 2}, []uint64{4, 5}, 5, true},
+		{"向前翻页下一页", server.HistoryQuery{Forward: true, Cursor: 5, Limit: 2}, []uint64{6, 7}, 7, true},
+		{"向前翻页最后一页", server.HistoryQuery{Forward: true, Cursor: 7, Limit: 2}, []uint64{8}, 8, false},
+		{"向前翻页游标已是最新", server.HistoryQuery{Forward: true, Cursor: 8, Limit: 2}, nil, 8, false},
+		{"向前翻页游标超出最新", server.HistoryQuery{Forward: true, Cursor: 20, Limit: 2}, nil, 20, false},
+		{"向前翻页游标已被截断", server.HistoryQuery{Forward: true, Cursor: 1, Limit: 10}, []uint64{4, 5, 6, 7, 8}, 8, false},
+		{"向前翻页游标为截断边界", server.HistoryQuery{Forward: true, Cursor: 3, Limit: 10}, []uint64{4, 5, 6, 7, 8}, 8, false},
+		{"向后翻页第一页", server.HistoryQuery{Limit: 2}, []uint64{8, 7}, 7, true},
+		{"向后翻页下一页", server.HistoryQuery{Cursor: 7, Limit: 2}, []uint64{6, 5}, 5, true},
+		{"向后翻页最后一页", server.HistoryQuery{Cursor: 5, Limit: 2}, []uint64{4}, 4, false},
+		{"向后翻页游标为最早保留的消息", server.HistoryQuery{Cursor: 4, Limit: 2}, nil, 4, false},
+		{"向后翻页游标已被截断", server.HistoryQuery{Cursor: 2, Limit: 2}, nil, 2, false},
+		{"向后翻页游标为最新的下一条", server.HistoryQuery{Cursor: 9, Limit: 2}, []uint64{8, 7}, 7, true},
+		{"向后翻页游标超出最新", server.HistoryQuery{Cursor: 20, Limit: 2}, []uint64{8, 7}, 7, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, next, hasMore, err := rooms.History("room1", tt.query)
+			if err != nil {
+				t.Fatalf("History: %v", err)
+			}
+			var got []uint64
+			for _, msg := range msgs {
+				got = append(got, msg.Sequence)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("History = %v, want %v", got, tt.want)
+			}
+			if next != tt.wantNext || hasMore != tt.wantHasMore {
+				t.Fatalf("next = %d, hasMore = %v, want %d, %v", next, hasMore, tt.wantNext, tt.wantHasMore)
+			}
+		})
+	}
+}
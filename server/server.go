@@ -0,0 +1,773 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// 与客户端约定的metadata key
+const (
+	metadataUserID          = "user-id"
+	metadataRoomID          = "room-id"
+	metadataAckRequired     = "ack-required"
+	metadataContentEncoding = "content-encoding"
+	metadataSequenceAck     = "sequence-ack" // 流上发送的每条消息都回复携带序号的ACK
+)
+
+// Config 服务端配置
+type Config struct {
+	// 新建房间的默认配置
+	RoomConfig *imv1.RoomConfig `json:"room_config"`
+
+	// 每条消息流的发送缓冲，消费过慢时丢弃消息
+	SessionBufferSize int `json:"session_buffer_size"`
+	HistorySize       int `json:"history_size"` // 每个房间保留的历史消息数
+
+	// 音频配置
+	MaxAudioSize   int64       `json:"max_audio_size"`
+	AudioURLPrefix string      `json:"audio_url_prefix"`
+	Transcriber    Transcriber `json:"-"`
+
+	// 附件配置
+	MaxAttachmentSize   int64  `json:"max_attachment_size"`
+	AttachmentURLPrefix string `json:"attachment_url_prefix"`
+
+	Logger Logger `json:"-"` // 结构化日志，为nil时不输出日志
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		RoomConfig: &imv1.RoomConfig{
+			AllowAudio:    true,
+			AllowRichText: true,
+		},
+		SessionBufferSize: 256,
+		HistorySize:       defaultHistorySize,
+		MaxAudioSize:      50 * 1024 * 1024,
+		AudioURLPrefix:    "memory://audio/",
+
+		MaxAttachmentSize:   100 * 1024 * 1024,
+		AttachmentURLPrefix: "memory://attachments/",
+	}
+}
+
+// Server IMService 参考实现，房间、成员关系和音频都保存在内存中
+type Server struct {
+	imv1.UnimplementedIMServiceServer
+
+	config      *Config
+	rooms       *RoomManager
+	audio       *AudioStore
+	attachments *AttachmentStore
+	voice       *VoiceHub
+	logger      Logger
+	serving     atomic.Bool
+	seq         atomic.Uint64
+}
+
+// NewServer 创建IM服务端
+func NewServer(config *Config) *Server {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	s := &Server{
+		config:      config,
+		rooms:       NewRoomManager(config.RoomConfig, config.SessionBufferSize, config.HistorySize),
+		audio:       NewAudioStore(config.Transcriber),
+		voice:       NewVoiceHub(),
+		attachments: NewAttachmentStore(),
+		logger:      config.Logger,
+	}
+	if s.logger == nil {
+		s.logger = nopLogger{}
+	}
+	s.serving.Store(true)
+
+	return s
+}
+
+// Register 将服务注册到gRPC服务器
+func (s *Server) Register(grpcServer *grpc.Server) {
+	imv1.RegisterIMServiceServer(grpcServer, s)
+}
+
+// Rooms 返回房间管理器
+func (s *Server) Rooms() *RoomManager {
+	return s.rooms
+}
+
+// Audio 返回音频存储
+func (s *Server) Audio() *AudioStore {
+	return s.audio
+}
+
+// Attachments 返回附件存储
+func (s *Server) Attachments() *AttachmentStore {
+	return s.attachments
+}
+
+// Voice 返回语音转发器
+func (s *Server) Voice() *VoiceHub {
+	return s.voice
+}
+
+// SetServing 设置健康检查状态
+func (s *Server) SetServing(serving bool) {
+	s.serving.Store(serving)
+}
+
+// StreamMessages 双向流消息
+//
+// 用户身份优先从 user-id metadata 读取，没有时回退为读取第一条消息的 user_id；
+// metadata 中带有 room-id 时自动加入该房间。
+func (s *Server) StreamMessages(stream grpc.BidiStreamingServer[imv1.MessageRequest, imv1.MessageResponse]) error {
+	ctx := stream.Context()
+	userID := incomingValue(ctx, metadataUserID)
+	roomID := incomingValue(ctx, metadataRoomID)
+
+	var first *imv1.MessageRequest
+	if userID == "" {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if msg.UserId == "" {
+			return status.Error(codes.InvalidArgument, "缺少用户ID")
+		}
+		userID = msg.UserId
+		first = msg
+	}
+
+	sess := s.rooms.addSession(userID)
+	defer s.rooms.removeSession(sess)
+	s.logger.Debug("消息流已建立", "user_id", userID, "room_id", roomID)
+	defer s.logger.Debug("消息流已关闭", "user_id", userID)
+	sess.sequenceAck = incomingValue(ctx, metadataSequenceAck) == "true"
+
+	if roomID != "" {
+		if _, _, err := s.joinRoom(roomID, userID, nil); err != nil {
+			return status.Errorf(codes.FailedPrecondition, "加入房间 %s 失败: %v", roomID, err)
+		}
+	}
+
+	// 所有发往该流的消息都经由 session 的发送队列，由单独的goroutine写入流
+	sendErr := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-sess.send:
+				if err := stream.Send(msg); err != nil {
+					sendErr <- err
+					return
+				}
+			}
+		}
+	}()
+
+	if first != nil {
+		s.handleStreamMessage(sess, first)
+	}
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case err := <-sendErr:
+			return err
+		default:
+		}
+
+		s.handleStreamMessage(sess, msg)
+	}
+}
+
+// handleStreamMessage 处理流上收到的消息，发送者身份以流的用户为准
+func (s *Server) handleStreamMessage(sess *session, msg *imv1.MessageRequest) {
+	switch msg.Type {
+	case imv1.MessageType_MESSAGE_TYPE_HEARTBEAT:
+		deliver(sess, &imv1.MessageResponse{
+			MessageId:  s.nextMessageID(),
+			FromUserId: "system",
+			RoomId:     msg.RoomId,
+			Type:       imv1.MessageType_MESSAGE_TYPE_HEARTBEAT,
+			Content:    []byte("pong"),
+			Timestamp:  timestamppb.New(time.Now()),
+		})
+	case imv1.MessageType_MESSAGE_TYPE_ACK:
+		// 客户端对投递消息的确认，参考实现不做重传，直接忽略
+	case imv1.MessageType_MESSAGE_TYPE_JOIN_ROOM:
+		s.joinRoom(msg.RoomId, sess.userID, msg.Metadata)
+	case imv1.MessageType_MESSAGE_TYPE_LEAVE_ROOM:
+		s.leaveRoom(msg.RoomId, sess.userID)
+	default:
+		published, err := s.publish(&imv1.MessageResponse{
+			MessageId:  msg.MessageId,
+			FromUserId: sess.userID,
+			RoomId:     msg.RoomId,
+			Type:       msg.Type,
+			Content:    msg.Content,
+			Timestamp:  msg.Timestamp,
+			Metadata:   msg.Metadata,
+		}, sess.id)
+		if err != nil {
+			s.logger.Warn("转发消息失败", "user_id", sess.userID, "room_id", msg.RoomId, "message_id", msg.MessageId, "error", err)
+		}
+
+		// 发送者的流不会收到自己的消息，sequence-ack 让发送者得知消息占用的序号
+		if msg.Metadata[metadataAckRequired] == "true" || sess.sequenceAck {
+			deliver(sess, s.ackMessage(msg, published, err))
+		}
+	}
+}
+
+// SendMessage 发送消息
+func (s *Server) SendMessage(ctx context.Context, req *imv1.SendMessageRequest) (*imv1.SendMessageResponse, error) {
+	userID := requestUserID(ctx, req.UserId)
+	if userID == "" || req.RoomId == "" {
+		return &imv1.SendMessageResponse{Status: errorStatus(codes.InvalidArgument, "用户ID和房间ID不能为空")}, nil
+	}
+
+	msg, err := s.publish(&imv1.MessageResponse{
+		MessageId:   req.MessageId,
+		FromUserId:  userID,
+		RoomId:      req.RoomId,
+		Type:        req.Type,
+		Content:     req.Content,
+		Metadata:    req.Metadata,
+		AckRequired: req.AckRequired,
+	}, 0)
+	if err != nil {
+		return &imv1.SendMessageResponse{Status: statusFromError(err)}, nil
+	}
+
+	return &imv1.SendMessageResponse{
+		MessageId: msg.MessageId,
+		Timestamp: msg.Timestamp,
+		Status:    okStatus(),
+		Sequence:  msg.Sequence,
+	}, nil
+}
+
+// JoinRoom 加入房间
+func (s *Server) JoinRoom(ctx context.Context, req *imv1.JoinRoomRequest) (*imv1.JoinRoomResponse, error) {
+	userID := requestUserID(ctx, req.UserId)
+	if userID == "" || req.RoomId == "" {
+		return &imv1.JoinRoomResponse{Status: errorStatus(codes.InvalidArgument, "用户ID和房间ID不能为空")}, nil
+	}
+
+	info, online, err := s.joinRoom(req.RoomId, userID, req.Metadata)
+	if err != nil {
+		return &imv1.JoinRoomResponse{Status: statusFromError(err)}, nil
+	}
+
+	return &imv1.JoinRoomResponse{
+		Status:      okStatus(),
+		RoomInfo:    info,
+		OnlineUsers: online,
+	}, nil
+}
+
+// LeaveRoom 离开房间
+func (s *Server) LeaveRoom(ctx context.Context, req *imv1.LeaveRoomRequest) (*imv1.LeaveRoomResponse, error) {
+	userID := requestUserID(ctx, req.UserId)
+	if userID == "" || req.RoomId == "" {
+		return &imv1.LeaveRoomResponse{Status: errorStatus(codes.InvalidArgument, "用户ID和房间ID不能为空")}, nil
+	}
+
+	if err := s.leaveRoom(req.RoomId, userID); err != nil {
+		return &imv1.LeaveRoomResponse{Status: statusFromError(err)}, nil
+	}
+
+	return &imv1.LeaveRoomResponse{Status: okStatus()}, nil
+}
+
+// GetRoomInfo 获取房间信息
+func (s *Server) GetRoomInfo(ctx context.Context, req *imv1.GetRoomInfoRequest) (*imv1.GetRoomInfoResponse, error) {
+	info, users, err := s.rooms.Room(req.RoomId)
+	if err != nil {
+		return &imv1.GetRoomInfoResponse{Status: statusFromError(err)}, nil
+	}
+
+	return &imv1.GetRoomInfoResponse{
+		Status:   okStatus(),
+		RoomInfo: info,
+		Users:    users,
+	}, nil
+}
+
+// GetHistory 分页获取房间的历史消息，只有房间成员可以获取
+func (s *Server) GetHistory(ctx context.Context, req *imv1.GetHistoryRequest) (*imv1.GetHistoryResponse, error) {
+	if !s.rooms.IsMember(req.RoomId, requestUserID(ctx, req.UserId)) {
+		if _, _, err := s.rooms.Room(req.RoomId); err != nil {
+			return &imv1.GetHistoryResponse{Status: statusFromError(err)}, nil
+		}
+		return &imv1.GetHistoryResponse{Status: statusFromError(ErrNotMember)}, nil
+	}
+
+	query := HistoryQuery{
+		Forward: req.Direction == imv1.HistoryDirection_HISTORY_DIRECTION_FORWARD,
+		Limit:   int(req.Limit),
+		Types:   req.Types,
+	}
+	if req.Cursor != "" {
+		cursor, err := strconv.ParseUint(req.Cursor, 10, 64)
+		if err != nil || cursor == 0 {
+			return &imv1.GetHistoryResponse{Status: statusFromError(ErrInvalidCursor)}, nil
+		}
+		query.Cursor = cursor
+	} else if req.StartTime != nil {
+		query.StartTime = req.StartTime.AsTime()
+	}
+
+	msgs, next, hasMore, err := s.rooms.History(req.RoomId, query)
+	if err != nil {
+		return &imv1.GetHistoryResponse{Status: statusFromError(err)}, nil
+	}
+
+	resp := &imv1.GetHistoryResponse{
+		Status:   okStatus(),
+		Messages: msgs,
+		HasMore:  hasMore,
+	}
+	if next > 0 {
+		resp.NextCursor = strconv.FormatUint(next, 10)
+	}
+	return resp, nil
+}
+
+// UploadAudio 上传音频，第一条消息必须是音频元数据，之后为音频数据块
+//
+// 元数据带有 upload_id 时支持断点续传：已收到的数据在流中断后保留，
+// 客户端通过 GetUploadStatus 查询已收到的字节数，再以该偏移量重新上传剩余数据。
+// 元数据带有 checksum 时校验完整音频的SHA-256。
+func (s *Server) UploadAudio(stream grpc.ClientStreamingServer[imv1.UploadAudioRequest, imv1.UploadAudioResponse]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	meta := first.GetMetadata()
+	if meta == nil {
+		return stream.SendAndClose(&imv1.UploadAudioResponse{Status: errorStatus(codes.InvalidArgument, "第一条消息必须是音频元数据")})
+	}
+	if meta.UserId == "" {
+		meta.UserId = incomingValue(stream.Context(), metadataUserID)
+	}
+
+	var data []byte
+	if meta.UploadId != "" {
+		if data, err = s.audio.ResumeUpload(meta.UploadId, meta.UserId, meta.Offset); err != nil {
+			return stream.SendAndClose(&imv1.UploadAudioResponse{Status: statusFromError(err)})
+		}
+	} else if meta.Offset != 0 {
+		return stream.SendAndClose(&imv1.UploadAudioResponse{Status: errorStatus(codes.InvalidArgument, "续传必须指定上传ID")})
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		data = append(data, req.GetChunk()...)
+		if s.config.MaxAudioSize > 0 && int64(len(data)) > s.config.MaxAudioSize {
+			if meta.UploadId != "" {
+				s.audio.FinishUpload(meta.UploadId)
+			}
+			return stream.SendAndClose(&imv1.UploadAudioResponse{Status: errorStatus(codes.ResourceExhausted, "音频文件过大")})
+		}
+		if meta.UploadId != "" {
+			s.audio.UpdateUpload(meta.UploadId, data)
+		}
+	}
+
+	// 数据已全部收到，之后的校验失败需要重新上传
+	if meta.UploadId != "" {
+		s.audio.FinishUpload(meta.UploadId)
+	}
+
+	if meta.Size > 0 && meta.Size != int64(len(data)) {
+		return stream.SendAndClose(&imv1.UploadAudioResponse{
+			Status: errorStatus(codes.DataLoss, fmt.Sprintf("音频大小不匹配: 声明 %d 字节，实际 %d 字节", meta.Size, len(data))),
+		})
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if meta.Checksum != "" && !strings.EqualFold(meta.Checksum, checksum) {
+		return stream.SendAndClose(&imv1.UploadAudioResponse{
+			Status: errorStatus(codes.DataLoss, fmt.Sprintf("音频校验和不匹配: 声明 %s，实际 %s", meta.Checksum, checksum)),
+		})
+	}
+
+	meta.Offset = 0
+
+	audioID := fmt.Sprintf("audio_%d_%d", time.Now().UnixNano(), s.seq.Add(1))
+	s.audio.Save(audioID, meta, data)
+
+	return stream.SendAndClose(&imv1.UploadAudioResponse{
+		Status:   okStatus(),
+		AudioId:  audioID,
+		AudioUrl: s.config.AudioURLPrefix + audioID,
+		Checksum: checksum,
+	})
+}
+
+// GetUploadStatus 查询可续传上传已收到的字节数
+func (s *Server) GetUploadStatus(ctx context.Context, req *imv1.UploadStatusRequest) (*imv1.UploadStatusResponse, error) {
+	received, err := s.audio.UploadStatus(req.UploadId, requestUserID(ctx, req.UserId))
+	if err != nil {
+		return &imv1.UploadStatusResponse{Status: statusFromError(err)}, nil
+	}
+
+	return &imv1.UploadStatusResponse{
+		Status:        okStatus(),
+		UploadId:      req.UploadId,
+		ReceivedBytes: received,
+	}, nil
+}
+
+// UploadAttachment 上传附件，第一条消息必须是附件元数据，之后为文件数据块
+func (s *Server) UploadAttachment(stream grpc.ClientStreamingServer[imv1.UploadAttachmentRequest, imv1.UploadAttachmentResponse]) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	meta := first.GetMetadata()
+	if meta == nil {
+		return stream.SendAndClose(&imv1.UploadAttachmentResponse{Status: errorStatus(codes.InvalidArgument, "第一条消息必须是附件元数据")})
+	}
+	if meta.UserId == "" {
+		meta.UserId = incomingValue(stream.Context(), metadataUserID)
+	}
+
+	var data []byte
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		data = append(data, req.GetChunk()...)
+		if s.config.MaxAttachmentSize > 0 && int64(len(data)) > s.config.MaxAttachmentSize {
+			return stream.SendAndClose(&imv1.UploadAttachmentResponse{Status: errorStatus(codes.ResourceExhausted, "附件文件过大")})
+		}
+	}
+
+	if meta.Size > 0 && meta.Size != int64(len(data)) {
+		return stream.SendAndClose(&imv1.UploadAttachmentResponse{
+			Status: errorStatus(codes.DataLoss, fmt.Sprintf("附件大小不匹配: 声明 %d 字节，实际 %d 字节", meta.Size, len(data))),
+		})
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if meta.Checksum != "" && !strings.EqualFold(meta.Checksum, checksum) {
+		return stream.SendAndClose(&imv1.UploadAttachmentResponse{
+			Status: errorStatus(codes.DataLoss, fmt.Sprintf("附件校验和不匹配: 声明 %s，实际 %s", meta.Checksum, checksum)),
+		})
+	}
+
+	attachmentID := fmt.Sprintf("attachment_%d_%d", time.Now().UnixNano(), s.seq.Add(1))
+	s.attachments.Save(attachmentID, meta, data)
+
+	resp := &imv1.UploadAttachmentResponse{
+		Status:       okStatus(),
+		AttachmentId: attachmentID,
+		Url:          s.config.AttachmentURLPrefix + attachmentID,
+		Checksum:     checksum,
+	}
+	if len(meta.Thumbnail) > 0 {
+		resp.ThumbnailUrl = resp.Url + "/thumbnail"
+	}
+	return stream.SendAndClose(resp)
+}
+
+// StreamVoice 实时语音流
+//
+// 第一帧用于加入语音会话，必须带有 room_id，用户必须已在该房间中，加入成功后回复确认帧；
+// 之后收到的帧补全用户和房间信息后转发给房间内的其他语音流，sequence 为0且不是结束帧的帧不转发。
+func (s *Server) StreamVoice(stream grpc.BidiStreamingServer[imv1.VoiceFrame, imv1.VoiceFrame]) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	userID := requestUserID(ctx, first.UserId)
+	if userID == "" || first.RoomId == "" {
+		return status.Error(codes.InvalidArgument, "用户ID和房间ID不能为空")
+	}
+	if !s.rooms.IsMember(first.RoomId, userID) {
+		return status.Error(codes.FailedPrecondition, ErrNotMember.Error())
+	}
+
+	// 回复确认帧后再加入语音会话，保证确认帧是该流收到的第一帧
+	err = stream.Send(&imv1.VoiceFrame{
+		SessionId: first.SessionId,
+		UserId:    userID,
+		RoomId:    first.RoomId,
+		Format:    first.Format,
+	})
+	if err != nil {
+		return err
+	}
+
+	sess := s.voice.add(first.RoomId, userID)
+	defer s.voice.remove(sess)
+
+	// 转发给该流的语音帧由单独的goroutine写入流
+	sendErr := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case frame := <-sess.send:
+				if err := stream.Send(frame); err != nil {
+					sendErr <- err
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		frame, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case err := <-sendErr:
+			return err
+		default:
+		}
+
+		if frame.Sequence == 0 && !frame.End {
+			continue
+		}
+		frame.UserId = userID
+		frame.RoomId = sess.roomID
+		if frame.SessionId == "" {
+			frame.SessionId = first.SessionId
+		}
+		s.voice.Broadcast(sess, frame)
+	}
+}
+
+// GetAudioTranscript 获取语音转写结果
+func (s *Server) GetAudioTranscript(ctx context.Context, req *imv1.TranscriptRequest) (*imv1.TranscriptResponse, error) {
+	transcription, err := s.audio.Transcription(req.AudioId)
+	if err != nil {
+		return &imv1.TranscriptResponse{Status: statusFromError(err)}, nil
+	}
+
+	return &imv1.TranscriptResponse{
+		Status:        okStatus(),
+		Transcription: transcription,
+	}, nil
+}
+
+// HealthCheck 健康检查
+func (s *Server) HealthCheck(ctx context.Context, req *imv1.HealthCheckRequest) (*imv1.HealthCheckResponse, error) {
+	if !s.serving.Load() {
+		return &imv1.HealthCheckResponse{
+			Status:  imv1.HealthStatus_HEALTH_STATUS_NOT_SERVING,
+			Message: "服务不可用",
+		}, nil
+	}
+
+	return &imv1.HealthCheckResponse{
+		Status:  imv1.HealthStatus_HEALTH_STATUS_SERVING,
+		Message: "OK",
+	}, nil
+}
+
+// joinRoom 加入房间并广播 user_joined 系统消息
+func (s *Server) joinRoom(roomID, userID string, metadata map[string]string) (*imv1.RoomInfo, []string, error) {
+	alreadyJoined := s.rooms.IsMember(roomID, userID)
+
+	info, online, err := s.rooms.Join(roomID, userID, metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !alreadyJoined {
+		s.publishSystem(roomID, "user_joined", map[string]string{"user_id": userID})
+	}
+
+	return info, online, nil
+}
+
+// leaveRoom 离开房间并广播 user_left 系统消息
+func (s *Server) leaveRoom(roomID, userID string) error {
+	if err := s.rooms.Leave(roomID, userID); err != nil {
+		return err
+	}
+
+	s.publishSystem(roomID, "user_left", map[string]string{"user_id": userID})
+	return nil
+}
+
+// publish 补全消息ID和时间戳后扇出给房间成员，发送者必须是房间成员
+func (s *Server) publish(msg *imv1.MessageResponse, exclude uint64) (*imv1.MessageResponse, error) {
+	if !s.rooms.IsMember(msg.RoomId, msg.FromUserId) {
+		return nil, ErrNotMember
+	}
+
+	if msg.MessageId == "" {
+		msg.MessageId = s.nextMessageID()
+	}
+	if msg.Timestamp == nil {
+		msg.Timestamp = timestamppb.New(time.Now())
+	}
+
+	if _, err := s.rooms.Publish(msg, exclude); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// publishSystem 广播系统消息，房间已不存在时忽略
+func (s *Server) publishSystem(roomID, eventType string, eventData map[string]string) {
+	content, err := proto.Marshal(&imv1.SystemContent{
+		EventType: eventType,
+		EventData: eventData,
+	})
+	if err != nil {
+		return
+	}
+
+	s.rooms.Publish(&imv1.MessageResponse{
+		MessageId:  s.nextMessageID(),
+		FromUserId: "system",
+		RoomId:     roomID,
+		Type:       imv1.MessageType_MESSAGE_TYPE_SYSTEM,
+		Content:    content,
+		Timestamp:  timestamppb.New(time.Now()),
+		Metadata: map[string]string{
+			metadataContentEncoding: "protobuf",
+		},
+	}, 0)
+}
+
+// ackMessage 构造发给发送者的ACK消息，published 为发布成功后的消息
+func (s *Server) ackMessage(msg *imv1.MessageRequest, published *imv1.MessageResponse, err error) *imv1.MessageResponse {
+	ack := &imv1.AckContent{
+		OriginalMessageId: msg.MessageId,
+		Success:           err == nil,
+		Sequence:          published.GetSequence(),
+	}
+	if err != nil {
+		ack.ErrorMessage = err.Error()
+	}
+
+	content, _ := proto.Marshal(ack)
+	return &imv1.MessageResponse{
+		MessageId:  s.nextMessageID(),
+		FromUserId: "system",
+		RoomId:     msg.RoomId,
+		Type:       imv1.MessageType_MESSAGE_TYPE_ACK,
+		Content:    content,
+		Timestamp:  timestamppb.New(time.Now()),
+	}
+}
+
+// nextMessageID 生成服务端消息ID
+func (s *Server) nextMessageID() string {
+	return fmt.Sprintf("srv_%d_%d", time.Now().UnixNano(), s.seq.Add(1))
+}
+
+// okStatus 成功状态
+func okStatus() *imv1.ResponseStatus {
+	return &imv1.ResponseStatus{
+		Code:    int32(codes.OK),
+		Message: "OK",
+	}
+}
+
+// errorStatus 错误状态，状态码使用gRPC状态码
+func errorStatus(code codes.Code, message string) *imv1.ResponseStatus {
+	return &imv1.ResponseStatus{
+		Code:    int32(code),
+		Message: message,
+	}
+}
+
+// statusFromError 将房间和音频错误转换为响应状态
+func statusFromError(err error) *imv1.ResponseStatus {
+	switch {
+	case errors.Is(err, ErrRoomNotFound), errors.Is(err, ErrAudioNotFound), errors.Is(err, ErrUploadNotFound),
+		errors.Is(err, ErrAttachmentNotFound):
+		return errorStatus(codes.NotFound, err.Error())
+	case errors.Is(err, ErrInvalidCursor):
+		return errorStatus(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrUploadOffset):
+		return errorStatus(codes.OutOfRange, err.Error())
+	case errors.Is(err, ErrNotMember):
+		return errorStatus(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrRoomFull):
+		return errorStatus(codes.ResourceExhausted, err.Error())
+	default:
+		return errorStatus(codes.Internal, err.Error())
+	}
+}
+
+// incomingValue 读取请求metadata中的值
+func incomingValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// requestUserID 优先使用请求中的用户ID，为空时读取 user-id metadata
+func requestUserID(ctx context.Context, userID string) string {
+	if userID != "" {
+		return userID
+	}
+	return incomingValue(ctx, metadataUserID)
+}
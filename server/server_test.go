@@ -0,0 +1,176 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Dev-Umb/im-grpc-sdk/client"
+	"github.com/Dev-Umb/im-grpc-sdk/imtest"
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+	"github.com/Dev-Umb/im-grpc-sdk/server"
+)
+
+const testTimeout = 5 * time.Second
+
+// connectClient 通过独立的服务发现和 Config.Dialer 连接到测试服务端，收到的文本消息写入返回的channel
+func connectClient(t *testing.T, srv *imtest.Server, userID, roomID string, outbox client.Outbox) (*client.Client, *imtest.Discovery, <-chan *imv1.MessageResponse) {
+	t.Helper()
+
+	disc := imtest.NewDiscovery()
+	disc.SetServices("im-service", srv.ServiceInfo())
+	received := make(chan *imv1.MessageResponse, 16)
+	config := client.DefaultConfig()
+	config.UserID = userID
+	config.DefaultRoomID = roomID
+	config.Discovery = disc
+	config.Dialer = srv.Dialer()
+	config.Backoff = client.NewConstantBackoff(50*time.Millisecond, 0)
+	config.Outbox = outbox
+	config.OnMessage = func(msg *imv1.MessageResponse) {
+		if msg.Type == imv1.MessageType_MESSAGE_TYPE_TEXT {
+			received <- msg
+		}
+	}
+
+	c, err := client.NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := c.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { c.Disconnect() })
+	return c, disc, received
+}
+
+// waitFor 轮询等待条件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(testTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitState 等待客户端进入指定状态
+func waitState(t *testing.T, c *client.Client, state client.State) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if err := c.WaitForState(ctx, state); err != nil {
+		t.Fatalf("等待状态 %s 失败，当前状态 %s: %v", state, c.State(), err)
+	}
+}
+
+// expectStateChanges 依次等待指定的状态变化
+func expectStateChanges(t *testing.T, ch <-chan client.StateChange, want ...client.StateChange) {
+	t.Helper()
+
+	for _, w := range want {
+		select {
+		case got := <-ch:
+			if got != w {
+				t.Fatalf("状态变化 = %s -> %s, want %s -> %s", got.From, got.To, w.From, w.To)
+			}
+		case <-time.After(testTimeout):
+			t.Fatalf("等待状态变化 %s -> %s 超时", w.From, w.To)
+		}
+	}
+}
+
+// nextMessage 等待下一条文本消息
+func nextMessage(t *testing.T, ch <-chan *imv1.MessageResponse) *imv1.MessageResponse {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(testTimeout):
+		t.Fatal("等待消息超时")
+		return nil
+	}
+}
+
+func TestRoomStateAfterClientReconnect(t *testing.T) {
+	srv := imtest.NewServer(nil)
+	defer srv.Close()
+	outbox := client.NewMemoryOutbox()
+	// alice 通过 JoinRoom 加入房间，重连后只能依靠 AutoRejoinRooms 恢复成员关系
+	alice, aliceDisc, _ := connectClient(t, srv, "alice", "", outbox)
+	bob, _, bobMessages := connectClient(t, srv, "bob", "room1", nil)
+	if _, err := alice.JoinRoom("room1", nil); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+
+	if err := alice.SendTextMessage("room1", "online"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	first := nextMessage(t, bobMessages)
+	if srv.ActiveStreams() != 2 {
+		t.Fatalf("ActiveStreams = %d, want 2", srv.ActiveStreams())
+	}
+
+	// 断开所有消息流，bob 先完成重连，alice 重连完成前服务端丢失她的成员关系
+	bobChanges, unsubscribe := bob.SubscribeState()
+	defer unsubscribe()
+	aliceDisc.FailDiscover(errors.New("discovery down"))
+	srv.DropStreams()
+	waitState(t, alice, client.StateReconnecting)
+	expectStateChanges(t, bobChanges,
+		client.StateChange{From: client.StateReady, To: client.StateReconnecting},
+		client.StateChange{From: client.StateReconnecting, To: client.StateReady},
+	)
+	if err := bob.SendTextMessage("room1", "back"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+	// 服务端在读取流上的第一条消息之前登记会话，收到 back 说明 bob 的新消息流已可接收消息
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+	if _, err := srv.Received().WaitFor(ctx, imtest.TextRequest("back")); err != nil {
+		t.Fatalf("服务端没有收到 bob 的消息: %v", err)
+	}
+	if err := srv.Rooms().Leave("room1", "alice"); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+	if err := alice.SendTextMessage("room1", "offline"); err != nil {
+		t.Fatalf("SendTextMessage: %v", err)
+	}
+
+	aliceDisc.FailDiscover(nil)
+	waitState(t, alice, client.StateReady)
+
+	// alice 重新加入房间，发件箱中的消息按房间序号继续发布
+	waitFor(t, "alice 重新加入房间", func() bool { return srv.Rooms().IsMember("room1", "alice") })
+	msg := nextMessage(t, bobMessages)
+	if string(msg.Content) != "offline" || msg.FromUserId != "alice" {
+		t.Fatalf("bob 收到 %s: %q, want alice: offline", msg.FromUserId, msg.Content)
+	}
+	if msg.Sequence <= first.Sequence {
+		t.Fatalf("Sequence = %d, want > %d", msg.Sequence, first.Sequence)
+	}
+	waitFor(t, "发件箱清空", func() bool { return outbox.Len() == 0 })
+
+	history, _, _, err := srv.Rooms().History("room1", server.HistoryQuery{
+		Forward: true,
+		Limit:   100,
+		Types:   []imv1.MessageType{imv1.MessageType_MESSAGE_TYPE_TEXT},
+	})
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	var texts []string
+	for _, msg := range history {
+		texts = append(texts, string(msg.Content))
+	}
+	if want := []string{"online", "back", "offline"}; !slices.Equal(texts, want) {
+		t.Fatalf("History = %v, want %v", texts, want)
+	}
+}
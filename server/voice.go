@@ -0,0 +1,92 @@
+package server
+
+import (
+	"sync"
+
+	imv1 "github.com/Dev-Umb/im-grpc-sdk/proto/im/v1"
+)
+
+// voiceBufferSize 每条语音流的发送缓冲，消费过慢时丢弃语音帧
+const voiceBufferSize = 64
+
+// voiceSession 一条加入了房间语音会话的语音流
+type voiceSession struct {
+	id     uint64
+	userID string
+	roomID string
+	send   chan *imv1.VoiceFrame
+}
+
+// VoiceHub 按房间转发实时语音帧
+type VoiceHub struct {
+	rooms  map[string]map[uint64]*voiceSession // roomID -> sessionID -> session
+	nextID uint64
+	mu     sync.RWMutex
+}
+
+// NewVoiceHub 创建语音转发器
+func NewVoiceHub() *VoiceHub {
+	return &VoiceHub{
+		rooms: make(map[string]map[uint64]*voiceSession),
+	}
+}
+
+// add 把语音流加入房间的语音会话
+func (h *VoiceHub) add(roomID, userID string) *voiceSession {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	s := &voiceSession{
+		id:     h.nextID,
+		userID: userID,
+		roomID: roomID,
+		send:   make(chan *imv1.VoiceFrame, voiceBufferSize),
+	}
+
+	if h.rooms[roomID] == nil {
+		h.rooms[roomID] = make(map[uint64]*voiceSession)
+	}
+	h.rooms[roomID][s.id] = s
+
+	return s
+}
+
+// remove 把语音流移出语音会话
+func (h *VoiceHub) remove(s *voiceSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sessions := h.rooms[s.roomID]
+	delete(sessions, s.id)
+	if len(sessions) == 0 {
+		delete(h.rooms, s.roomID)
+	}
+}
+
+// Broadcast 把语音帧转发给房间内的其他语音流，返回转发的流数量；
+// 语音对延迟敏感，消费过慢的流直接丢帧
+func (h *VoiceHub) Broadcast(from *voiceSession, frame *imv1.VoiceFrame) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	delivered := 0
+	for id, s := range h.rooms[from.roomID] {
+		if id == from.id {
+			continue
+		}
+		select {
+		case s.send <- frame:
+			delivered++
+		default:
+		}
+	}
+	return delivered
+}
+
+// Listeners 返回房间内加入语音会话的流数量
+func (h *VoiceHub) Listeners(roomID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[roomID])
+}